@@ -64,6 +64,17 @@ func (rdb *RDBConnection) GetCache(key string, dest interface{}) error {
 	return json.Unmarshal([]byte(val), dest)
 }
 
+// SetCacheNX stores the value only when the key does not exist yet and
+// reports whether the value was stored.
+func (rdb *RDBConnection) SetCacheNX(key string, value interface{}, duration int) (bool, error) {
+	v, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	return rdb.SetNX(rdbCtx, key, v, time.Duration(duration)*time.Minute).Result()
+}
+
 func (rdb *RDBConnection) DeleteCache(key string) error {
 	_, err := rdb.Del(rdbCtx, key).Result()
 	if err != nil {
@@ -72,6 +83,20 @@ func (rdb *RDBConnection) DeleteCache(key string) error {
 	return nil
 }
 
+// DeleteCacheByPattern deletes every key matching pattern, scanning the
+// keyspace in batches instead of blocking redis with KEYS.
+func (rdb *RDBConnection) DeleteCacheByPattern(pattern string) error {
+	iter := rdb.Scan(rdbCtx, 0, pattern, 100).Iterator()
+	for iter.Next(rdbCtx) {
+		_, err := rdb.Del(rdbCtx, iter.Val()).Result()
+		if err != nil {
+			return err
+		}
+	}
+
+	return iter.Err()
+}
+
 func (rdb *RDBConnection) GetTTLCache(key string) (int, error) {
 	val, err := rdb.TTL(rdbCtx, key).Result()
 	if err != nil {
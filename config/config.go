@@ -40,6 +40,8 @@ type rdbConfig struct {
 	DB       string
 	Password string
 	Expires  string
+
+	IdempotencyExpires int
 }
 
 type envConfig struct {
@@ -75,6 +77,8 @@ type oauthConfig struct {
 type rajaOngkirConfig struct {
 	Url    string
 	ApiKey string
+
+	WaybillStub bool
 }
 
 type smtpConfig struct {
@@ -95,6 +99,9 @@ type cronConfig struct {
 	TrxBatchSizeProcessedToCanceled  int
 	TrxQueueSizeDeliveredToCompleted int
 	TrxBatchSizeDeliveredToCompleted int
+	PaymentReconcileOlderThanMinutes int
+	PaymentReconcileBatchSize        int
+	PaymentReconcileMaxAgeHours      int
 }
 
 type AppConfig struct {
@@ -211,6 +218,8 @@ func initAllConfig() AppConfig {
 			DB:       getENV("RDB_DB", ""),
 			Password: getENV("RDB_PASSWORD", ""),
 			Expires:  getENV("RDB_EXPIRES", ""),
+
+			IdempotencyExpires: getENVinteger("RDB_IDEMPOTENCY_EXPIRES", 1440),
 		},
 
 		ENVConfig: envConfig{
@@ -229,6 +238,8 @@ func initAllConfig() AppConfig {
 		RajaOngkirConfig: rajaOngkirConfig{
 			Url:    getENV("RAJAONGKIR_URL", ""),
 			ApiKey: getENV("RAJAONGKIR_API_KEY", ""),
+
+			WaybillStub: getENVbool("RAJAONGKIR_WAYBILL_STUB", false),
 		},
 
 		SmtpConfig: smtpConfig{
@@ -275,6 +286,9 @@ func initAllConfig() AppConfig {
 			TrxBatchSizeProcessedToCanceled:  getENVinteger("TRX_BATCH_SIZE_PROCESSED_TO_CANCELED", 25),
 			TrxQueueSizeDeliveredToCompleted: getENVinteger("TRX_QUEUE_SIZE_DELIVERED_TO_COMPLETED", 100),
 			TrxBatchSizeDeliveredToCompleted: getENVinteger("TRX_BATCH_SIZE_DELIVERED_TO_COMPLETED", 25),
+			PaymentReconcileOlderThanMinutes: getENVinteger("PAYMENT_RECONCILE_OLDER_THAN_MINUTES", 2),
+			PaymentReconcileBatchSize:        getENVinteger("PAYMENT_RECONCILE_BATCH_SIZE", 50),
+			PaymentReconcileMaxAgeHours:      getENVinteger("PAYMENT_RECONCILE_MAX_AGE_HOURS", 24),
 		},
 	}
 }
@@ -0,0 +1,16 @@
+-- buyers waiting for an out of stock variant, triggered inside the restock transaction and notified once by cron.
+-- variant_name keeps the subscription attached when a product update recreates its variant items.
+create table back_in_stock_subscriptions (
+	id bigserial primary key,
+	user_id bigint not null references users(id),
+	product_id bigint not null references products(id),
+	variant_item_id bigint not null references variant_items(id),
+	variant_name varchar not null default '',
+	triggered_at timestamptz,
+	notified_at timestamptz,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now()
+);
+
+create unique index back_in_stock_subscriptions_pending_idx on back_in_stock_subscriptions (user_id, product_id, variant_name) where notified_at is null;
+create index back_in_stock_subscriptions_triggered_idx on back_in_stock_subscriptions (triggered_at) where triggered_at is not null and notified_at is null;
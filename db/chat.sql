@@ -0,0 +1,47 @@
+-- buyer to merchant chat, one conversation per buyer, merchant and product or transaction
+create table chat_conversations (
+	id bigserial primary key,
+	merchant_domain varchar not null references merchants(domain),
+	buyer_id bigint not null references users(id),
+	product_id bigint references products(id),
+	transaction_id bigint references transactions(id),
+	last_message_at timestamptz,
+	buyer_waiting_since timestamptz,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	deleted_at timestamptz
+);
+
+create unique index chat_conversations_subject_idx on chat_conversations (buyer_id, merchant_domain, coalesce(product_id, 0), coalesce(transaction_id, 0)) where deleted_at is null;
+
+create table chat_participants (
+	id bigserial primary key,
+	conversation_id bigint not null references chat_conversations(id),
+	user_id bigint not null references users(id),
+	role varchar not null,
+	last_read_message_id bigint not null default 0,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	unique (conversation_id, user_id)
+);
+
+create index chat_participants_user_id_idx on chat_participants (user_id);
+
+-- response_seconds is set on the first merchant reply after the buyer started waiting
+create table chat_messages (
+	id bigserial primary key,
+	conversation_id bigint not null references chat_conversations(id),
+	sender_id bigint not null references users(id),
+	sender_role varchar not null,
+	message varchar,
+	image_url varchar,
+	response_seconds decimal,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	deleted_at timestamptz
+);
+
+create index chat_messages_conversation_id_idx on chat_messages (conversation_id, id desc);
+
+-- chat response time in hours, shown on the merchant responsiveness dashboard
+alter table merchant_daily_analytics_hists add column crt decimal;
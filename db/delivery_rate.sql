@@ -0,0 +1,35 @@
+-- fallback shipping rates per kg, used when the rajaongkir cost api is unreachable
+create table delivery_rates (
+	id bigserial primary key,
+	courier_code varchar not null,
+	service_code varchar not null,
+	description varchar,
+	scope varchar not null,
+	cost_per_kg decimal not null,
+	etd varchar,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	deleted_at timestamptz,
+	unique (courier_code, service_code, scope)
+);
+
+INSERT INTO delivery_rates (
+courier_code,
+service_code,
+description,
+scope,
+cost_per_kg,
+etd
+)VALUES
+('jne', 'REG', 'Layanan Reguler', 'SAME_CITY', 9000, '1-2'),
+('jne', 'REG', 'Layanan Reguler', 'SAME_PROVINCE', 14000, '2-3'),
+('jne', 'REG', 'Layanan Reguler', 'INTER_PROVINCE', 24000, '3-5'),
+('jne', 'YES', 'Yakin Esok Sampai', 'SAME_CITY', 18000, '1-1'),
+('jne', 'YES', 'Yakin Esok Sampai', 'SAME_PROVINCE', 26000, '1-1'),
+('jne', 'YES', 'Yakin Esok Sampai', 'INTER_PROVINCE', 42000, '1-1'),
+('pos', 'Pos Reguler', 'Pos Reguler', 'SAME_CITY', 8000, '2 HARI'),
+('pos', 'Pos Reguler', 'Pos Reguler', 'SAME_PROVINCE', 13000, '3 HARI'),
+('pos', 'Pos Reguler', 'Pos Reguler', 'INTER_PROVINCE', 22000, '4 HARI'),
+('tiki', 'REG', 'Regular Service', 'SAME_CITY', 9000, '2'),
+('tiki', 'REG', 'Regular Service', 'SAME_PROVINCE', 14000, '3'),
+('tiki', 'REG', 'Regular Service', 'INTER_PROVINCE', 23000, '4');
@@ -0,0 +1,72 @@
+-- ledger accounts, one per wallet / merchant holding account / external party
+create table ledger_accounts (
+	id bigserial primary key,
+	code varchar not null unique,
+	owner_type varchar not null,
+	owner_id bigint,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	deleted_at timestamptz
+);
+
+create table ledger_journal_entries (
+	id bigserial primary key,
+	type varchar not null,
+	reference varchar not null,
+	notes varchar,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	deleted_at timestamptz
+);
+
+create table ledger_postings (
+	id bigserial primary key,
+	ledger_journal_entry_id bigint not null references ledger_journal_entries(id),
+	ledger_account_id bigint not null references ledger_accounts(id),
+	direction varchar(2) not null check (direction in ('DR', 'CR')),
+	amount numeric not null check (amount > 0),
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	deleted_at timestamptz
+);
+
+create index ledger_accounts_owner_idx on ledger_accounts (owner_type, owner_id);
+create index ledger_journal_entries_reference_idx on ledger_journal_entries (reference);
+create index ledger_postings_account_idx on ledger_postings (ledger_account_id);
+
+
+-- opening balances, existing balances are moved in from the opening balance equity account
+insert into ledger_accounts (code, owner_type) values ('equity:opening_balance', 'equity');
+
+insert into ledger_accounts (code, owner_type, owner_id)
+select concat('wallet:', w.id), 'wallet', w.id
+from wallets w
+where w.deleted_at is null;
+
+insert into ledger_accounts (code, owner_type, owner_id)
+select concat('merchant_holding_account:', mha.id), 'merchant_holding_account', mha.id
+from merchant_holding_accounts mha
+where mha.deleted_at is null;
+
+with cte_opening_entry as (
+	insert into ledger_journal_entries (type, reference, notes)
+	values ('OPENING_BALANCE', 'OPENING_BALANCE', 'Opening balance when ledger is introduced')
+	returning id
+), cte_opening_balances as (
+	select la.id as ledger_account_id, w.balance
+	from ledger_accounts la
+	join wallets w on la.owner_type = 'wallet' and la.owner_id = w.id
+	union all
+	select la.id as ledger_account_id, mha.balance
+	from ledger_accounts la
+	join merchant_holding_accounts mha on la.owner_type = 'merchant_holding_account' and la.owner_id = mha.id
+)
+insert into ledger_postings (ledger_journal_entry_id, ledger_account_id, direction, amount)
+select (select id from cte_opening_entry), ob.ledger_account_id, case when ob.balance > 0 then 'CR' else 'DR' end, abs(ob.balance)
+from cte_opening_balances ob
+where ob.balance <> 0
+union all
+select (select id from cte_opening_entry), (select id from ledger_accounts where code = 'equity:opening_balance'),
+	case when sum(ob.balance) > 0 then 'DR' else 'CR' end, abs(sum(ob.balance))
+from cte_opening_balances ob
+having sum(ob.balance) <> 0;
@@ -0,0 +1,2 @@
+-- SHIPPING vouchers subsidise the delivery cost instead of the subtotal
+alter table marketplace_vouchers add column type varchar not null default 'DISCOUNT';
@@ -0,0 +1,14 @@
+-- daily latency percentiles in hours per merchant and metric (ACCEPT, SHIP, REFUND) over the last 30 days
+create table merchant_daily_responsiveness_hists (
+	id bigserial primary key,
+	date_partition date not null,
+	domain varchar not null,
+	metric varchar not null,
+	sample_count int not null,
+	p50 decimal,
+	p90 decimal,
+	p95 decimal,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	unique (date_partition, domain, metric)
+);
@@ -0,0 +1,14 @@
+-- merchant defined adjustments applied on top of the courier cost at checkout
+create table merchant_shipping_rules (
+	id bigserial primary key,
+	merchant_delivery_option_id bigint not null references merchant_delivery_options(id),
+	type varchar not null,
+	min_subtotal decimal not null default 0,
+	province_id bigint references provinces(id),
+	amount decimal not null default 0,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	deleted_at timestamptz
+);
+
+create unique index merchant_shipping_rules_unique_idx on merchant_shipping_rules (merchant_delivery_option_id, type, coalesce(province_id, 0)) where deleted_at is null;
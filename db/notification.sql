@@ -0,0 +1,16 @@
+-- in-app notification feed
+create table notifications (
+	id bigserial primary key,
+	user_id bigint not null references users(id),
+	type varchar not null,
+	title varchar not null,
+	message varchar,
+	link varchar,
+	read_at timestamptz,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	deleted_at timestamptz
+);
+
+create index notifications_user_id_idx on notifications (user_id, created_at desc);
+create index notifications_unread_idx on notifications (user_id) where read_at is null and deleted_at is null;
@@ -0,0 +1,4 @@
+-- when the reconcile cron last asked the provider about a pending payment, used to rotate its batch
+alter table payment_records add column last_checked_at timestamptz;
+
+create index payment_records_pending_idx on payment_records (payment_method_id, last_checked_at, created_at) where paid_at is null and canceled_at is null;
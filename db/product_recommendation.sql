@@ -0,0 +1,18 @@
+-- item-to-item similarity refreshed nightly by the recommendation cron
+create table product_similarities (
+	product_id bigint not null references products(id),
+	related_product_id bigint not null references products(id),
+	score numeric not null,
+	updated_at timestamptz default now(),
+	primary key (product_id, related_product_id)
+);
+
+-- "frequently bought together" and "similar products", refreshed nightly
+create table product_related_products (
+	product_id bigint not null references products(id),
+	related_product_id bigint not null references products(id),
+	relation_type varchar not null check (relation_type in ('BOUGHT_TOGETHER', 'SIMILAR')),
+	score numeric not null,
+	updated_at timestamptz default now(),
+	primary key (product_id, relation_type, related_product_id)
+);
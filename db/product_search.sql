@@ -0,0 +1,10 @@
+-- trigram matching for typo tolerant product search
+create extension if not exists pg_trgm;
+
+create index if not exists products_title_trgm_idx on products using gin (title gin_trgm_ops);
+create index if not exists products_documentq_weights_idx on products using gin (documentq_weights);
+
+-- prefix lookups for search suggestions
+create index if not exists products_title_prefix_idx on products (lower(title) text_pattern_ops);
+create index if not exists categories_name_prefix_idx on categories (lower(name) text_pattern_ops);
+create index if not exists merchants_name_prefix_idx on merchants (lower(name) text_pattern_ops);
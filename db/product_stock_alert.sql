@@ -0,0 +1,9 @@
+-- per-product low-stock threshold and the last alerted state, products without a row use the default threshold
+create table product_stock_alerts (
+	product_id bigint primary key references products(id),
+	threshold int not null check (threshold >= 0),
+	is_low_stock boolean not null default false,
+	alerted_at timestamptz,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now()
+);
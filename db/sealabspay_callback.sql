@@ -0,0 +1,20 @@
+-- inbox of sealabspay payment callbacks, processed and retried by the callback worker
+create table sealabspay_callbacks (
+	id bigserial primary key,
+	txn_id varchar not null unique,
+	amount varchar not null,
+	merchant_code varchar,
+	message varchar,
+	signature varchar,
+	status varchar not null,
+	process_status varchar not null default 'PENDING',
+	attempts int not null default 0,
+	last_error varchar,
+	next_attempt_at timestamptz,
+	processed_at timestamptz,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	deleted_at timestamptz
+);
+
+create index sealabspay_callbacks_process_status_idx on sealabspay_callbacks (process_status, next_attempt_at);
@@ -0,0 +1,16 @@
+-- stock held for an order code between checkout and payment, released by cron once expired
+create table stock_reservations (
+	id bigserial primary key,
+	order_code varchar not null,
+	product_id bigint not null references products(id),
+	variant_item_id bigint not null references variant_items(id),
+	quantity int not null,
+	expires_at timestamptz not null,
+	consumed_at timestamptz,
+	released_at timestamptz,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now()
+);
+
+create index stock_reservations_order_code_idx on stock_reservations (order_code);
+create index stock_reservations_active_idx on stock_reservations (variant_item_id, expires_at) where consumed_at is null and released_at is null;
@@ -0,0 +1,11 @@
+-- courier manifest entries fetched from the waybill api, one row per checkpoint
+create table transaction_tracking_events (
+	id bigserial primary key,
+	transaction_id bigint not null references transactions(id),
+	code varchar not null,
+	description varchar not null,
+	location varchar,
+	occurred_at timestamptz not null,
+	created_at timestamptz default now(),
+	unique (transaction_id, occurred_at, code, description)
+);
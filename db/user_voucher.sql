@@ -0,0 +1,14 @@
+-- marketplace and merchant vouchers a user saved to their wallet
+create table user_vouchers (
+	id bigserial primary key,
+	user_id bigint not null references users(id),
+	marketplace_voucher_id bigint references marketplace_vouchers(id),
+	merchant_voucher_id bigint references merchant_vouchers(id),
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	deleted_at timestamptz,
+	check ((marketplace_voucher_id is null) <> (merchant_voucher_id is null))
+);
+
+create unique index user_vouchers_marketplace_voucher_unique on user_vouchers (user_id, marketplace_voucher_id) where deleted_at is null and marketplace_voucher_id is not null;
+create unique index user_vouchers_merchant_voucher_unique on user_vouchers (user_id, merchant_voucher_id) where deleted_at is null and merchant_voucher_id is not null;
@@ -0,0 +1,20 @@
+-- single-use codes generated in batches for a marketplace or merchant voucher
+create table voucher_codes (
+	id bigserial primary key,
+	marketplace_voucher_id bigint references marketplace_vouchers(id),
+	merchant_voucher_id bigint references merchant_vouchers(id),
+	code varchar not null,
+	redeemed_at timestamptz,
+	redeemed_by bigint references users(id),
+	payment_id varchar,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	deleted_at timestamptz,
+	check ((marketplace_voucher_id is null) <> (merchant_voucher_id is null))
+);
+
+create unique index voucher_codes_code_unique on voucher_codes (code) where deleted_at is null;
+create index voucher_codes_marketplace_voucher_idx on voucher_codes (marketplace_voucher_id) where deleted_at is null;
+create index voucher_codes_merchant_voucher_idx on voucher_codes (merchant_voucher_id) where deleted_at is null;
+
+alter table voucher_redemptions add column voucher_code_id bigint references voucher_codes(id);
@@ -0,0 +1,33 @@
+-- one row per voucher use, released when the transaction is canceled
+create table voucher_redemptions (
+	id bigserial primary key,
+	marketplace_voucher_id bigint references marketplace_vouchers(id),
+	merchant_voucher_id bigint references merchant_vouchers(id),
+	user_id bigint not null references users(id),
+	transaction_id bigint references transactions(id),
+	payment_id varchar not null,
+	released_at timestamptz,
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	deleted_at timestamptz,
+	check ((marketplace_voucher_id is null) <> (merchant_voucher_id is null))
+);
+
+create unique index voucher_redemptions_marketplace_unique_idx on voucher_redemptions (marketplace_voucher_id, payment_id) where marketplace_voucher_id is not null and released_at is null and deleted_at is null;
+create unique index voucher_redemptions_merchant_unique_idx on voucher_redemptions (merchant_voucher_id, transaction_id) where merchant_voucher_id is not null and released_at is null and deleted_at is null;
+create index voucher_redemptions_payment_idx on voucher_redemptions (payment_id) where released_at is null and deleted_at is null;
+
+-- backfill vouchers held by transactions made before redemptions were recorded
+insert into voucher_redemptions (marketplace_voucher_id, user_id, payment_id)
+select distinct t.marketplace_voucher_id, t.user_id, tpr.payment_id
+from transactions t
+join transaction_payment_records tpr on tpr.transaction_id = t.id
+join transaction_statuses ts on ts.transaction_id = t.id and ts.deleted_at is null
+where t.marketplace_voucher_id is not null and ts.on_canceled_at is null and t.deleted_at is null;
+
+insert into voucher_redemptions (merchant_voucher_id, user_id, transaction_id, payment_id)
+select t.merchant_voucher_id, t.user_id, t.id, tpr.payment_id
+from transactions t
+join transaction_payment_records tpr on tpr.transaction_id = t.id
+join transaction_statuses ts on ts.transaction_id = t.id and ts.deleted_at is null
+where t.merchant_voucher_id is not null and ts.on_canceled_at is null and t.deleted_at is null;
@@ -0,0 +1,16 @@
+-- eligibility conditions attached to a marketplace or merchant voucher
+create table voucher_rules (
+	id bigserial primary key,
+	marketplace_voucher_id bigint references marketplace_vouchers(id),
+	merchant_voucher_id bigint references merchant_vouchers(id),
+	type varchar not null,
+	value int not null default 0,
+	targets varchar not null default '',
+	created_at timestamptz default now(),
+	updated_at timestamptz default now(),
+	deleted_at timestamptz,
+	check ((marketplace_voucher_id is null) <> (merchant_voucher_id is null))
+);
+
+create index voucher_rules_marketplace_voucher_idx on voucher_rules (marketplace_voucher_id) where deleted_at is null;
+create index voucher_rules_merchant_voucher_idx on voucher_rules (merchant_voucher_id) where deleted_at is null;
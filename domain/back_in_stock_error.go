@@ -0,0 +1,10 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrBackInStockVariantIdNotValid = httperror.BadRequestError("variant id is not valid", "VARIANT_ID_NOT_VALID")
+var ErrBackInStockVariantNotFound = httperror.NotFoundError("product variant not found")
+var ErrBackInStockVariantInStock = httperror.BadRequestError("product variant is still in stock", "VARIANT_IN_STOCK")
+var ErrCreateBackInStockSubscription = httperror.InternalServerError("failed to subscribe to product variant")
+var ErrTriggerBackInStockSubscription = httperror.InternalServerError("failed to trigger back in stock subscription")
+var ErrNotifyBackInStockSubscription = httperror.InternalServerError("failed to notify back in stock subscription")
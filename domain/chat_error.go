@@ -0,0 +1,15 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrCreateChatConversation = httperror.InternalServerError("failed to create chat conversation")
+var ErrGetChatConversation = httperror.InternalServerError("failed to get chat conversation")
+var ErrChatConversationNotFound = httperror.NotFoundError("chat conversation not found")
+var ErrChatConversationIdNotValid = httperror.BadRequestError("chat conversation id is not valid", "CHAT_CONVERSATION_ID_NOT_VALID")
+var ErrChatWithOwnMerchant = httperror.BadRequestError("cannot chat with your own merchant", "CHAT_WITH_OWN_MERCHANT")
+var ErrChatProductNotInMerchant = httperror.BadRequestError("product does not belong to the merchant", "CHAT_PRODUCT_NOT_IN_MERCHANT")
+var ErrChatTransactionNotInMerchant = httperror.BadRequestError("transaction does not belong to the merchant", "CHAT_TRANSACTION_NOT_IN_MERCHANT")
+var ErrChatMessageEmpty = httperror.BadRequestError("message or image is required", "CHAT_MESSAGE_EMPTY")
+var ErrCreateChatMessage = httperror.InternalServerError("failed to send chat message")
+var ErrGetChatMessage = httperror.InternalServerError("failed to get chat messages")
+var ErrUpdateChatParticipant = httperror.InternalServerError("failed to mark chat as read")
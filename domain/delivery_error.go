@@ -5,3 +5,10 @@ import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperro
 var ErrGetAllDeliveryInternalError = httperror.InternalServerError("cannot get all delivery option")
 var ErrGetDeliveryFeeInternalError = httperror.InternalServerError("cannot get delivery fee")
 var ErrUpdateDeliveryOptionInternalError = httperror.InternalServerError("cannot update delivery fee")
+var ErrGetMerchantShippingRuleInternalError = httperror.InternalServerError("cannot get merchant shipping rules")
+var ErrUpdateMerchantShippingRuleInternalError = httperror.InternalServerError("cannot update merchant shipping rules")
+var ErrMerchantShippingRuleNotFound = httperror.NotFoundError("merchant shipping rule not found")
+var ErrMerchantShippingRuleIdNotValid = httperror.BadRequestError("shipping rule id is not valid", "SHIPPING_RULE_ID_NOT_VALID")
+var ErrMerchantShippingRuleDuplicate = httperror.BadRequestError("shipping rule already exists for this courier", "SHIPPING_RULE_DUPLICATE")
+var ErrMerchantShippingRuleCourierNotEnabled = httperror.BadRequestError("courier is not enabled for this merchant", "SHIPPING_RULE_COURIER_NOT_ENABLED")
+var ErrMerchantShippingRuleProvinceRequired = httperror.BadRequestError("flat rate rule requires a province", "SHIPPING_RULE_PROVINCE_REQUIRED")
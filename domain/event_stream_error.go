@@ -0,0 +1,6 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrPublishStreamEvent = httperror.InternalServerError("failed to publish stream event")
+var ErrSubscribeStreamEvent = httperror.InternalServerError("failed to subscribe to event stream")
@@ -0,0 +1,9 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrIdempotencyKeyTooLong = httperror.BadRequestError("idempotency key is too long", "IDEMPOTENCY_KEY_TOO_LONG")
+var ErrIdempotencyKeyReused = httperror.ConflictError("idempotency key has already been used for a different request", "IDEMPOTENCY_KEY_REUSED")
+var ErrIdempotencyKeyInProgress = httperror.ConflictError("request with the same idempotency key is still being processed", "IDEMPOTENCY_KEY_IN_PROGRESS")
+var ErrIdempotencyReadBody = httperror.BadRequestError("cannot read request body", "IDEMPOTENCY_READ_BODY")
+var ErrIdempotencyStore = httperror.InternalServerError("failed to process idempotency key")
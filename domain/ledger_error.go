@@ -0,0 +1,8 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrLedgerUnbalancedEntry = httperror.InternalServerError("cannot post ledger entry, debit and credit are not balanced")
+var ErrLedgerGetAccount = httperror.InternalServerError("cannot get ledger account")
+var ErrLedgerPostEntry = httperror.InternalServerError("cannot post ledger entry")
+var ErrLedgerGetAccountBalances = httperror.InternalServerError("cannot get ledger account balances")
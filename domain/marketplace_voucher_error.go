@@ -7,9 +7,13 @@ var ErrGetMarketplaceVoucher = httperror.InternalServerError("failed to get mark
 var ErrInvalidVoucherID = httperror.BadRequestError("invalid voucher ID", "INVALID_VOUCHER_ID")
 var ErrMarketplaceVoucherNotFound = httperror.BadRequestError("marketplace voucher not found", "MARKETPLACE_VOUCHER_NOT_FOUND")
 
-var ErrDecreaseMarketplaceVoucherQuota = httperror.InternalServerError("failed to decrease marketplace voucher quota")
-var ErrIncreaseMarketplaceVoucherQuota = httperror.InternalServerError("failed to increase marketplace voucher quota")
 var ErrCreateMarketplaceVoucher = httperror.InternalServerError("failed to create marketplace voucher")
 var ErrDeleteMarketplaceVoucher = httperror.InternalServerError("failed to delete marketplace voucher")
 var ErrUpdateMarketplaceVoucher = httperror.InternalServerError("failed to update marketplace voucher")
 var ErrMarketplaceVoucherCodeAlreadyExist = httperror.BadRequestError("voucher code already exist", "VOUCHER_CODE_ALREADY_EXIST")
+
+var ErrGetVoucherRules = httperror.InternalServerError("failed to get voucher rules")
+var ErrUpdateVoucherRules = httperror.InternalServerError("failed to update voucher rules")
+var ErrCheckVoucherRules = httperror.InternalServerError("failed to check voucher rules")
+var ErrInvalidVoucherRule = httperror.BadRequestError("voucher rule is not valid", "INVALID_VOUCHER_RULE")
+var ErrDuplicateVoucherRule = httperror.BadRequestError("voucher rule type can only be set once", "DUPLICATE_VOUCHER_RULE")
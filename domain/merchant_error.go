@@ -17,7 +17,6 @@ var ErrMerchantAlreadyRegistered = httperror.BadRequestError("this account alrea
 var ErrGetMerchantVoucherList = httperror.InternalServerError("failed to get merchant voucher list")
 var ErrGetMerchantVoucher = httperror.InternalServerError("failed to get merchant voucher record")
 var ErrMerchantVoucherNotFound = httperror.BadRequestError("cannot retrieve merchant voucher information, merchant voucher not found", "MERCHANT_VOUCHER_NOT_FOUND")
-var ErrDecreaseMerchantVoucherQuota = httperror.InternalServerError("failed to decrease merchant voucher quota")
 var ErrMerchantFundActivitiesReqParam = httperror.BadRequestError("invalid request parameter for merchant fund activities", "INVALID_REQUEST_PARAM")
 var ErrCreateMerchantVoucher = httperror.InternalServerError("failed to create merchant voucher")
 var ErrInvalidVoucherDateRange = httperror.BadRequestError("voucher start date must before than voucher end date", "INVALID_VOUCHER_DATE_RANGE")
@@ -0,0 +1,9 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrCreateNotification = httperror.InternalServerError("failed to create notification")
+var ErrGetNotification = httperror.InternalServerError("failed to get notification")
+var ErrUpdateNotification = httperror.InternalServerError("failed to update notification")
+var ErrNotificationNotFound = httperror.NotFoundError("notification not found")
+var ErrNotificationIdNotValid = httperror.BadRequestError("notification id is not valid", "NOTIFICATION_ID_NOT_VALID")
@@ -0,0 +1,8 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrPaymentProviderNotFound = httperror.BadRequestError("payment provider is not available for this payment method", "PAYMENT_PROVIDER_NOT_FOUND")
+var ErrPaymentProviderOperationNotSupported = httperror.BadRequestError("operation is not supported by payment provider", "PAYMENT_PROVIDER_OPERATION_NOT_SUPPORTED")
+var ErrPaymentProviderInvalidCallbackStatus = httperror.BadRequestError("payment callback status is not valid", "PAYMENT_PROVIDER_INVALID_CALLBACK_STATUS")
+var ErrPaymentProviderQueryStatus = httperror.InternalServerError("failed to query payment status")
@@ -9,3 +9,4 @@ var ErrPaymentIdNotFound = httperror.BadRequestError("payment id not found", "PA
 var ErrGetPaymentRecord = httperror.InternalServerError("failed to get payment record")
 var ErrPaymentIdExpired = httperror.BadRequestError("payment is expired", "PAYMENT_ID_EXPIRED")
 var ErrPaymentAmountNotMatch = httperror.BadRequestError("payment amount not match", "PAYMENT_AMOUNT_NOT_MATCH")
+var ErrPaymentAlreadySettled = httperror.BadRequestError("payment is already settled", "PAYMENT_ALREADY_SETTLED")
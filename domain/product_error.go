@@ -3,6 +3,9 @@ package domain
 import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
 
 var ErrGetProducts = httperror.InternalServerError("failed to get products record")
+var ErrGetProductFacets = httperror.InternalServerError("failed to get product search facets")
+var ErrGetProductHighlights = httperror.InternalServerError("failed to get product search highlights")
+var ErrGetProductSuggestions = httperror.InternalServerError("failed to get product suggestions")
 var ErrGetProduct = httperror.InternalServerError("failed to get product record")
 var ErrGetProductNotFound = httperror.BadRequestError("failed to retrieve product details, product not found", "PRODUCT_NOT_FOUND")
 var ErrInvalidPriceRange = httperror.BadRequestError("the minimum price must be lower than the maximum price", "INVALID_PRICE_RANGE")
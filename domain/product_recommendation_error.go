@@ -0,0 +1,8 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrRefreshProductSimilarities = httperror.InternalServerError("failed to refresh product similarities")
+var ErrGetProductRecommendations = httperror.InternalServerError("failed to get product recommendations")
+var ErrRefreshRelatedProducts = httperror.InternalServerError("failed to refresh related products")
+var ErrGetRelatedProducts = httperror.InternalServerError("failed to get related products")
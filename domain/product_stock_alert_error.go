@@ -0,0 +1,7 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrUpdateLowStockThreshold = httperror.InternalServerError("failed to update low stock threshold")
+var ErrGetLowStockProducts = httperror.InternalServerError("failed to get low stock products")
+var ErrUpdateLowStockState = httperror.InternalServerError("failed to update low stock state")
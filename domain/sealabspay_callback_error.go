@@ -0,0 +1,11 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrCreateSealabspayCallback = httperror.InternalServerError("failed to store sealabspay callback")
+var ErrUpdateSealabspayCallback = httperror.InternalServerError("failed to update sealabspay callback")
+var ErrGetSealabspayCallback = httperror.InternalServerError("failed to get sealabspay callback")
+var ErrSealabspayCallbackNotFound = httperror.BadRequestError("sealabspay callback not found", "SEALABSPAY_CALLBACK_NOT_FOUND")
+var ErrSealabspayCallbackAlreadyProcessed = httperror.BadRequestError("sealabspay callback is already processed", "SEALABSPAY_CALLBACK_ALREADY_PROCESSED")
+var ErrSealabspayCallbackInProgress = httperror.BadRequestError("sealabspay callback is being processed or waiting for its next attempt", "SEALABSPAY_CALLBACK_IN_PROGRESS")
+var ErrInvalidSealabspayCallbackId = httperror.BadRequestError("invalid sealabspay callback id", "INVALID_SEALABSPAY_CALLBACK_ID")
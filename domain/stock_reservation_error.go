@@ -0,0 +1,7 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrStockReservationInsufficient = httperror.BadRequestError("some product stock is reserved by other orders", "PRODUCT_STOCK_RESERVED")
+var ErrGetStockReservation = httperror.InternalServerError("failed to get stock reservation")
+var ErrReleaseStockReservation = httperror.InternalServerError("failed to release stock reservation")
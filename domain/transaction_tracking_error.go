@@ -0,0 +1,9 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrGetWaybillInternalError = httperror.InternalServerError("cannot get waybill")
+var ErrWaybillNotFound = httperror.NotFoundError("waybill not found")
+var ErrGetTransactionTracking = httperror.InternalServerError("cannot get transaction tracking")
+var ErrCreateTransactionTracking = httperror.InternalServerError("cannot create transaction tracking")
+var ErrUpdateTransactionTrackingDelivered = httperror.InternalServerError("cannot mark tracked transaction as delivered")
@@ -0,0 +1,11 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrGetUserVouchers = httperror.InternalServerError("failed to get user vouchers")
+var ErrClaimVoucher = httperror.InternalServerError("failed to claim voucher")
+var ErrDeleteUserVoucher = httperror.InternalServerError("failed to delete user voucher")
+var ErrVoucherAlreadyClaimed = httperror.BadRequestError("voucher is already claimed", "VOUCHER_ALREADY_CLAIMED")
+var ErrVoucherNotClaimable = httperror.BadRequestError("voucher is not available to claim", "VOUCHER_NOT_CLAIMABLE")
+var ErrUserVoucherNotFound = httperror.NotFoundError("user voucher not found")
+var ErrInvalidUserVoucherID = httperror.BadRequestError("invalid user voucher id", "INVALID_USER_VOUCHER_ID")
@@ -0,0 +1,10 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrGetVoucherCodes = httperror.InternalServerError("failed to get voucher codes")
+var ErrCreateVoucherCodes = httperror.InternalServerError("failed to create voucher codes")
+var ErrExportVoucherCodes = httperror.InternalServerError("failed to export voucher codes")
+var ErrVoucherCodeNotFound = httperror.BadRequestError("voucher code not found", "VOUCHER_CODE_NOT_FOUND")
+var ErrVoucherCodeAlreadyRedeemed = httperror.BadRequestError("voucher code has already been redeemed", "VOUCHER_CODE_ALREADY_REDEEMED")
+var ErrVoucherCodeTemplateTooSmall = httperror.BadRequestError("code template can't fit the requested number of codes", "VOUCHER_CODE_TEMPLATE_TOO_SMALL")
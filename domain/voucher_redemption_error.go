@@ -0,0 +1,8 @@
+package domain
+
+import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+
+var ErrVoucherQuotaExhausted = httperror.BadRequestError("voucher quota has run out", "VOUCHER_QUOTA_EXHAUSTED")
+var ErrReserveVoucherQuota = httperror.InternalServerError("failed to reserve voucher quota")
+var ErrRedeemVoucher = httperror.InternalServerError("failed to redeem voucher")
+var ErrReleaseVoucher = httperror.InternalServerError("failed to release voucher")
@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+const BACK_IN_STOCK_NOTIFY_BATCH_SIZE = 200
+
+const (
+	SMTP_BACK_IN_STOCK_SENDER_NAME = "Blanche"
+	SMTP_BACK_IN_STOCK_SUBJECT     = "Blanche - Back In Stock"
+	SMTP_BACK_IN_STOCK_HTML_PATH   = "template/email/back_in_stock.html"
+)
+
+type BackInStockSubscriptionResDTO struct {
+	ID            uint      `json:"id"`
+	ProductId     uint      `json:"product_id"`
+	VariantItemId uint      `json:"variant_item_id"`
+	VariantName   string    `json:"variant_name"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type BackInStockNotificationDTO struct {
+	SubscriptionId uint
+	UserId         uint
+	Email          string
+	ProductTitle   string
+	ProductSlug    string
+	VariantName    string
+}
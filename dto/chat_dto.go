@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"mime/multipart"
+	"time"
+)
+
+const (
+	CHAT_ROLE_BUYER    = "buyer"
+	CHAT_ROLE_MERCHANT = "merchant"
+)
+
+type StartChatConversationReqDTO struct {
+	MerchantDomain string  `json:"merchant_domain" binding:"required"`
+	ProductId      *uint   `json:"product_id"`
+	InvoiceCode    *string `json:"invoice_code"`
+}
+
+type ChatConversationListReqParamDTO struct {
+	PaginationRequest
+	Role string `form:"role" binding:"omitempty,oneof=buyer merchant"`
+}
+
+type ChatMessageFormReqDTO struct {
+	Message string                `form:"message"`
+	Image   *multipart.FileHeader `form:"image,omitempty"`
+}
+
+type ChatCounterpartDTO struct {
+	Name     string `json:"name"`
+	ImageUrl string `json:"image_url"`
+}
+
+type ChatConversationDTO struct {
+	ID             uint               `json:"id"`
+	Role           string             `json:"role"`
+	MerchantDomain string             `json:"merchant_domain"`
+	Counterpart    ChatCounterpartDTO `json:"counterpart"`
+	ProductId      *uint              `json:"product_id"`
+	ProductTitle   *string            `json:"product_title"`
+	InvoiceCode    *string            `json:"invoice_code"`
+	LastMessage    *ChatMessageDTO    `json:"last_message"`
+	UnreadCount    int64              `json:"unread_count"`
+	LastMessageAt  *time.Time         `json:"last_message_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+type ChatConversationListResDTO struct {
+	PaginationResponse
+	UnreadCount   int64                 `json:"unread_count"`
+	Conversations []ChatConversationDTO `json:"conversations"`
+}
+
+type ChatMessageDTO struct {
+	ID             uint      `json:"id"`
+	ConversationId uint      `json:"conversation_id"`
+	SenderRole     string    `json:"sender_role"`
+	Message        string    `json:"message"`
+	ImageUrl       *string   `json:"image_url"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ChatMessageListResDTO struct {
+	PaginationResponse
+	Messages []ChatMessageDTO `json:"messages"`
+}
+
+type ChatUnreadCountResDTO struct {
+	UnreadCount int64 `json:"unread_count"`
+}
@@ -1,5 +1,20 @@
 package dto
 
+import "time"
+
+const (
+	DELIVERY_RATE_WEIGHT_BUCKET          = 1000
+	DELIVERY_RATE_CACHE_DURATION_MINUTES = 360
+	DELIVERY_RATE_REQUEST_TIMEOUT        = 5 * time.Second
+	DELIVERY_RATE_SCOPE_SAME_CITY        = "SAME_CITY"
+	DELIVERY_RATE_SCOPE_SAME_PROVINCE    = "SAME_PROVINCE"
+	DELIVERY_RATE_SCOPE_INTER_PROVINCE   = "INTER_PROVINCE"
+
+	SHIPPING_RULE_TYPE_FREE_SHIPPING     = "FREE_SHIPPING"
+	SHIPPING_RULE_TYPE_FLAT_RATE         = "FLAT_RATE"
+	SHIPPING_RULE_TYPE_COURIER_SURCHARGE = "COURIER_SURCHARGE"
+)
+
 type DeliveryOption struct {
 	CourierName string `json:"courier_name"`
 	CourierCode string `json:"courier_code"`
@@ -35,6 +50,29 @@ type DeliveryUpdateMerchantOptionResDTO struct {
 	IsChecked   bool   `json:"is_checked"`
 }
 
+type MerchantShippingRuleReqDTO struct {
+	CourierCode string  `json:"courier_code" binding:"required"`
+	Type        string  `json:"type" binding:"required,oneof=FREE_SHIPPING FLAT_RATE COURIER_SURCHARGE"`
+	MinSubtotal float64 `json:"min_subtotal" binding:"gte=0"`
+	ProvinceId  *uint   `json:"province_id"`
+	Amount      float64 `json:"amount" binding:"gte=0"`
+}
+
+type MerchantShippingRuleResDTO struct {
+	ID          uint    `json:"id"`
+	CourierCode string  `json:"courier_code"`
+	Type        string  `json:"type"`
+	MinSubtotal float64 `json:"min_subtotal"`
+	ProvinceId  *uint   `json:"province_id"`
+	Amount      float64 `json:"amount"`
+}
+
+type AppliedShippingRuleDTO struct {
+	Type       string  `json:"type"`
+	ProvinceId *uint   `json:"province_id"`
+	Amount     float64 `json:"amount"`
+}
+
 type RajaOngkirDeliveryInfoReqDTO struct {
 	Origin      int    `json:"origin"`
 	Destination int    `json:"destination"`
@@ -42,20 +80,56 @@ type RajaOngkirDeliveryInfoReqDTO struct {
 	Courier     string `json:"courier"`
 }
 
+type RajaOngkirCostValueDTO struct {
+	Value float64 `json:"value"`
+	Etd   string  `json:"etd"`
+	Note  string  `json:"note"`
+}
+
+type RajaOngkirCostDTO struct {
+	Service     string                   `json:"service"`
+	Description string                   `json:"description"`
+	Cost        []RajaOngkirCostValueDTO `json:"cost"`
+}
+
+type RajaOngkirCourierCostDTO struct {
+	Code  string              `json:"code"`
+	Name  string              `json:"name"`
+	Costs []RajaOngkirCostDTO `json:"costs"`
+}
+
 type RajaOngkirDeliveryInfoResDTO struct {
 	Rajaongkir struct {
-		Results []struct {
-			Code  string `json:"code"`
-			Name  string `json:"name"`
-			Costs []struct {
-				Service     string `json:"service"`
-				Description string `json:"description"`
-				Cost        []struct {
-					Value float64 `json:"value"`
-					Etd   string  `json:"etd"`
-					Note  string  `json:"note"`
-				} `json:"cost"`
-			} `json:"costs"`
-		} `json:"results"`
+		Status struct {
+			Code        int    `json:"code"`
+			Description string `json:"description"`
+		} `json:"status"`
+		Results []RajaOngkirCourierCostDTO `json:"results"`
+	} `json:"rajaongkir"`
+}
+
+type RajaOngkirWaybillReqDTO struct {
+	Waybill string `json:"waybill"`
+	Courier string `json:"courier"`
+}
+
+type RajaOngkirWaybillManifestDTO struct {
+	ManifestCode        string `json:"manifest_code"`
+	ManifestDescription string `json:"manifest_description"`
+	ManifestDate        string `json:"manifest_date"`
+	ManifestTime        string `json:"manifest_time"`
+	CityName            string `json:"city_name"`
+}
+
+type RajaOngkirWaybillResDTO struct {
+	Rajaongkir struct {
+		Status struct {
+			Code        int    `json:"code"`
+			Description string `json:"description"`
+		} `json:"status"`
+		Result struct {
+			Delivered bool                           `json:"delivered"`
+			Manifest  []RajaOngkirWaybillManifestDTO `json:"manifest"`
+		} `json:"result"`
 	} `json:"rajaongkir"`
 }
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	EVENT_STREAM_USER_CHANNEL       = "event-stream:user:%d"
+	EVENT_STREAM_ADMIN_CHANNEL      = "event-stream:admin"
+	EVENT_STREAM_HEARTBEAT_INTERVAL = 15 * time.Second
+	EVENT_STREAM_BUFFER_SIZE        = 16
+)
+
+const (
+	EVENT_TYPE_TRANSACTION_STATUS = "transaction_status"
+	EVENT_TYPE_PAYMENT_FAILED     = "payment_failed"
+	EVENT_TYPE_DELIVERY_STATUS    = "delivery_status"
+	EVENT_TYPE_REFUND_MESSAGE     = "refund_message"
+	EVENT_TYPE_CHAT_MESSAGE       = "chat_message"
+	EVENT_TYPE_HEARTBEAT          = "heartbeat"
+)
+
+type StreamEventDTO struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+type TransactionStatusEventDTO struct {
+	InvoiceCode string    `json:"invoice_code"`
+	Status      int       `json:"status"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type PaymentFailedEventDTO struct {
+	PaymentId    string    `json:"payment_id"`
+	InvoiceCodes []string  `json:"invoice_codes"`
+	FailedAt     time.Time `json:"failed_at"`
+}
+
+type DeliveryStatusEventDTO struct {
+	InvoiceCode   string    `json:"invoice_code"`
+	Status        int       `json:"status"`
+	ReceiptNumber *string   `json:"receipt_number"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type RefundMessageEventDTO struct {
+	RefundRequestId uint                   `json:"refund_request_id"`
+	InvoiceCode     string                 `json:"invoice_code"`
+	Message         RefundRequestMsgResDTO `json:"message"`
+}
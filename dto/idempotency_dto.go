@@ -0,0 +1,12 @@
+package dto
+
+const IDEMPOTENCY_KEY_HEADER = "Idempotency-Key"
+const IDEMPOTENCY_REPLAYED_HEADER = "Idempotent-Replayed"
+const IDEMPOTENCY_KEY_MAX_LENGTH = 255
+
+type IdempotencyRecordDTO struct {
+	Fingerprint string `json:"fingerprint"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
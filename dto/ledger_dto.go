@@ -0,0 +1,34 @@
+package dto
+
+const LEDGER_DEBIT_CODE = "DR"
+const LEDGER_CREDIT_CODE = "CR"
+
+const LEDGER_OWNER_TYPE_WALLET = "wallet"
+const LEDGER_OWNER_TYPE_MERCHANT_HOLDING_ACC = "merchant_holding_account"
+const LEDGER_OWNER_TYPE_EXTERNAL = "external"
+
+const LEDGER_ACCOUNT_CODE_SEALABSPAY = "external:sealabspay"
+
+const LEDGER_ENTRY_TYPE_TOP_UP = "TOP_UP"
+const LEDGER_ENTRY_TYPE_PAYMENT = "PAYMENT"
+const LEDGER_ENTRY_TYPE_MERCHANT_WITHDRAWAL = "MERCHANT_WITHDRAWAL"
+const LEDGER_ENTRY_TYPE_CANCEL = "CANCEL"
+const LEDGER_ENTRY_TYPE_REFUND = "REFUND"
+const LEDGER_ENTRY_TYPE_PAYOUT = "PAYOUT"
+
+// balance drift below this nominal is treated as rounding noise
+const LEDGER_DRIFT_TOLERANCE = 0.01
+
+type LedgerAccountDriftDTO struct {
+	OwnerType     string  `json:"owner_type"`
+	OwnerId       uint    `json:"owner_id"`
+	ActualBalance float64 `json:"actual_balance"`
+	LedgerBalance float64 `json:"ledger_balance"`
+	Drift         float64 `json:"drift"`
+}
+
+type LedgerReconciliationResDTO struct {
+	CheckedAccounts int                     `json:"checked_accounts"`
+	DriftedAccounts int                     `json:"drifted_accounts"`
+	Drifts          []LedgerAccountDriftDTO `json:"drifts"`
+}
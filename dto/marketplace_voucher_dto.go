@@ -2,8 +2,14 @@ package dto
 
 import "time"
 
+const (
+	MARKETPLACE_VOUCHER_TYPE_DISCOUNT = "DISCOUNT"
+	MARKETPLACE_VOUCHER_TYPE_SHIPPING = "SHIPPING"
+)
+
 type MarketplaceVoucherResDTO struct {
 	ID                 uint      `json:"id"`
+	Type               string    `json:"type"`
 	Code               string    `json:"code"`
 	DiscountPercentage uint      `json:"discount_percentage"`
 	MaxDiscountNominal float64   `json:"max_discount_nominal"`
@@ -14,6 +20,7 @@ type MarketplaceVoucherResDTO struct {
 
 type MarketplaceAdminVoucherResDTO struct {
 	ID                 uint      `json:"id"`
+	Type               string    `json:"type"`
 	Code               string    `json:"code"`
 	MpDomain           string    `json:"mp_domain,omitempty"`
 	CodeSuffix         string    `json:"code_suffix,omitempty"`
@@ -33,6 +40,7 @@ type MarketplaceAdminVoucherListResDTO struct {
 
 type UpsertMarketplaceVoucherReqDTO struct {
 	Code               string    `json:"code"`
+	Type               string    `json:"type" binding:"omitempty,oneof=DISCOUNT SHIPPING"`
 	DiscountPercentage uint      `json:"discount_percentage" binding:"required,min=1,max=100"`
 	MaxDiscountNominal float64   `json:"max_discount_nominal" binding:"required"`
 	StartDate          time.Time `json:"start_date" binding:"required"`
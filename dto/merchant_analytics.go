@@ -14,6 +14,25 @@ type MerchantAnalyticsMerchantResponsivenessResBody struct {
 	Value string `json:"value"`
 }
 
+const (
+	MERCHANT_RESPONSIVENESS_METRIC_ACCEPT = "ACCEPT"
+	MERCHANT_RESPONSIVENESS_METRIC_SHIP   = "SHIP"
+	MERCHANT_RESPONSIVENESS_METRIC_REFUND = "REFUND"
+)
+
+type MerchantAnalyticsResponsivenessPercentileReqBody struct {
+	StartDate string `form:"start_date"`
+	EndDate   string `form:"end_date"`
+}
+type MerchantAnalyticsResponsivenessPercentileResBody struct {
+	Metric      string  `json:"metric"`
+	Date        string  `json:"date"`
+	SampleCount int     `json:"sample_count"`
+	P50         float64 `json:"p50"`
+	P90         float64 `json:"p90"`
+	P95         float64 `json:"p95"`
+}
+
 type MerchantAnalyticsUserConversionReqBody struct {
 	StartDate string `form:"start_date"`
 	EndDate   string `form:"end_date"`
@@ -0,0 +1,105 @@
+package dto
+
+import "time"
+
+const (
+	NOTIFICATION_TYPE_LOW_STOCK     = "LOW_STOCK"
+	NOTIFICATION_TYPE_BACK_IN_STOCK = "BACK_IN_STOCK"
+
+	NOTIFICATION_TYPE_TRANSACTION_PAID           = "TRANSACTION_PAID"
+	NOTIFICATION_TYPE_TRANSACTION_PAYMENT_FAILED = "TRANSACTION_PAYMENT_FAILED"
+	NOTIFICATION_TYPE_TRANSACTION_PROCESSED      = "TRANSACTION_PROCESSED"
+	NOTIFICATION_TYPE_TRANSACTION_ON_DELIVERY    = "TRANSACTION_ON_DELIVERY"
+	NOTIFICATION_TYPE_TRANSACTION_DELIVERED      = "TRANSACTION_DELIVERED"
+	NOTIFICATION_TYPE_TRANSACTION_CANCELED       = "TRANSACTION_CANCELED"
+	NOTIFICATION_TYPE_TRANSACTION_COMPLETED      = "TRANSACTION_COMPLETED"
+
+	NOTIFICATION_TYPE_MERCHANT_NEW_ORDER             = "MERCHANT_NEW_ORDER"
+	NOTIFICATION_TYPE_MERCHANT_TRANSACTION_CANCELED  = "MERCHANT_TRANSACTION_CANCELED"
+	NOTIFICATION_TYPE_MERCHANT_TRANSACTION_COMPLETED = "MERCHANT_TRANSACTION_COMPLETED"
+
+	NOTIFICATION_TYPE_REFUND_ACCEPTED_BY_SELLER   = "REFUND_ACCEPTED_BY_SELLER"
+	NOTIFICATION_TYPE_REFUND_REJECTED_BY_SELLER   = "REFUND_REJECTED_BY_SELLER"
+	NOTIFICATION_TYPE_REFUND_ACCEPTED_BY_ADMIN    = "REFUND_ACCEPTED_BY_ADMIN"
+	NOTIFICATION_TYPE_REFUND_REJECTED_BY_ADMIN    = "REFUND_REJECTED_BY_ADMIN"
+	NOTIFICATION_TYPE_MERCHANT_REFUND_REQUESTED   = "MERCHANT_REFUND_REQUESTED"
+	NOTIFICATION_TYPE_MERCHANT_REFUND_CANCELED    = "MERCHANT_REFUND_CANCELED"
+	NOTIFICATION_TYPE_MERCHANT_REFUND_ACCEPTED    = "MERCHANT_REFUND_ACCEPTED"
+	NOTIFICATION_TYPE_MERCHANT_REFUND_REJECTED    = "MERCHANT_REFUND_REJECTED"
+	NOTIFICATION_TYPE_MERCHANT_REFUND_BUYER_CLOSE = "MERCHANT_REFUND_BUYER_CLOSE"
+
+	NOTIFICATION_TYPE_WALLET_TOP_UP_SUCCESS = "WALLET_TOP_UP_SUCCESS"
+	NOTIFICATION_TYPE_WALLET_TOP_UP_FAILED  = "WALLET_TOP_UP_FAILED"
+	NOTIFICATION_TYPE_MERCHANT_WITHDRAW     = "MERCHANT_WITHDRAW"
+)
+
+// NotificationTemplate describes the recipient and text of a typed notification,
+// Message is formatted with the invoice code or the amount.
+type NotificationTemplate struct {
+	Title         string
+	Message       string
+	IsForMerchant bool
+}
+
+var NotificationTemplates = map[string]NotificationTemplate{
+	NOTIFICATION_TYPE_TRANSACTION_PAID:           {Title: "Payment received", Message: "Payment for %s is received and waiting for the seller"},
+	NOTIFICATION_TYPE_TRANSACTION_PAYMENT_FAILED: {Title: "Payment failed", Message: "Payment for %s failed, your order is back in checkout"},
+	NOTIFICATION_TYPE_TRANSACTION_PROCESSED:      {Title: "Order processed", Message: "The seller is processing %s"},
+	NOTIFICATION_TYPE_TRANSACTION_ON_DELIVERY:    {Title: "Order shipped", Message: "%s is on its way"},
+	NOTIFICATION_TYPE_TRANSACTION_DELIVERED:      {Title: "Order delivered", Message: "%s has been delivered, please complete the order"},
+	NOTIFICATION_TYPE_TRANSACTION_CANCELED:       {Title: "Order canceled", Message: "%s is canceled and the payment is refunded to your wallet"},
+	NOTIFICATION_TYPE_TRANSACTION_COMPLETED:      {Title: "Order completed", Message: "%s is completed, don't forget to leave a review"},
+
+	NOTIFICATION_TYPE_MERCHANT_NEW_ORDER:             {Title: "New order", Message: "You have a new order %s", IsForMerchant: true},
+	NOTIFICATION_TYPE_MERCHANT_TRANSACTION_CANCELED:  {Title: "Order canceled", Message: "%s is canceled", IsForMerchant: true},
+	NOTIFICATION_TYPE_MERCHANT_TRANSACTION_COMPLETED: {Title: "Order completed", Message: "%s is completed and the fund is added to your balance", IsForMerchant: true},
+
+	NOTIFICATION_TYPE_REFUND_ACCEPTED_BY_SELLER:   {Title: "Refund accepted by seller", Message: "The seller accepted your refund request for %s, waiting for admin review"},
+	NOTIFICATION_TYPE_REFUND_REJECTED_BY_SELLER:   {Title: "Refund rejected by seller", Message: "The seller rejected your refund request for %s, waiting for admin review"},
+	NOTIFICATION_TYPE_REFUND_ACCEPTED_BY_ADMIN:    {Title: "Refund accepted", Message: "Your refund request for %s is accepted, the payment is refunded to your wallet"},
+	NOTIFICATION_TYPE_REFUND_REJECTED_BY_ADMIN:    {Title: "Refund rejected", Message: "Your refund request for %s is rejected by admin"},
+	NOTIFICATION_TYPE_MERCHANT_REFUND_REQUESTED:   {Title: "Refund requested", Message: "The buyer requested a refund for %s", IsForMerchant: true},
+	NOTIFICATION_TYPE_MERCHANT_REFUND_CANCELED:    {Title: "Refund canceled", Message: "The buyer canceled the refund request for %s", IsForMerchant: true},
+	NOTIFICATION_TYPE_MERCHANT_REFUND_ACCEPTED:    {Title: "Refund accepted", Message: "The refund request for %s is accepted by admin", IsForMerchant: true},
+	NOTIFICATION_TYPE_MERCHANT_REFUND_REJECTED:    {Title: "Refund rejected", Message: "The refund request for %s is rejected by admin", IsForMerchant: true},
+	NOTIFICATION_TYPE_MERCHANT_REFUND_BUYER_CLOSE: {Title: "Refund closed", Message: "The buyer accepted the refund decision for %s", IsForMerchant: true},
+
+	NOTIFICATION_TYPE_WALLET_TOP_UP_SUCCESS: {Title: "Top up success", Message: "Rp%.0f is added to your wallet"},
+	NOTIFICATION_TYPE_WALLET_TOP_UP_FAILED:  {Title: "Top up failed", Message: "Top up of Rp%.0f to your wallet failed"},
+	NOTIFICATION_TYPE_MERCHANT_WITHDRAW:     {Title: "Withdrawal success", Message: "Rp%.0f is withdrawn to your wallet", IsForMerchant: true},
+}
+
+// TransactionStatusNotificationTypes maps a merchant status update to the buyer notification.
+var TransactionStatusNotificationTypes = map[int]string{
+	TransactionStatusProcessed:  NOTIFICATION_TYPE_TRANSACTION_PROCESSED,
+	TransactionStatusOnDelivery: NOTIFICATION_TYPE_TRANSACTION_ON_DELIVERY,
+	TransactionStatusDelivered:  NOTIFICATION_TYPE_TRANSACTION_DELIVERED,
+	TransactionStatusCanceled:   NOTIFICATION_TYPE_TRANSACTION_CANCELED,
+}
+
+type NotificationListReqParamDTO struct {
+	PaginationRequest
+	Type     string `form:"type"`
+	IsUnread bool   `form:"is_unread"`
+}
+
+type NotificationDTO struct {
+	ID        uint       `json:"id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	Link      string     `json:"link"`
+	IsRead    bool       `json:"is_read"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type NotificationListResDTO struct {
+	PaginationResponse
+	UnreadCount   int64             `json:"unread_count"`
+	Notifications []NotificationDTO `json:"notifications"`
+}
+
+type NotificationUnreadCountResDTO struct {
+	UnreadCount int64 `json:"unread_count"`
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+import (
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+)
 
 type PostOrderSummaryMerchantsDTO struct {
 	MerchantId      int    `json:"merchant_id"`
@@ -14,6 +18,8 @@ type PostOrderSummaryReqDTO struct {
 	AddressId          int                            `json:"address_id"`
 	Merchants          []PostOrderSummaryMerchantsDTO `json:"merchants"`
 	VoucherMarketplace string                         `json:"voucher_marketplace"`
+	PaymentMethodCode  string                         `json:"payment_method_code"`
+	AutoApplyVoucher   bool                           `json:"auto_apply_voucher"`
 }
 
 type OrderItemDTO struct {
@@ -34,6 +40,8 @@ type OrderItemDTO struct {
 	DiscountPrice  float64 `json:"discount_price"`
 	Quantity       int     `json:"quantity"`
 	Stock          int     `json:"stock"`
+	ReservedStock  int     `json:"reserved_stock"`
+	AvailableStock int     `json:"available_stock"`
 	Notes          *string `json:"notes"`
 	IsValid        bool    `json:"is_valid"`
 }
@@ -54,18 +62,26 @@ type DeliveryServiceDTO struct {
 	UserCity       string `json:"user_city"`
 	Etd            string `json:"etd"`
 	Note           string `json:"note"`
+
+	BaseCost     float64                  `json:"base_cost"`
+	AppliedRules []AppliedShippingRuleDTO `json:"applied_rules"`
 }
 
 type OrderItemPerMerchantDTO struct {
-	Merchant          OrderMerchantDTO   `json:"merchant"`
-	Items             []OrderItemDTO     `json:"items"`
-	DeliveryService   DeliveryServiceDTO `json:"delivery_service"`
-	SubTotal          float64            `json:"sub_total"`
-	DeliveryCost      float64            `json:"delivery_cost"`
-	Discount          float64            `json:"discount"`
-	Total             float64            `json:"total"`
-	IsVoucherInvalid  bool               `json:"is_voucher_invalid"`
-	MerchantVoucherId *uint              `json:"-"`
+	Merchant          OrderMerchantDTO      `json:"merchant"`
+	Items             []OrderItemDTO        `json:"items"`
+	DeliveryService   DeliveryServiceDTO    `json:"delivery_service"`
+	SubTotal          float64               `json:"sub_total"`
+	DeliveryCost      float64               `json:"delivery_cost"`
+	ShippingDiscount  float64               `json:"shipping_discount"`
+	Discount          float64               `json:"discount"`
+	Total             float64               `json:"total"`
+	IsVoucherInvalid  bool                  `json:"is_voucher_invalid"`
+	VoucherReason     string                `json:"voucher_invalid_reason,omitempty"`
+	SuggestedVoucher  *SuggestedVoucherDTO  `json:"suggested_voucher,omitempty"`
+	VoucherCandidates []VoucherCandidateDTO `json:"voucher_candidates,omitempty"`
+	MerchantVoucherId *uint                 `json:"-"`
+	MerchantCodeId    *uint                 `json:"-"`
 }
 
 type PostOrderSummaryResDTO struct {
@@ -76,12 +92,18 @@ type PostOrderSummaryResDTO struct {
 	DeliveryCost        float64                   `json:"delivery_cost"`
 	DiscountMerchant    float64                   `json:"discount_merchant"`
 	DiscountMarketplace float64                   `json:"discount_marketplace"`
+	DiscountShipping    float64                   `json:"discount_shipping"`
 	Total               float64                   `json:"total"`
 	IsVouchervalid      bool                      `json:"is_voucher_valid"`
+	VoucherReason       string                    `json:"voucher_invalid_reason,omitempty"`
+	SuggestedVoucher    *SuggestedVoucherDTO      `json:"suggested_voucher,omitempty"`
+	VoucherCandidates   []VoucherCandidateDTO     `json:"voucher_candidates,omitempty"`
 	IsOrderEligible     bool                      `json:"is_order_eligible"`
 	IsOrderValid        bool                      `json:"is_order_valid"`
+	ReservationExpireAt *time.Time                `json:"reservation_expire_at"`
 
 	MarketplaceVoucherId *uint              `json:"-"`
+	MarketplaceCodeId    *uint              `json:"-"`
 	Address              entity.UserAddress `json:"-"`
 }
 
@@ -10,3 +10,6 @@ type PaymentMehodDTO struct {
 	Name string `json:"name"`
 	Code string `json:"code"`
 }
+
+const PAYMENT_METHOD_CODE_WALLET = "WALLET"
+const PAYMENT_METHOD_CODE_SLP = "SLP"
@@ -0,0 +1,39 @@
+package dto
+
+const PAYMENT_STATUS_PENDING = "PENDING"
+const PAYMENT_STATUS_PAID = "PAID"
+const PAYMENT_STATUS_FAILED = "FAILED"
+const PAYMENT_STATUS_CANCELED = "CANCELED"
+
+type PaymentChargeReqDTO struct {
+	AccountNumber string
+	Amount        uint
+	RedirectUrl   string
+}
+
+type PaymentChargeResDTO struct {
+	RedirectUrl     string
+	PaymentId       string
+	PaymentRecordId uint
+}
+
+type PaymentCallbackReqDTO struct {
+	PaymentId    string
+	Amount       string
+	MerchantCode string
+	Status       string
+	Message      string
+	Signature    string
+}
+
+type PaymentCallbackResDTO struct {
+	PaymentId string
+	Amount    uint
+	IsSuccess bool
+}
+
+type PaymentStatusResDTO struct {
+	PaymentId string
+	Amount    uint
+	Status    string
+}
@@ -9,12 +9,14 @@ type ProductListReqParamDTO struct {
 	MerchantDomain   string  `form:"merchant,default=" binding:"max=255"`
 	CategorySlug     string  `form:"cat,default=" binding:"max=255"`
 	Search           string  `form:"q,default=" binding:"max=255"`
-	SortBy           string  `form:"sort_by,default=avg_rating" binding:"oneof=avg_rating num_of_sale created_at min_discount_price"`
+	SortBy           string  `form:"sort_by,default=avg_rating" binding:"oneof=avg_rating num_of_sale created_at min_discount_price relevance"`
 	SortDir          string  `form:"sort_dir,default=desc" binding:"oneof=asc desc"`
 	MinPrice         float64 `form:"min_price,default=0" binding:"number,min=0,max=999999999999"`
 	MaxPrice         float64 `form:"max_price,default=999999999999" binding:"number,min=0,max=999999999999"`
 	MinRating        int     `form:"min_rating,default=0" binding:"number,min=0,max=5"`
 	SellerCityId     string  `form:"seller_city_id,default="`
+	WithFacets       bool    `form:"facets,default=false"`
+	WithHighlight    bool    `form:"highlight,default=false"`
 	IsMerchant       bool
 	SellerCityIdList []uint
 	Pagination       PaginationRequest
@@ -32,6 +34,7 @@ type ProductResDTO struct {
 	AvgRating        float64 `json:"avg_rating"`
 	ThumbnailImg     string  `json:"thumbnail_img"`
 	SellerCity       string  `json:"seller_city"`
+	Highlight        string  `json:"highlight,omitempty"`
 }
 
 type ProductSellerResDTO struct {
@@ -54,7 +57,9 @@ type ProductSellerListResDTO struct {
 
 type ProductListResDTO struct {
 	PaginationResponse
-	Products []ProductResDTO `json:"products"`
+	Products   []ProductResDTO         `json:"products"`
+	Facets     *ProductSearchFacetsDTO `json:"facets,omitempty"`
+	DidYouMean string                  `json:"did_you_mean,omitempty"`
 }
 
 type CreateProductReqDTO struct {
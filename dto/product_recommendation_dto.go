@@ -0,0 +1,30 @@
+package dto
+
+const PRODUCT_RECOMMENDATION_LIMIT = 150
+const PRODUCT_SIMILARITY_MAX_NEIGHBORS = 50
+
+const PRODUCT_INTERACTION_WEIGHT_PURCHASE = 3.0
+const PRODUCT_INTERACTION_WEIGHT_CART = 2.0
+const PRODUCT_INTERACTION_WEIGHT_FAVORITE = 1.0
+
+type ProductRecommendationScoreDTO struct {
+	ProductId uint
+	Score     float64
+}
+
+const PRODUCT_RELATION_TYPE_BOUGHT_TOGETHER = "BOUGHT_TOGETHER"
+const PRODUCT_RELATION_TYPE_SIMILAR = "SIMILAR"
+
+const PRODUCT_RELATED_MAX_NEIGHBORS = 20
+const PRODUCT_RELATED_CACHE_MINUTES = 60
+
+// PRODUCT_SIMILAR_PRICE_BAND is the maximum relative price difference of a similar product.
+const PRODUCT_SIMILAR_PRICE_BAND = 0.3
+
+type RelatedProductReqDTO struct {
+	Limit int `form:"limit,default=10" binding:"number,min=1,max=20"`
+}
+
+type RelatedProductListResDTO struct {
+	Products []ProductResDTO `json:"products"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+const PRODUCT_SORT_BY_RELEVANCE = "relevance"
+const PRODUCT_SEARCH_FACET_LIMIT = 20
+const PRODUCT_SEARCH_HEADLINE_OPTIONS = "StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=10, MaxFragments=2"
+
+// ProductSearchPriceBuckets are the upper bounds of the price facet ranges.
+var ProductSearchPriceBuckets = []float64{50000, 100000, 250000, 500000, 1000000}
+
+type ProductSearchFacetDTO struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Slug  string `json:"slug,omitempty"`
+	Count int64  `json:"count"`
+}
+
+type ProductPriceFacetDTO struct {
+	MinPrice float64  `json:"min_price"`
+	MaxPrice *float64 `json:"max_price"`
+	Count    int64    `json:"count"`
+}
+
+type ProductRatingFacetDTO struct {
+	MinRating int   `json:"min_rating"`
+	Count     int64 `json:"count"`
+}
+
+type ProductSearchFacetsDTO struct {
+	Categories   []ProductSearchFacetDTO `json:"categories"`
+	SellerCities []ProductSearchFacetDTO `json:"seller_cities"`
+	PriceRanges  []ProductPriceFacetDTO  `json:"price_ranges"`
+	Ratings      []ProductRatingFacetDTO `json:"ratings"`
+}
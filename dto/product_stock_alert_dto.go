@@ -0,0 +1,41 @@
+package dto
+
+const PRODUCT_LOW_STOCK_DEFAULT_THRESHOLD = 5
+const PRODUCT_LOW_STOCK_ALERT_BATCH_SIZE = 200
+
+const (
+	SMTP_LOW_STOCK_SENDER_NAME = "Blanche"
+	SMTP_LOW_STOCK_SUBJECT     = "Blanche - Low Stock Alert"
+	SMTP_LOW_STOCK_HTML_PATH   = "template/email/low_stock.html"
+)
+
+type UpdateLowStockThresholdReqDTO struct {
+	Threshold *int `json:"threshold" binding:"required,min=0"`
+}
+
+type ProductStockAlertResDTO struct {
+	ProductId  uint `json:"product_id"`
+	Threshold  int  `json:"threshold"`
+	IsLowStock bool `json:"is_low_stock"`
+}
+
+type LowStockProductDTO struct {
+	ID         uint   `json:"id"`
+	Title      string `json:"title"`
+	Slug       string `json:"slug"`
+	TotalStock int    `json:"total_stock"`
+	MinStock   int    `json:"min_stock"`
+	Threshold  int    `json:"threshold"`
+}
+
+type LowStockProductListResDTO struct {
+	PaginationResponse
+	Products []LowStockProductDTO `json:"products"`
+}
+
+type LowStockProductAlertDTO struct {
+	LowStockProductDTO
+	MerchantUserId uint
+	MerchantName   string
+	MerchantEmail  string
+}
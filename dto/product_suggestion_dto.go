@@ -0,0 +1,31 @@
+package dto
+
+const PRODUCT_SUGGESTION_CACHE_MINUTES = 10
+const PRODUCT_DID_YOU_MEAN_MIN_SIMILARITY = 0.3
+
+type ProductSuggestionReqDTO struct {
+	Query string `form:"q" binding:"required,max=100"`
+	Limit int    `form:"limit,default=5" binding:"number,min=1,max=10"`
+}
+
+type ProductTitleSuggestionDTO struct {
+	Title          string `json:"title"`
+	Slug           string `json:"slug"`
+	MerchantDomain string `json:"merchant_domain"`
+}
+
+type CategorySuggestionDTO struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type MerchantSuggestionDTO struct {
+	Name   string `json:"name"`
+	Domain string `json:"domain"`
+}
+
+type ProductSuggestionResDTO struct {
+	Products   []ProductTitleSuggestionDTO `json:"products"`
+	Categories []CategorySuggestionDTO     `json:"categories"`
+	Merchants  []MerchantSuggestionDTO     `json:"merchants"`
+}
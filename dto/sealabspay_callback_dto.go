@@ -0,0 +1,37 @@
+package dto
+
+import "time"
+
+const SLP_CALLBACK_STATUS_PENDING = "PENDING"
+const SLP_CALLBACK_STATUS_PROCESSED = "PROCESSED"
+const SLP_CALLBACK_STATUS_FAILED = "FAILED"
+
+const SLP_CALLBACK_MAX_ATTEMPTS = 8
+const SLP_CALLBACK_RETRY_BATCH_SIZE = 50
+const SLP_CALLBACK_RETRY_BASE_DELAY = 30 * time.Second
+const SLP_CALLBACK_RETRY_MAX_DELAY = time.Hour
+const SLP_CALLBACK_PROCESS_LEASE = 2 * time.Minute
+
+type SealabspayCallbackListReqParamDTO struct {
+	PaginationRequest
+	Status string `form:"status" binding:"omitempty,oneof=PENDING PROCESSED FAILED"`
+}
+
+type SealabspayCallbackDTO struct {
+	ID            uint       `json:"id"`
+	TxnId         string     `json:"txn_id"`
+	Amount        string     `json:"amount"`
+	Status        string     `json:"status"`
+	Message       string     `json:"message"`
+	ProcessStatus string     `json:"process_status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+	ProcessedAt   *time.Time `json:"processed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type SealabspayCallbackListResDTO struct {
+	PaginationResponse
+	Callbacks []SealabspayCallbackDTO `json:"callbacks"`
+}
@@ -17,3 +17,15 @@ type SealabspayReqDTO struct {
 	Status       string `json:"status"`
 	TxnId        string `json:"txn_id"`
 }
+
+type SealabspayStatusDataDTO struct {
+	TxnId  string `json:"txn_id"`
+	Amount string `json:"amount"`
+	Status string `json:"status"`
+}
+
+type SealabspayStatusResDTO struct {
+	Code    string                  `json:"code"`
+	Message string                  `json:"message"`
+	Data    SealabspayStatusDataDTO `json:"data"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+import "time"
+
+const STOCK_RESERVATION_TTL = 15 * time.Minute
@@ -6,3 +6,16 @@ type TransactionDeliveryStatusResDTO struct {
 	OnDeliveryAt  *time.Time `json:"on_delivery_at"`
 	OnDeliveredAt *time.Time `json:"on_delivered_at"`
 }
+
+const (
+	TRACKING_SYNC_BATCH_SIZE       = 100
+	TRACKING_MANIFEST_TIME_FORMAT  = "2006-01-02 15:04"
+	TRACKING_MANIFEST_CODE_DEFAULT = "-"
+)
+
+type TransactionTrackingEventResDTO struct {
+	Code        string    `json:"code"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
@@ -49,9 +49,10 @@ type TransactionDeliveryOptionResDTO struct {
 }
 
 type TransactionDetailShippingResDTO struct {
-	Address                   entity.TransactionAddress       `json:"address"`
-	DeliveryOption            TransactionDeliveryOptionResDTO `json:"delivery_option"`
-	TransactionDeliveryStatus TransactionDeliveryStatusResDTO `json:"transaction_delivery_status"`
+	Address                   entity.TransactionAddress        `json:"address"`
+	DeliveryOption            TransactionDeliveryOptionResDTO  `json:"delivery_option"`
+	TransactionDeliveryStatus TransactionDeliveryStatusResDTO  `json:"transaction_delivery_status"`
+	TrackingEvents            []TransactionTrackingEventResDTO `json:"tracking_events,omitempty"`
 }
 
 type TransactionDetailPaymentResDTO struct {
@@ -118,6 +119,7 @@ type MakeTransactionReqDTO struct {
 	AddressId          int                            `json:"address_id" binding:"required"`
 	Merchants          []PostOrderSummaryMerchantsDTO `json:"merchants"`
 	VoucherMarketplace string                         `json:"voucher_marketplace"`
+	AutoApplyVoucher   bool                           `json:"auto_apply_voucher"`
 
 	PaymentTotal         float64 `json:"payment_total" binding:"required"`
 	PaymentMethodCode    string  `json:"payment_method_code" binding:"required"`
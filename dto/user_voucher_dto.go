@@ -0,0 +1,46 @@
+package dto
+
+import "time"
+
+const (
+	USER_VOUCHER_SOURCE_MARKETPLACE = "MARKETPLACE"
+	USER_VOUCHER_SOURCE_MERCHANT    = "MERCHANT"
+)
+
+type ClaimVoucherReqDTO struct {
+	VoucherCode    string `json:"voucher_code" binding:"required"`
+	MerchantDomain string `json:"merchant_domain"`
+}
+
+// UserVoucherResDTO is a claimed voucher along with whether it applies to the
+// checked items in the user's cart.
+type UserVoucherResDTO struct {
+	ID                 uint      `json:"id"`
+	Source             string    `json:"source"`
+	MerchantDomain     string    `json:"merchant_domain,omitempty"`
+	Code               string    `json:"code"`
+	Type               string    `json:"type,omitempty"`
+	DiscountPercentage uint      `json:"discount_percentage,omitempty"`
+	DiscountNominal    float64   `json:"discount_nominal,omitempty"`
+	MaxDiscountNominal float64   `json:"max_discount_nominal"`
+	MinOrderNominal    float64   `json:"min_order_nominal"`
+	ExpiredAt          time.Time `json:"expired_at"`
+	IsApplicable       bool      `json:"is_applicable"`
+	Reason             string    `json:"reason,omitempty"`
+	EstimatedDiscount  float64   `json:"estimated_discount"`
+}
+
+// VoucherCandidateDTO is a voucher weighed by the auto-apply mode of the order summary.
+type VoucherCandidateDTO struct {
+	Code         string  `json:"code"`
+	Discount     float64 `json:"discount"`
+	IsApplied    bool    `json:"is_applied"`
+	IsApplicable bool    `json:"is_applicable"`
+	Reason       string  `json:"reason,omitempty"`
+}
+
+// SuggestedVoucherDTO points to a claimed voucher saving more than the one applied.
+type SuggestedVoucherDTO struct {
+	Code     string  `json:"code"`
+	Discount float64 `json:"discount"`
+}
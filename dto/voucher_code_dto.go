@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+const (
+	VOUCHER_CODE_STATUS_AVAILABLE = "AVAILABLE"
+	VOUCHER_CODE_STATUS_REDEEMED  = "REDEEMED"
+
+	VOUCHER_CODE_GENERATE_MAX_ATTEMPTS = 5
+	VOUCHER_CODE_CSV_TIME_FORMAT       = "2006-01-02 15:04:05"
+)
+
+// GenerateVoucherCodesReqDTO describes a batch of codes made of the voucher owner's
+// prefix, the template prefix and random characters up to Length.
+type GenerateVoucherCodesReqDTO struct {
+	Prefix string `json:"prefix" binding:"omitempty,alphanum,uppercase"`
+	Length int    `json:"length" binding:"required,min=3,max=5"`
+	Count  int    `json:"count" binding:"required,min=1,max=1000"`
+}
+
+type VoucherCodeListParamReqDTO struct {
+	PaginationRequest
+	Status string `form:"status" binding:"omitempty,oneof=AVAILABLE REDEEMED"`
+}
+
+type VoucherCodeResDTO struct {
+	ID         uint       `json:"id"`
+	Code       string     `json:"code"`
+	Status     string     `json:"status"`
+	RedeemedAt *time.Time `json:"redeemed_at"`
+	RedeemedBy *uint      `json:"redeemed_by"`
+	PaymentId  *string    `json:"payment_id"`
+}
+
+type GenerateVoucherCodesResDTO struct {
+	Generated int                 `json:"generated"`
+	Codes     []VoucherCodeResDTO `json:"codes"`
+}
+
+type VoucherCodeListResDTO struct {
+	PaginationResponse
+	Codes []VoucherCodeResDTO `json:"codes"`
+}
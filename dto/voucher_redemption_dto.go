@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+const (
+	VOUCHER_QUOTA_CACHE_DURATION  = 10 * time.Minute
+	VOUCHER_QUOTA_KEY_MARKETPLACE = "voucher-quota:marketplace:%d"
+	VOUCHER_QUOTA_KEY_MERCHANT    = "voucher-quota:merchant:%d"
+)
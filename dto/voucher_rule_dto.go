@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+const (
+	VOUCHER_RULE_TYPE_FIRST_PURCHASE       = "FIRST_PURCHASE"
+	VOUCHER_RULE_TYPE_USAGE_LIMIT_PER_USER = "USAGE_LIMIT_PER_USER"
+	VOUCHER_RULE_TYPE_CATEGORY             = "CATEGORY"
+	VOUCHER_RULE_TYPE_PRODUCT              = "PRODUCT"
+	VOUCHER_RULE_TYPE_MERCHANT             = "MERCHANT"
+	VOUCHER_RULE_TYPE_PAYMENT_METHOD       = "PAYMENT_METHOD"
+	VOUCHER_RULE_TYPE_NEW_USER_DAYS        = "NEW_USER_DAYS"
+)
+
+const (
+	VOUCHER_REJECT_REASON_NOT_FOUND            = "VOUCHER_NOT_FOUND"
+	VOUCHER_REJECT_REASON_CODE_REDEEMED        = "CODE_ALREADY_REDEEMED"
+	VOUCHER_REJECT_REASON_MIN_ORDER_NOT_MET    = "MIN_ORDER_NOT_MET"
+	VOUCHER_REJECT_REASON_NOT_FIRST_PURCHASE   = "NOT_FIRST_PURCHASE"
+	VOUCHER_REJECT_REASON_USAGE_LIMIT_REACHED  = "USAGE_LIMIT_REACHED"
+	VOUCHER_REJECT_REASON_CATEGORY_NOT_ALLOWED = "CATEGORY_NOT_ELIGIBLE"
+	VOUCHER_REJECT_REASON_PRODUCT_NOT_ALLOWED  = "PRODUCT_NOT_ELIGIBLE"
+	VOUCHER_REJECT_REASON_MERCHANT_NOT_ALLOWED = "MERCHANT_NOT_ELIGIBLE"
+	VOUCHER_REJECT_REASON_PAYMENT_NOT_ALLOWED  = "PAYMENT_METHOD_NOT_ELIGIBLE"
+	VOUCHER_REJECT_REASON_PAYMENT_REQUIRED     = "PAYMENT_METHOD_REQUIRED"
+	VOUCHER_REJECT_REASON_NOT_NEW_USER         = "NOT_NEW_USER"
+	VOUCHER_REJECT_REASON_MERCHANT_NOT_IN_CART = "MERCHANT_NOT_IN_CART"
+)
+
+type VoucherRuleReqDTO struct {
+	Type    string   `json:"type" binding:"required,oneof=FIRST_PURCHASE USAGE_LIMIT_PER_USER CATEGORY PRODUCT MERCHANT PAYMENT_METHOD NEW_USER_DAYS"`
+	Value   int      `json:"value" binding:"min=0"`
+	Targets []string `json:"targets"`
+}
+
+type UpdateVoucherRulesReqDTO struct {
+	Rules []VoucherRuleReqDTO `json:"rules" binding:"dive"`
+}
+
+type VoucherRuleResDTO struct {
+	ID      uint     `json:"id"`
+	Type    string   `json:"type"`
+	Value   int      `json:"value"`
+	Targets []string `json:"targets"`
+}
+
+// VoucherRuleCheckDTO is what a voucher's rules are evaluated against at checkout.
+type VoucherRuleCheckDTO struct {
+	UserId            uint
+	UserCreatedAt     time.Time
+	PaymentMethodCode string
+	IsAutoApply       bool
+	Items             []OrderItemDTO
+}
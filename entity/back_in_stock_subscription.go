@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+)
+
+type BackInStockSubscription struct {
+	ID            uint `gorm:"primaryKey"`
+	UserId        uint `gorm:"not null"`
+	ProductId     uint `gorm:"not null"`
+	VariantItemId uint `gorm:"not null"`
+	VariantName   string
+	TriggeredAt   *time.Time
+	NotifiedAt    *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ChatConversation struct {
+	ID             uint `gorm:"primaryKey"`
+	MerchantDomain string
+	Merchant       Merchant `gorm:"foreignKey:MerchantDomain;references:Domain"`
+	BuyerId        uint
+	Buyer          User `gorm:"foreignKey:BuyerId"`
+	ProductId      *uint
+	Product        *Product
+	TransactionId  *uint
+	Transaction    *Transaction
+
+	Participants []ChatParticipant `gorm:"foreignKey:ConversationId"`
+
+	LastMessageAt     *time.Time
+	BuyerWaitingSince *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+type ChatParticipant struct {
+	ID                uint `gorm:"primaryKey"`
+	ConversationId    uint
+	UserId            uint
+	Role              string
+	LastReadMessageId uint
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ChatMessage struct {
+	ID              uint `gorm:"primaryKey"`
+	ConversationId  uint
+	SenderId        uint
+	SenderRole      string
+	Message         string
+	ImageUrl        *string
+	ResponseSeconds *float64
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
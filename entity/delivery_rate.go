@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeliveryRate is the offline rate table used when RajaOngkir is unreachable.
+type DeliveryRate struct {
+	ID          uint `gorm:"primaryKey"`
+	CourierCode string
+	ServiceCode string
+	Description string
+	Scope       string
+	CostPerKg   float64
+	Etd         string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LedgerAccount struct {
+	ID        uint   `gorm:"primary_key"`
+	Code      string `gorm:"not null;unique"`
+	OwnerType string `gorm:"not null"`
+	OwnerId   uint
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+type LedgerAccountBalance struct {
+	OwnerType     string
+	OwnerId       uint
+	ActualBalance float64
+	LedgerBalance float64
+}
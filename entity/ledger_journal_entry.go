@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LedgerJournalEntry struct {
+	ID             uint   `gorm:"primary_key"`
+	Type           string `gorm:"not null"`
+	Reference      string `gorm:"not null"`
+	Notes          string
+	LedgerPostings []LedgerPosting
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
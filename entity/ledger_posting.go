@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LedgerPosting struct {
+	ID                   uint `gorm:"primary_key"`
+	LedgerJournalEntryId uint `gorm:"not null"`
+	LedgerAccountId      uint `gorm:"not null"`
+	LedgerAccount        LedgerAccount
+	Direction            string  `gorm:"not null"`
+	Amount               float64 `gorm:"not null"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
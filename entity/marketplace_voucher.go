@@ -8,6 +8,7 @@ import (
 
 type MarketplaceVoucher struct {
 	ID                 uint `gorm:"primary_key"`
+	Type               string
 	DiscountPercentage uint
 	StartDate          time.Time
 	ExpiredAt          time.Time
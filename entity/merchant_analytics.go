@@ -17,8 +17,24 @@ type MerchantDailyAnalyticsHist struct {
 	CountReview int
 	OAD         float64
 	OSD         float64
+	CRT         float64
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt
 }
+
+type MerchantDailyResponsivenessHist struct {
+	ID            uint `gorm:"primary_key"`
+	DatePartition time.Time
+	Domain        string
+	Metric        string
+
+	SampleCount int
+	P50         float64
+	P90         float64
+	P95         float64
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -11,6 +11,7 @@ type MerchantDeliveryOption struct {
 	MerchantId       uint
 	DeliveryOptionId uint
 	DeliveryOption   DeliveryOption
+	ShippingRules    []MerchantShippingRule
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
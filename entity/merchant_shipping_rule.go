@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MerchantShippingRule struct {
+	ID                       uint `gorm:"primaryKey"`
+	MerchantDeliveryOptionId uint
+	Type                     string
+	MinSubtotal              float64
+	ProvinceId               *uint
+	Amount                   float64
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Notification struct {
+	ID      uint   `gorm:"primaryKey"`
+	UserId  uint   `gorm:"not null"`
+	Type    string `gorm:"not null"`
+	Title   string `gorm:"not null"`
+	Message string
+	Link    string
+	ReadAt  *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
@@ -17,9 +17,10 @@ type PaymentRecord struct {
 	Transactions             []Transaction            `gorm:"many2many:transaction_payment_records;references:ID;joinReferences:TransactionId;foreignKey:PaymentId;joinForeignKey:PaymentId;"`
 	TransactionPaymentRecord TransactionPaymentRecord `gorm:"foreignKey:PaymentId;references:PaymentId;"`
 
-	PaidAt     *time.Time
-	CanceledAt *time.Time
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	DeletedAt  gorm.DeletedAt
+	PaidAt        *time.Time
+	CanceledAt    *time.Time
+	LastCheckedAt *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt
 }
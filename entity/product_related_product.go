@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+type ProductRelatedProduct struct {
+	ProductId        uint   `gorm:"primaryKey"`
+	RelatedProductId uint   `gorm:"primaryKey"`
+	RelationType     string `gorm:"primaryKey"`
+	Score            float64
+	UpdatedAt        time.Time
+}
@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+type ProductSimilarity struct {
+	ProductId        uint `gorm:"primaryKey"`
+	RelatedProductId uint `gorm:"primaryKey"`
+	Score            float64
+	UpdatedAt        time.Time
+}
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+)
+
+type ProductStockAlert struct {
+	ProductId  uint `gorm:"primaryKey"`
+	Threshold  int  `gorm:"not null"`
+	IsLowStock bool `gorm:"not null"`
+	AlertedAt  *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SealabspayCallback struct {
+	ID            uint   `gorm:"primary_key"`
+	TxnId         string `gorm:"unique;not null"`
+	Amount        string `gorm:"not null"`
+	MerchantCode  string
+	Message       string
+	Signature     string
+	Status        string `gorm:"not null"`
+	ProcessStatus string `gorm:"not null"`
+	Attempts      int    `gorm:"not null"`
+	LastError     string
+	NextAttemptAt *time.Time
+	ProcessedAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt
+}
@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+)
+
+type StockReservation struct {
+	ID            uint   `gorm:"primaryKey"`
+	OrderCode     string `gorm:"not null"`
+	ProductId     uint   `gorm:"not null"`
+	VariantItemId uint   `gorm:"not null"`
+	Quantity      uint   `gorm:"not null"`
+	ExpiresAt     time.Time
+	ConsumedAt    *time.Time
+	ReleasedAt    *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -48,11 +48,22 @@ type TransactionCartItem struct {
 }
 
 type TransactionPaymentDetails struct {
-	Subtotal                  float64 `json:"subtotal"`
-	DeliveryFee               float64 `json:"delivery_fee"`
-	MarketplaceVoucherNominal float64 `json:"marketplace_voucher_nominal"`
-	MerchantVoucherNominal    float64 `json:"merchant_voucher_nominal"`
-	Total                     float64 `json:"total"`
+	Subtotal                          float64 `json:"subtotal"`
+	DeliveryFee                       float64 `json:"delivery_fee"`
+	MarketplaceVoucherNominal         float64 `json:"marketplace_voucher_nominal"`
+	MarketplaceShippingVoucherNominal float64 `json:"marketplace_shipping_voucher_nominal"`
+	MerchantVoucherNominal            float64 `json:"merchant_voucher_nominal"`
+	Total                             float64 `json:"total"`
+}
+
+// PaidAmount is what the buyer paid for the transaction, held in the marketplace wallet.
+func (d TransactionPaymentDetails) PaidAmount() float64 {
+	return d.Subtotal + d.DeliveryFee - d.MerchantVoucherNominal - d.MarketplaceSubsidy()
+}
+
+// MarketplaceSubsidy is the marketplace funded part, paid out of the promotion wallet.
+func (d TransactionPaymentDetails) MarketplaceSubsidy() float64 {
+	return d.MarketplaceVoucherNominal + d.MarketplaceShippingVoucherNominal
 }
 
 type TransactionPaymentMethod struct {
@@ -63,7 +74,18 @@ type TransactionPaymentMethod struct {
 }
 
 type TransactionDeliveryOption struct {
-	CourierName string `json:"courier_name"`
+	CourierName  string                    `json:"courier_name"`
+	CourierCode  string                    `json:"courier_code"`
+	Service      string                    `json:"service"`
+	BaseCost     float64                   `json:"base_cost"`
+	Cost         float64                   `json:"cost"`
+	AppliedRules []TransactionShippingRule `json:"applied_rules"`
+}
+
+type TransactionShippingRule struct {
+	Type       string  `json:"type"`
+	ProvinceId *uint   `json:"province_id"`
+	Amount     float64 `json:"amount"`
 }
 
 type TransactionAddress struct {
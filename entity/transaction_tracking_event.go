@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type TransactionTrackingEvent struct {
+	ID            uint `gorm:"primary_key"`
+	TransactionId uint
+	Code          string
+	Description   string
+	Location      string
+	OccurredAt    time.Time
+
+	CreatedAt time.Time
+}
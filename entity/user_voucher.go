@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type UserVoucher struct {
+	ID                   uint `gorm:"primaryKey"`
+	UserId               uint
+	MarketplaceVoucherId *uint
+	MarketplaceVoucher   *MarketplaceVoucher
+	MerchantVoucherId    *uint
+	MerchantVoucher      *MerchantVoucher
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
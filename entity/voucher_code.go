@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type VoucherCode struct {
+	ID                   uint `gorm:"primaryKey"`
+	MarketplaceVoucherId *uint
+	MerchantVoucherId    *uint
+	Code                 string
+	RedeemedAt           *time.Time
+	RedeemedBy           *uint
+	PaymentId            *string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VoucherRedemption records a voucher held by a checkout. A marketplace voucher is
+// redeemed once per payment, a merchant voucher once per transaction.
+type VoucherRedemption struct {
+	ID                   uint `gorm:"primaryKey"`
+	MarketplaceVoucherId *uint
+	MerchantVoucherId    *uint
+	VoucherCodeId        *uint
+	UserId               uint
+	TransactionId        *uint
+	PaymentId            string
+	ReleasedAt           *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
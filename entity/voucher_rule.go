@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VoucherRule belongs to either a marketplace or a merchant voucher. Targets holds
+// comma separated ids or payment method codes depending on the rule type.
+type VoucherRule struct {
+	ID                   uint `gorm:"primaryKey"`
+	MarketplaceVoucherId *uint
+	MerchantVoucherId    *uint
+	Type                 string
+	Value                int
+	Targets              string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
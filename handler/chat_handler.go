@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"strconv"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) StartChatConversation(c *gin.Context) {
+	var req dto.StartChatConversationReqDTO
+	err := util.ShouldBindJsonWithValidation(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.chatUsecase.StartConversation(user.Username, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_START_CHAT_CONVERSATION",
+		Message: "Success start chat conversation",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) GetChatConversationList(c *gin.Context) {
+	var req dto.ChatConversationListReqParamDTO
+	err := util.ShouldBindQueryWithValidation(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.chatUsecase.GetConversationList(user.Username, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_CHAT_CONVERSATION_LIST",
+		Message: "Success retrieve chat conversation list",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) GetUnreadChatCount(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.chatUsecase.GetUnreadChatCount(user.Username)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_UNREAD_CHAT_COUNT",
+		Message: "Success retrieve unread chat count",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) GetChatMessageList(c *gin.Context) {
+	conversationIdInt, err := strconv.Atoi(c.Param("conversation_id"))
+	if err != nil {
+		_ = c.Error(domain.ErrChatConversationIdNotValid)
+		return
+	}
+
+	var req dto.PaginationRequest
+	err = util.ShouldBindQueryWithValidation(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.chatUsecase.GetMessageList(user.Username, uint(conversationIdInt), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_CHAT_MESSAGE_LIST",
+		Message: "Success retrieve chat message list",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) SendChatMessage(c *gin.Context) {
+	conversationIdInt, err := strconv.Atoi(c.Param("conversation_id"))
+	if err != nil {
+		_ = c.Error(domain.ErrChatConversationIdNotValid)
+		return
+	}
+
+	var req dto.ChatMessageFormReqDTO
+	err = util.ShouldBindWithValidation(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.chatUsecase.SendMessage(user.Username, uint(conversationIdInt), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_SEND_CHAT_MESSAGE",
+		Message: "Success send chat message",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) MarkChatConversationRead(c *gin.Context) {
+	conversationIdInt, err := strconv.Atoi(c.Param("conversation_id"))
+	if err != nil {
+		_ = c.Error(domain.ErrChatConversationIdNotValid)
+		return
+	}
+
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.chatUsecase.MarkConversationRead(user.Username, uint(conversationIdInt))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_MARK_CHAT_CONVERSATION_READ",
+		Message: "Success mark chat conversation as read",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"strconv"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
 	"github.com/gin-gonic/gin"
@@ -87,3 +90,81 @@ func (h *Handler) ChangeMerchantUserDeliveryOption(c *gin.Context) {
 
 	util.ResponseSuccessJSON(c, response)
 }
+
+func (h *Handler) GetMerchantShippingRules(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resBody, err := h.deliveryUsecase.GetMerchantShippingRules(user.Username)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_SHIPPING_RULES",
+		Message: "Success get shipping rules",
+		Data:    resBody,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) CreateMerchantShippingRule(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var inputRequest dto.MerchantShippingRuleReqDTO
+	if err := util.ShouldBindJsonWithValidation(c, &inputRequest); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resBody, err := h.deliveryUsecase.CreateMerchantShippingRule(user.Username, inputRequest)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_CREATE_SHIPPING_RULE",
+		Message: "Success create shipping rule",
+		Data:    resBody,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) DeleteMerchantShippingRule(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	ruleId, err := strconv.Atoi(c.Param("rule_id"))
+	if err != nil || ruleId <= 0 {
+		_ = c.Error(domain.ErrMerchantShippingRuleIdNotValid)
+		return
+	}
+
+	err = h.deliveryUsecase.DeleteMerchantShippingRule(user.Username, uint(ruleId))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_DELETE_SHIPPING_RULE",
+		Message: "Success delete shipping rule",
+		Data:    nil,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"io"
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) StreamEvents(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	events, closeStream, err := h.eventStreamUsecase.Subscribe(c.Request.Context(), user.Username, c.GetString("scope"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	defer closeStream()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(dto.EVENT_STREAM_HEARTBEAT_INTERVAL)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-heartbeat.C:
+			c.SSEvent(dto.EVENT_TYPE_HEARTBEAT, time.Now())
+			return true
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Event, event.Data)
+			return true
+		}
+	})
+}
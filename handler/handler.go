@@ -34,6 +34,15 @@ type Handler struct {
 	merchantAnalyticsUsecase    usecase.MerchantAnalyticsUsecase
 	promotionBannerUsecase      usecase.PromotionBannerUsecase
 	promotionUsecase            usecase.PromotionUsecase
+	ledgerUsecase               usecase.LedgerUsecase
+	productStockAlertUsecase    usecase.ProductStockAlertUsecase
+	backInStockUsecase          usecase.BackInStockUsecase
+	notificationUsecase         usecase.NotificationUsecase
+	eventStreamUsecase          usecase.EventStreamUsecase
+	chatUsecase                 usecase.ChatUsecase
+	voucherRuleUsecase          usecase.VoucherRuleUsecase
+	voucherCodeUsecase          usecase.VoucherCodeUsecase
+	userVoucherUsecase          usecase.UserVoucherUsecase
 }
 
 type HandlerConfig struct {
@@ -67,6 +76,15 @@ type HandlerConfig struct {
 	MerchantAnalyticsUsecase         usecase.MerchantAnalyticsUsecase
 	PromotionBannerUsecase           usecase.PromotionBannerUsecase
 	PromotionUsecase                 usecase.PromotionUsecase
+	LedgerUsecase                    usecase.LedgerUsecase
+	ProductStockAlertUsecase         usecase.ProductStockAlertUsecase
+	BackInStockUsecase               usecase.BackInStockUsecase
+	NotificationUsecase              usecase.NotificationUsecase
+	EventStreamUsecase               usecase.EventStreamUsecase
+	ChatUsecase                      usecase.ChatUsecase
+	VoucherRuleUsecase               usecase.VoucherRuleUsecase
+	VoucherCodeUsecase               usecase.VoucherCodeUsecase
+	UserVoucherUsecase               usecase.UserVoucherUsecase
 }
 
 func New(c HandlerConfig) *Handler {
@@ -101,5 +119,14 @@ func New(c HandlerConfig) *Handler {
 		merchantAnalyticsUsecase:         c.MerchantAnalyticsUsecase,
 		promotionBannerUsecase:           c.PromotionBannerUsecase,
 		promotionUsecase:                 c.PromotionUsecase,
+		ledgerUsecase:                    c.LedgerUsecase,
+		productStockAlertUsecase:         c.ProductStockAlertUsecase,
+		backInStockUsecase:               c.BackInStockUsecase,
+		notificationUsecase:              c.NotificationUsecase,
+		eventStreamUsecase:               c.EventStreamUsecase,
+		chatUsecase:                      c.ChatUsecase,
+		voucherRuleUsecase:               c.VoucherRuleUsecase,
+		voucherCodeUsecase:               c.VoucherCodeUsecase,
+		userVoucherUsecase:               c.UserVoucherUsecase,
 	}
 }
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetLedgerReconciliation(c *gin.Context) {
+	res, err := h.ledgerUsecase.ReconcileBalances()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_LEDGER_RECONCILIATION",
+		Message: "Success get ledger reconciliation",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
@@ -34,6 +34,34 @@ func (h *Handler) GetMerchantDashboardMerchantResponsivenessStatistics(c *gin.Co
 	util.ResponseSuccessJSON(c, response)
 }
 
+func (h *Handler) GetMerchantDashboardResponsivenessPercentiles(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var reqBody dto.MerchantAnalyticsResponsivenessPercentileReqBody
+	if err := util.ShouldBindQueryWithValidation(c, &reqBody); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resBody, err := h.merchantAnalyticsUsecase.GetMerchantDashboardResponsivenessPercentiles(user.Username, reqBody)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_MERCHANT_RESPONSIVENESS_PERCENTILES",
+		Message: "Success get merchant responsiveness percentiles",
+		Data:    resBody,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
 func (h *Handler) GetMerchantDashboardSalesStatistics(c *gin.Context) {
 	user, err := util.GetUserJWTContext(c)
 	if err != nil {
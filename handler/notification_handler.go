@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"strconv"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetNotificationList(c *gin.Context) {
+	var req dto.NotificationListReqParamDTO
+	err := util.ShouldBindQueryWithValidation(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.notificationUsecase.GetNotificationList(user.Username, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_NOTIFICATION_LIST",
+		Message: "Success retrieve notification list",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) GetUnreadNotificationCount(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.notificationUsecase.GetUnreadNotificationCount(user.Username)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_UNREAD_NOTIFICATION_COUNT",
+		Message: "Success retrieve unread notification count",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) MarkNotificationRead(c *gin.Context) {
+	notificationIdInt, err := strconv.Atoi(c.Param("notification_id"))
+	if err != nil {
+		_ = c.Error(domain.ErrNotificationIdNotValid)
+		return
+	}
+
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.notificationUsecase.MarkNotificationRead(user.Username, uint(notificationIdInt))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_MARK_NOTIFICATION_READ",
+		Message: "Success mark notification as read",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) MarkAllNotificationsRead(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.notificationUsecase.MarkAllNotificationsRead(user.Username)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_MARK_ALL_NOTIFICATIONS_READ",
+		Message: "Success mark all notifications as read",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
@@ -37,6 +37,63 @@ func (h *Handler) GetProductList(c *gin.Context) {
 	util.ResponseSuccessJSON(c, response)
 }
 
+func (h *Handler) GetProductSuggestions(c *gin.Context) {
+	var suggestionRequest dto.ProductSuggestionReqDTO
+	err := util.ShouldBindQueryWithValidation(c, &suggestionRequest)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.productUsecase.GetProductSuggestions(suggestionRequest)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_PRODUCT_SUGGESTIONS",
+		Message: "Success retrieve product suggestions",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) GetBoughtTogetherProducts(c *gin.Context) {
+	h.getRelatedProducts(c, dto.PRODUCT_RELATION_TYPE_BOUGHT_TOGETHER, "SUCCESS_GET_BOUGHT_TOGETHER_PRODUCTS", "Success retrieve frequently bought together products")
+}
+
+func (h *Handler) GetSimilarProducts(c *gin.Context) {
+	h.getRelatedProducts(c, dto.PRODUCT_RELATION_TYPE_SIMILAR, "SUCCESS_GET_SIMILAR_PRODUCTS", "Success retrieve similar products")
+}
+
+func (h *Handler) getRelatedProducts(c *gin.Context, relationType string, code string, message string) {
+	domain := c.Param("domain")
+	slug := c.Param("slug")
+
+	var req dto.RelatedProductReqDTO
+	err := util.ShouldBindQueryWithValidation(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.productUsecase.GetRelatedProducts(fmt.Sprintf("%s/%s", domain, slug), relationType, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    code,
+		Message: message,
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
 func (h *Handler) GetMerchantProductList(c *gin.Context) {
 	var productRequest dto.ProductListReqParamDTO
 	err := util.ShouldBindQueryWithValidation(c, &productRequest)
@@ -74,7 +131,12 @@ func (h *Handler) GetRecommendationProductList(c *gin.Context) {
 		return
 	}
 
-	res, err := h.productUsecase.GetRecommendationProductList(paginationRequest)
+	userJwt, err := util.GetUserJWTContext(c)
+	if err != nil {
+		userJwt = nil
+	}
+
+	res, err := h.productUsecase.GetRecommendationProductList(userJwt, paginationRequest)
 	if err != nil {
 		_ = c.Error(err)
 		return
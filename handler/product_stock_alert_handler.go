@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"strconv"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) UpdateLowStockThreshold(c *gin.Context) {
+	productIdInt, err := strconv.Atoi(c.Param("product_id"))
+	if err != nil {
+		_ = c.Error(domain.ErrProductIdNotValid)
+		return
+	}
+
+	var req dto.UpdateLowStockThresholdReqDTO
+	err = util.ShouldBindJsonWithValidation(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.productStockAlertUsecase.UpdateLowStockThreshold(user.Username, uint(productIdInt), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_UPDATE_LOW_STOCK_THRESHOLD",
+		Message: "Success update low stock threshold",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) GetLowStockProductList(c *gin.Context) {
+	var req dto.PaginationRequest
+	err := util.ShouldBindQueryWithValidation(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.productStockAlertUsecase.GetLowStockProductList(user.Username, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_LOW_STOCK_PRODUCT_LIST",
+		Message: "Success retrieve low stock product list",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
@@ -50,3 +50,33 @@ func (h *Handler) GetMerchantProductVariants(c *gin.Context) {
 
 	util.ResponseSuccessJSON(c, response)
 }
+
+func (h *Handler) SubscribeBackInStock(c *gin.Context) {
+	domainParam := c.Param("domain")
+	slug := c.Param("slug")
+	variantItemId, err := strconv.Atoi(c.Param("variant_id"))
+	if err != nil {
+		_ = c.Error(domain.ErrBackInStockVariantIdNotValid)
+		return
+	}
+
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.backInStockUsecase.Subscribe(user.Username, fmt.Sprintf("%s/%s", domainParam, slug), uint(variantItemId))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_SUBSCRIBE_BACK_IN_STOCK",
+		Message: "Success subscribe back in stock notification",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
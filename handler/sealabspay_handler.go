@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"strconv"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
 	"github.com/gin-gonic/gin"
@@ -28,3 +31,48 @@ func (h *Handler) SealabspayResponseHandler(c *gin.Context) {
 
 	util.ResponseSuccessJSON(c, response)
 }
+
+func (h *Handler) GetSealabspayCallbackList(c *gin.Context) {
+	var reqParam dto.SealabspayCallbackListReqParamDTO
+	if err := util.ShouldBindQueryWithValidation(c, &reqParam); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.sealabspayUsecase.GetCallbackList(reqParam)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_SEALABSPAY_CALLBACK_LIST",
+		Message: "Success get sealabspay callback list",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) ReplaySealabspayCallback(c *gin.Context) {
+	idStr := c.Param("callback_id")
+	callbackId, err := strconv.Atoi(idStr)
+	if err != nil || callbackId <= 0 {
+		_ = c.Error(domain.ErrInvalidSealabspayCallbackId)
+		return
+	}
+
+	res, err := h.sealabspayUsecase.ReplayCallback(uint(callbackId))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_REPLAY_SEALABSPAY_CALLBACK",
+		Message: "Success replay sealabspay callback",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
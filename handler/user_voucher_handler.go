@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"strconv"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetUserVouchers(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.userVoucherUsecase.GetUserVouchers(user.Username)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_USER_VOUCHERS",
+		Message: "Success get user vouchers",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) ClaimVoucher(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var req dto.ClaimVoucherReqDTO
+	if err := util.ShouldBindJsonWithValidation(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.userVoucherUsecase.ClaimVoucher(user.Username, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_CLAIM_VOUCHER",
+		Message: "Success claim voucher",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) DeleteUserVoucher(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userVoucherId, err := strconv.Atoi(c.Param("user_voucher_id"))
+	if err != nil {
+		_ = c.Error(domain.ErrInvalidUserVoucherID)
+		return
+	}
+
+	err = h.userVoucherUsecase.DeleteUserVoucher(user.Username, uint(userVoucherId))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_DELETE_USER_VOUCHER",
+		Message: "Success delete user voucher",
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GenerateMarketplaceVoucherCodes(c *gin.Context) {
+	var req dto.GenerateVoucherCodesReqDTO
+	if err := util.ShouldBindJsonWithValidation(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.voucherCodeUsecase.GenerateMarketplaceVoucherCodes(c.Param("voucher_code"), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GENERATE_MARKETPLACE_VOUCHER_CODES",
+		Message: "Success generate marketplace voucher codes",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) GetMarketplaceVoucherCodes(c *gin.Context) {
+	var req dto.VoucherCodeListParamReqDTO
+	if err := util.ShouldBindQueryWithValidation(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.voucherCodeUsecase.GetMarketplaceVoucherCodes(c.Param("voucher_code"), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_MARKETPLACE_VOUCHER_CODES",
+		Message: "Success get marketplace voucher codes",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) ExportMarketplaceVoucherCodes(c *gin.Context) {
+	voucherCode := c.Param("voucher_code")
+	res, err := h.voucherCodeUsecase.ExportMarketplaceVoucherCodes(voucherCode)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-codes.csv", voucherCode))
+	c.Data(http.StatusOK, "text/csv", res)
+}
+
+func (h *Handler) GenerateMerchantVoucherCodes(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var req dto.GenerateVoucherCodesReqDTO
+	if err := util.ShouldBindJsonWithValidation(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.voucherCodeUsecase.GenerateMerchantVoucherCodes(user.Username, c.Param("voucher_code"), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GENERATE_MERCHANT_VOUCHER_CODES",
+		Message: "Success generate merchant voucher codes",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) GetMerchantVoucherCodes(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var req dto.VoucherCodeListParamReqDTO
+	if err := util.ShouldBindQueryWithValidation(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.voucherCodeUsecase.GetMerchantVoucherCodes(user.Username, c.Param("voucher_code"), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_MERCHANT_VOUCHER_CODES",
+		Message: "Success get merchant voucher codes",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) ExportMerchantVoucherCodes(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	voucherCode := c.Param("voucher_code")
+	res, err := h.voucherCodeUsecase.ExportMerchantVoucherCodes(user.Username, voucherCode)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-codes.csv", voucherCode))
+	c.Data(http.StatusOK, "text/csv", res)
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetMarketplaceVoucherRules(c *gin.Context) {
+	res, err := h.voucherRuleUsecase.GetMarketplaceVoucherRules(c.Param("voucher_code"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_MARKETPLACE_VOUCHER_RULES",
+		Message: "Success get marketplace voucher rules",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) UpdateMarketplaceVoucherRules(c *gin.Context) {
+	var req dto.UpdateVoucherRulesReqDTO
+	if err := util.ShouldBindJsonWithValidation(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.voucherRuleUsecase.UpdateMarketplaceVoucherRules(c.Param("voucher_code"), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_UPDATE_MARKETPLACE_VOUCHER_RULES",
+		Message: "Success update marketplace voucher rules",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) GetMerchantVoucherRules(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.voucherRuleUsecase.GetMerchantVoucherRules(user.Username, c.Param("voucher_code"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_GET_MERCHANT_VOUCHER_RULES",
+		Message: "Success get merchant voucher rules",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
+
+func (h *Handler) UpdateMerchantVoucherRules(c *gin.Context) {
+	user, err := util.GetUserJWTContext(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var req dto.UpdateVoucherRulesReqDTO
+	if err := util.ShouldBindJsonWithValidation(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.voucherRuleUsecase.UpdateMerchantVoucherRules(user.Username, c.Param("voucher_code"), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := util.ResponseStruct{
+		Code:    "SUCCESS_UPDATE_MERCHANT_VOUCHER_RULES",
+		Message: "Success update merchant voucher rules",
+		Data:    res,
+	}
+
+	util.ResponseSuccessJSON(c, response)
+}
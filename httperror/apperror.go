@@ -89,3 +89,15 @@ func TimeoutError() AppError {
 		Data:       nil,
 	}
 }
+
+func ConflictError(message string, code string) AppError {
+	if code == "" {
+		code = "CONFLICT_ERROR"
+	}
+	return AppError{
+		Code:       code,
+		Message:    message,
+		StatusCode: http.StatusConflict,
+		Data:       nil,
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cache"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/config"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cronjob"
@@ -22,6 +24,11 @@ func main() {
 		log.Fatal().Msg("error connecting to RDB")
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "reconcile-ledger" {
+		server.ReconcileLedger()
+		return
+	}
+
 	cronjob.Init()
 	server.Init()
 
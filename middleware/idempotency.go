@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cache"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/config"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the stored response when a request is retried with the
+// same Idempotency-Key header. Requests without the header are passed through.
+func Idempotency(rdb *cache.RDBConnection) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(dto.IDEMPOTENCY_KEY_HEADER)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > dto.IDEMPOTENCY_KEY_MAX_LENGTH {
+			util.AbortWithError(c, domain.ErrIdempotencyKeyTooLong)
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			util.AbortWithError(c, domain.ErrIdempotencyReadBody)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+		username := ""
+		userJWT, err := util.GetUserJWTContext(c)
+		if err == nil {
+			username = userJWT.Username
+		}
+
+		hash := sha256.Sum256(append([]byte(c.Request.Method+" "+c.FullPath()+"\n"), body...))
+		fingerprint := hex.EncodeToString(hash[:])
+		cacheKey := fmt.Sprintf("idempotency:%s:%s:%s", username, c.FullPath(), key)
+		expires := config.Config.RDBConfig.IdempotencyExpires
+
+		var stored dto.IdempotencyRecordDTO
+		err = rdb.GetCache(cacheKey, &stored)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			log.Error().Msgf("error: get idempotency key %s: %v", cacheKey, err)
+			util.AbortWithError(c, domain.ErrIdempotencyStore)
+			return
+		}
+		if err == nil {
+			replayIdempotentResponse(c, stored, fingerprint)
+			return
+		}
+
+		isLocked, err := rdb.SetCacheNX(cacheKey, dto.IdempotencyRecordDTO{Fingerprint: fingerprint}, expires)
+		if err != nil {
+			log.Error().Msgf("error: lock idempotency key %s: %v", cacheKey, err)
+			util.AbortWithError(c, domain.ErrIdempotencyStore)
+			return
+		}
+		if !isLocked {
+			util.AbortWithError(c, domain.ErrIdempotencyKeyInProgress)
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+
+		// failed requests are rolled back, release the key so the client can retry
+		if len(c.Errors) != 0 || writer.Status() >= 500 {
+			err = rdb.DeleteCache(cacheKey)
+			if err != nil {
+				log.Error().Msgf("error: release idempotency key %s: %v", cacheKey, err)
+			}
+			return
+		}
+
+		err = rdb.SetCache(cacheKey, dto.IdempotencyRecordDTO{
+			Fingerprint: fingerprint,
+			StatusCode:  writer.Status(),
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		}, expires)
+		if err != nil {
+			log.Error().Msgf("error: store idempotency response %s: %v", cacheKey, err)
+		}
+	}
+}
+
+func replayIdempotentResponse(c *gin.Context, stored dto.IdempotencyRecordDTO, fingerprint string) {
+	if stored.Fingerprint != fingerprint {
+		util.AbortWithError(c, domain.ErrIdempotencyKeyReused)
+		return
+	}
+	if stored.StatusCode == 0 {
+		util.AbortWithError(c, domain.ErrIdempotencyKeyInProgress)
+		return
+	}
+
+	c.Header(dto.IDEMPOTENCY_REPLAYED_HEADER, "true")
+	c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+	c.Abort()
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BackInStockSubscriptionRepository interface {
+	Create(subscription entity.BackInStockSubscription) (*entity.BackInStockSubscription, error)
+	TriggerRestockedTx(tx *gorm.DB, productId uint) error
+	ClaimTriggered(limit int) ([]dto.BackInStockNotificationDTO, error)
+}
+
+type BackInStockSubscriptionRepositoryConfig struct {
+	DB                     *gorm.DB
+	NotificationRepository NotificationRepository
+}
+
+type backInStockSubscriptionRepositoryImpl struct {
+	db                     *gorm.DB
+	notificationRepository NotificationRepository
+}
+
+func NewBackInStockSubscriptionRepository(c BackInStockSubscriptionRepositoryConfig) BackInStockSubscriptionRepository {
+	return &backInStockSubscriptionRepositoryImpl{
+		db:                     c.DB,
+		notificationRepository: c.NotificationRepository,
+	}
+}
+
+// Create returns the pending subscription of the user when one already exists.
+func (r *backInStockSubscriptionRepositoryImpl) Create(subscription entity.BackInStockSubscription) (*entity.BackInStockSubscription, error) {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "user_id"}, {Name: "product_id"}, {Name: "variant_name"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "notified_at IS NULL"}}},
+		DoNothing:   true,
+	}).Create(&subscription).Error
+	if err != nil {
+		return nil, domain.ErrCreateBackInStockSubscription
+	}
+
+	var pending entity.BackInStockSubscription
+	err = r.db.
+		Where("user_id = ?", subscription.UserId).
+		Where("product_id = ?", subscription.ProductId).
+		Where("variant_name = ?", subscription.VariantName).
+		Where("notified_at IS NULL").
+		First(&pending).Error
+	if err != nil {
+		return nil, domain.ErrCreateBackInStockSubscription
+	}
+
+	return &pending, nil
+}
+
+// TriggerRestockedTx marks pending subscriptions of the product whose variant is
+// in stock again. Variants are matched by name since a product update recreates
+// its variant items.
+func (r *backInStockSubscriptionRepositoryImpl) TriggerRestockedTx(tx *gorm.DB, productId uint) error {
+	err := tx.Exec(`
+		UPDATE back_in_stock_subscriptions s
+		SET variant_item_id = v.id, triggered_at = now(), updated_at = now()
+		FROM (
+			SELECT vi.id, vi.product_id, COALESCE(string_agg(vs.variation_name, ',' ORDER BY vs.id), '') AS variant_name
+			FROM variant_items vi
+			LEFT JOIN variant_specs vs ON vs.variant_item_id = vi.id AND vs.deleted_at IS NULL
+			WHERE vi.product_id = ? AND vi.deleted_at IS NULL AND vi.stock > 0
+			GROUP BY vi.id, vi.product_id
+		) v
+		WHERE s.product_id = v.product_id
+		AND s.variant_name = v.variant_name
+		AND s.triggered_at IS NULL`, productId).Error
+	if err != nil {
+		log.Error().Msgf("Error trigger back in stock subscription: %v", err)
+		return domain.ErrTriggerBackInStockSubscription
+	}
+
+	return nil
+}
+
+// ClaimTriggered marks a batch of triggered subscriptions as notified and adds
+// their feed notification in the same transaction, so each subscriber is
+// notified exactly once even with several instances running the cron.
+func (r *backInStockSubscriptionRepositoryImpl) ClaimTriggered(limit int) (claimed []dto.BackInStockNotificationDTO, errClaim error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			log.Error().Msgf("Recovered in ClaimTriggered repo: %v", r)
+			errClaim = domain.ErrNotifyBackInStockSubscription
+		}
+	}()
+
+	var subscriptions []dto.BackInStockNotificationDTO
+	err := tx.Raw(`
+		SELECT s.id AS subscription_id, s.user_id, u.email, p.title AS product_title, p.slug AS product_slug, s.variant_name
+		FROM back_in_stock_subscriptions s
+		JOIN users u ON u.id = s.user_id
+		JOIN products p ON p.id = s.product_id
+		WHERE s.triggered_at IS NOT NULL AND s.notified_at IS NULL
+		ORDER BY s.triggered_at ASC
+		LIMIT ?
+		FOR UPDATE OF s SKIP LOCKED`, limit).
+		Scan(&subscriptions).Error
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("Error get triggered back in stock subscription: %v", err)
+		return nil, domain.ErrNotifyBackInStockSubscription
+	}
+
+	if len(subscriptions) == 0 {
+		tx.Rollback()
+		return subscriptions, nil
+	}
+
+	subscriptionIds := make([]uint, len(subscriptions))
+	notifications := make([]entity.Notification, len(subscriptions))
+	for i, subscription := range subscriptions {
+		productName := subscription.ProductTitle
+		if subscription.VariantName != "" {
+			productName = fmt.Sprintf("%s (%s)", subscription.ProductTitle, subscription.VariantName)
+		}
+
+		subscriptionIds[i] = subscription.SubscriptionId
+		notifications[i] = entity.Notification{
+			UserId:  subscription.UserId,
+			Type:    dto.NOTIFICATION_TYPE_BACK_IN_STOCK,
+			Title:   "Back in stock",
+			Message: fmt.Sprintf("%s is back in stock", productName),
+			Link:    "/" + subscription.ProductSlug,
+		}
+	}
+
+	err = tx.Model(&entity.BackInStockSubscription{}).
+		Where("id IN ?", subscriptionIds).
+		Update("notified_at", time.Now()).Error
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("Error update back in stock subscription: %v", err)
+		return nil, domain.ErrNotifyBackInStockSubscription
+	}
+
+	err = r.notificationRepository.CreateTx(tx, notifications)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return nil, domain.ErrNotifyBackInStockSubscription
+	}
+
+	return subscriptions, nil
+}
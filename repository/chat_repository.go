@@ -0,0 +1,338 @@
+package repository
+
+import (
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChatRepository interface {
+	GetOrCreateConversation(conversation entity.ChatConversation, merchantUserId uint) (*entity.ChatConversation, error)
+	GetConversationByUserId(conversationId uint, userId uint) (*entity.ChatConversation, *entity.ChatParticipant, error)
+	GetConversationList(userId uint, req dto.ChatConversationListReqParamDTO) ([]entity.ChatConversation, int64, error)
+	GetLastMessages(conversationIds []uint) (map[uint]entity.ChatMessage, error)
+	GetUnreadCounts(userId uint, conversationIds []uint) (map[uint]int64, error)
+	CountUnread(userId uint) (int64, error)
+
+	CreateMessage(message entity.ChatMessage) (*entity.ChatMessage, error)
+	GetMessages(conversationId uint, req dto.PaginationRequest) ([]entity.ChatMessage, int64, error)
+	MarkRead(conversationId uint, userId uint) error
+}
+
+type ChatRepositoryConfig struct {
+	DB *gorm.DB
+}
+
+type chatRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewChatRepository(c ChatRepositoryConfig) ChatRepository {
+	return &chatRepositoryImpl{
+		db: c.DB,
+	}
+}
+
+func (r *chatRepositoryImpl) conversationSubjectQuery(tx *gorm.DB, conversation entity.ChatConversation) *gorm.DB {
+	query := tx.Where("buyer_id = ?", conversation.BuyerId).Where("merchant_domain = ?", conversation.MerchantDomain)
+	if conversation.ProductId != nil {
+		query = query.Where("product_id = ?", *conversation.ProductId)
+	} else {
+		query = query.Where("product_id IS NULL")
+	}
+	if conversation.TransactionId != nil {
+		query = query.Where("transaction_id = ?", *conversation.TransactionId)
+	} else {
+		query = query.Where("transaction_id IS NULL")
+	}
+
+	return query
+}
+
+// GetOrCreateConversation returns the conversation of the buyer, merchant and
+// subject, creating it with both participants when it does not exist yet.
+func (r *chatRepositoryImpl) GetOrCreateConversation(conversation entity.ChatConversation, merchantUserId uint) (createdConversation *entity.ChatConversation, errCreate error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			log.Error().Msgf("Recovered in GetOrCreateConversation repo: %v", r)
+			errCreate = domain.ErrCreateChatConversation
+		}
+	}()
+
+	var existingConversations []entity.ChatConversation
+	err := r.conversationSubjectQuery(tx, conversation).Limit(1).Find(&existingConversations).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, domain.ErrGetChatConversation
+	}
+	if len(existingConversations) > 0 {
+		tx.Rollback()
+		return &existingConversations[0], nil
+	}
+
+	err = tx.Create(&conversation).Error
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("error creating chat conversation: %v", err)
+
+		// a concurrent request may have created the same conversation
+		err = r.conversationSubjectQuery(r.db, conversation).First(&conversation).Error
+		if err != nil {
+			return nil, domain.ErrCreateChatConversation
+		}
+		return &conversation, nil
+	}
+
+	participants := []entity.ChatParticipant{
+		{ConversationId: conversation.ID, UserId: conversation.BuyerId, Role: dto.CHAT_ROLE_BUYER},
+		{ConversationId: conversation.ID, UserId: merchantUserId, Role: dto.CHAT_ROLE_MERCHANT},
+	}
+	err = tx.Create(&participants).Error
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("error creating chat participants: %v", err)
+		return nil, domain.ErrCreateChatConversation
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return nil, domain.ErrCreateChatConversation
+	}
+
+	return &conversation, nil
+}
+
+func (r *chatRepositoryImpl) GetConversationByUserId(conversationId uint, userId uint) (*entity.ChatConversation, *entity.ChatParticipant, error) {
+	var conversation entity.ChatConversation
+	err := r.db.
+		Preload("Participants").
+		Where("id = ?", conversationId).
+		First(&conversation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, domain.ErrChatConversationNotFound
+		}
+		return nil, nil, domain.ErrGetChatConversation
+	}
+
+	for i := range conversation.Participants {
+		if conversation.Participants[i].UserId == userId {
+			return &conversation, &conversation.Participants[i], nil
+		}
+	}
+
+	return nil, nil, domain.ErrChatConversationNotFound
+}
+
+func (r *chatRepositoryImpl) GetConversationList(userId uint, req dto.ChatConversationListReqParamDTO) ([]entity.ChatConversation, int64, error) {
+	var conversations []entity.ChatConversation
+	var totalData int64
+
+	query := r.db.Model(&entity.ChatConversation{}).
+		Joins("JOIN chat_participants cp ON cp.conversation_id = chat_conversations.id").
+		Where("cp.user_id = ?", userId)
+	if req.Role != "" {
+		query = query.Where("cp.role = ?", req.Role)
+	}
+	query = query.Session(&gorm.Session{})
+
+	err := query.Count(&totalData).Error
+	if err != nil {
+		return nil, 0, domain.ErrGetChatConversation
+	}
+
+	err = query.
+		Select("chat_conversations.*").
+		Preload("Merchant").
+		Preload("Buyer.UserDetail").
+		Preload("Product").
+		Preload("Transaction").
+		Preload("Participants").
+		Order("chat_conversations.last_message_at DESC NULLS LAST, chat_conversations.id DESC").
+		Limit(req.Limit).
+		Offset((req.Page - 1) * req.Limit).
+		Find(&conversations).Error
+	if err != nil {
+		return nil, 0, domain.ErrGetChatConversation
+	}
+
+	return conversations, totalData, nil
+}
+
+func (r *chatRepositoryImpl) GetLastMessages(conversationIds []uint) (map[uint]entity.ChatMessage, error) {
+	lastMessages := make(map[uint]entity.ChatMessage)
+	if len(conversationIds) == 0 {
+		return lastMessages, nil
+	}
+
+	var messages []entity.ChatMessage
+	err := r.db.
+		Raw(`SELECT DISTINCT ON (conversation_id) * FROM chat_messages
+			WHERE conversation_id IN ? AND deleted_at IS NULL
+			ORDER BY conversation_id, id DESC`, conversationIds).
+		Scan(&messages).Error
+	if err != nil {
+		return nil, domain.ErrGetChatMessage
+	}
+
+	for _, message := range messages {
+		lastMessages[message.ConversationId] = message
+	}
+
+	return lastMessages, nil
+}
+
+func (r *chatRepositoryImpl) unreadQuery(userId uint) *gorm.DB {
+	return r.db.Table("chat_messages cm").
+		Joins("JOIN chat_participants cp ON cp.conversation_id = cm.conversation_id").
+		Where("cp.user_id = ?", userId).
+		Where("cm.sender_id <> ?", userId).
+		Where("cm.id > cp.last_read_message_id").
+		Where("cm.deleted_at IS NULL")
+}
+
+func (r *chatRepositoryImpl) GetUnreadCounts(userId uint, conversationIds []uint) (map[uint]int64, error) {
+	unreadCounts := make(map[uint]int64)
+	if len(conversationIds) == 0 {
+		return unreadCounts, nil
+	}
+
+	var rows []struct {
+		ConversationId uint
+		UnreadCount    int64
+	}
+	err := r.unreadQuery(userId).
+		Select("cm.conversation_id, count(cm.id) as unread_count").
+		Where("cm.conversation_id IN ?", conversationIds).
+		Group("cm.conversation_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, domain.ErrGetChatMessage
+	}
+
+	for _, row := range rows {
+		unreadCounts[row.ConversationId] = row.UnreadCount
+	}
+
+	return unreadCounts, nil
+}
+
+func (r *chatRepositoryImpl) CountUnread(userId uint) (int64, error) {
+	var unreadCount int64
+	err := r.unreadQuery(userId).Count(&unreadCount).Error
+	if err != nil {
+		return 0, domain.ErrGetChatMessage
+	}
+
+	return unreadCount, nil
+}
+
+// CreateMessage stores the message and keeps the waiting time of the buyer,
+// the first merchant reply after the buyer started waiting records its response time.
+func (r *chatRepositoryImpl) CreateMessage(message entity.ChatMessage) (createdMessage *entity.ChatMessage, errCreate error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			log.Error().Msgf("Recovered in CreateMessage repo: %v", r)
+			errCreate = domain.ErrCreateChatMessage
+		}
+	}()
+
+	var conversation entity.ChatConversation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", message.ConversationId).
+		First(&conversation).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, domain.ErrGetChatConversation
+	}
+
+	timeNow := time.Now()
+	conversationUpdate := map[string]interface{}{"last_message_at": timeNow}
+	if message.SenderRole == dto.CHAT_ROLE_BUYER && conversation.BuyerWaitingSince == nil {
+		conversationUpdate["buyer_waiting_since"] = timeNow
+	}
+	if message.SenderRole == dto.CHAT_ROLE_MERCHANT && conversation.BuyerWaitingSince != nil {
+		responseSeconds := timeNow.Sub(*conversation.BuyerWaitingSince).Seconds()
+		message.ResponseSeconds = &responseSeconds
+		conversationUpdate["buyer_waiting_since"] = nil
+	}
+
+	message.CreatedAt = timeNow
+	err = tx.Create(&message).Error
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("error creating chat message: %v", err)
+		return nil, domain.ErrCreateChatMessage
+	}
+
+	err = tx.Model(&conversation).Updates(conversationUpdate).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, domain.ErrCreateChatMessage
+	}
+
+	err = tx.Model(&entity.ChatParticipant{}).
+		Where("conversation_id = ?", message.ConversationId).
+		Where("user_id = ?", message.SenderId).
+		Update("last_read_message_id", message.ID).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, domain.ErrCreateChatMessage
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return nil, domain.ErrCreateChatMessage
+	}
+
+	return &message, nil
+}
+
+func (r *chatRepositoryImpl) GetMessages(conversationId uint, req dto.PaginationRequest) ([]entity.ChatMessage, int64, error) {
+	var messages []entity.ChatMessage
+	var totalData int64
+
+	query := r.db.Model(&entity.ChatMessage{}).
+		Where("conversation_id = ?", conversationId).
+		Session(&gorm.Session{})
+
+	err := query.Count(&totalData).Error
+	if err != nil {
+		return nil, 0, domain.ErrGetChatMessage
+	}
+
+	err = query.
+		Order("id DESC").
+		Limit(req.Limit).
+		Offset((req.Page - 1) * req.Limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, 0, domain.ErrGetChatMessage
+	}
+
+	return messages, totalData, nil
+}
+
+func (r *chatRepositoryImpl) MarkRead(conversationId uint, userId uint) error {
+	err := r.db.Model(&entity.ChatParticipant{}).
+		Where("conversation_id = ?", conversationId).
+		Where("user_id = ?", userId).
+		Update("last_read_message_id", gorm.Expr(
+			"GREATEST(last_read_message_id, (SELECT COALESCE(MAX(id), 0) FROM chat_messages WHERE conversation_id = ?))",
+			conversationId,
+		)).Error
+	if err != nil {
+		return domain.ErrUpdateChatParticipant
+	}
+
+	return nil
+}
@@ -3,13 +3,16 @@ package repository
 import (
 	"bytes"
 	"encoding/json"
-	"io"
+	"fmt"
 	"net/http"
 
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cache"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/config"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 )
 
@@ -22,19 +25,36 @@ type DeliveryRepository interface {
 	AddMerchantDeliveryOptions(input []entity.MerchantDeliveryOption) ([]entity.MerchantDeliveryOption, error)
 	AddMerchantDeliveryOptionsTx(tx *gorm.DB, input []entity.MerchantDeliveryOption) ([]entity.MerchantDeliveryOption, error)
 	DeleteMerchantDeliveryOptions(merchantId uint, input []uint) error
+
+	GetMerchantShippingRules(merchantId uint, courierCode string) ([]entity.MerchantShippingRule, error)
+	CreateMerchantShippingRule(rule entity.MerchantShippingRule) (*entity.MerchantShippingRule, error)
+	DeleteMerchantShippingRule(merchantId uint, ruleId uint) error
 }
 
+// DeliveryRepositoryConfig allows replacing the HTTP client, e.g. to point
+// the cost lookup at a local stub server. Rates are cached when RDB is set.
 type DeliveryRepositoryConfig struct {
-	DB *gorm.DB
+	DB         *gorm.DB
+	RDB        *cache.RDBConnection
+	HttpClient *http.Client
 }
 
 type deliveryRepositoryImpl struct {
-	db *gorm.DB
+	db         *gorm.DB
+	rdb        *cache.RDBConnection
+	httpClient *http.Client
 }
 
 func NewDeliveryRepository(c DeliveryRepositoryConfig) DeliveryRepository {
+	httpClient := c.HttpClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: dto.DELIVERY_RATE_REQUEST_TIMEOUT}
+	}
+
 	return &deliveryRepositoryImpl{
-		db: c.DB,
+		db:         c.DB,
+		rdb:        c.RDB,
+		httpClient: httpClient,
 	}
 }
 
@@ -80,6 +100,7 @@ func (r *deliveryRepositoryImpl) GetDeliveryOptionsByMerchantID(merchantId uint)
 func (r *deliveryRepositoryImpl) GetMerchantDeliveryOptionsByMerchantID(merchantId uint) ([]entity.MerchantDeliveryOption, error) {
 	var res []entity.MerchantDeliveryOption
 	err := r.db.Preload("DeliveryOption").
+		Preload("ShippingRules").
 		Where("merchant_id = ?", merchantId).
 		Find(&res).
 		Error
@@ -91,36 +112,134 @@ func (r *deliveryRepositoryImpl) GetMerchantDeliveryOptionsByMerchantID(merchant
 	return res, nil
 }
 
+// GetDeliveryInfo prices the weight rounded up to the courier's kilogram
+// bucket, so every lookup within the same bucket shares one cache entry.
+// The offline rate table is used when RajaOngkir cannot be reached.
 func (r *deliveryRepositoryImpl) GetDeliveryInfo(input dto.RajaOngkirDeliveryInfoReqDTO) (*dto.RajaOngkirDeliveryInfoResDTO, error) {
+	input.Weight = ((input.Weight + dto.DELIVERY_RATE_WEIGHT_BUCKET - 1) / dto.DELIVERY_RATE_WEIGHT_BUCKET) * dto.DELIVERY_RATE_WEIGHT_BUCKET
+	if input.Weight < dto.DELIVERY_RATE_WEIGHT_BUCKET {
+		input.Weight = dto.DELIVERY_RATE_WEIGHT_BUCKET
+	}
+	cacheKey := fmt.Sprintf("delivery-rate:%d:%d:%d:%s", input.Origin, input.Destination, input.Weight, input.Courier)
+
+	var output dto.RajaOngkirDeliveryInfoResDTO
+	if r.rdb != nil && r.rdb.GetCache(cacheKey, &output) == nil {
+		return &output, nil
+	}
+
+	deliveryInfo, isReachable, err := r.getRajaOngkirDeliveryInfo(input)
+	if err == nil {
+		if r.rdb != nil {
+			if err := r.rdb.SetCache(cacheKey, deliveryInfo, dto.DELIVERY_RATE_CACHE_DURATION_MINUTES); err != nil {
+				log.Error().Msgf("cannot cache delivery rate %s: %v", cacheKey, err)
+			}
+		}
+		return deliveryInfo, nil
+	}
+	if isReachable {
+		return nil, err
+	}
+
+	log.Error().Msgf("rajaongkir unreachable, using delivery rate table for %s: %v", cacheKey, err)
+	return r.getRateTableDeliveryInfo(input)
+}
+
+func (r *deliveryRepositoryImpl) getRajaOngkirDeliveryInfo(input dto.RajaOngkirDeliveryInfoReqDTO) (output *dto.RajaOngkirDeliveryInfoResDTO, isReachable bool, err error) {
 	c := config.Config.RajaOngkirConfig
 	reqBody, err := json.Marshal(input)
 	if err != nil {
-		return nil, domain.ErrGetDeliveryFeeInternalError
+		return nil, true, domain.ErrGetDeliveryFeeInternalError
 	}
 	req, err := http.NewRequest("POST", c.Url+"/cost", bytes.NewBuffer(reqBody))
 	if err != nil {
-		return nil, domain.ErrGetDeliveryFeeInternalError
+		return nil, true, domain.ErrGetDeliveryFeeInternalError
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("key", c.ApiKey)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := r.httpClient.Do(req)
 	if err != nil {
-		return nil, domain.ErrGetDeliveryFeeInternalError
+		return nil, false, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, false, fmt.Errorf("rajaongkir responded with status %d", resp.StatusCode)
+	}
+
+	var deliveryInfo dto.RajaOngkirDeliveryInfoResDTO
+	if err := json.NewDecoder(resp.Body).Decode(&deliveryInfo); err != nil {
+		return nil, false, err
+	}
+	if deliveryInfo.Rajaongkir.Status.Code != http.StatusOK || len(deliveryInfo.Rajaongkir.Results) == 0 {
+		return nil, true, domain.ErrGetDeliveryFeeInternalError
+	}
+
+	return &deliveryInfo, true, nil
+}
+
+func (r *deliveryRepositoryImpl) getRateTableDeliveryInfo(input dto.RajaOngkirDeliveryInfoReqDTO) (*dto.RajaOngkirDeliveryInfoResDTO, error) {
+	var cities []entity.City
+	err := r.db.Where("ro_id IN ?", []int{input.Origin, input.Destination}).Find(&cities).Error
 	if err != nil {
 		return nil, domain.ErrGetDeliveryFeeInternalError
 	}
 
-	var output dto.RajaOngkirDeliveryInfoResDTO
-	if err := json.Unmarshal(body, &output); err != nil {
-		return nil, err
+	var originCity, destinationCity *entity.City
+	for i := range cities {
+		if int(cities[i].RoId) == input.Origin {
+			originCity = &cities[i]
+		}
+		if int(cities[i].RoId) == input.Destination {
+			destinationCity = &cities[i]
+		}
+	}
+	if originCity == nil || destinationCity == nil {
+		return nil, domain.ErrGetDeliveryFeeInternalError
+	}
+
+	scope := dto.DELIVERY_RATE_SCOPE_INTER_PROVINCE
+	if originCity.RoId == destinationCity.RoId {
+		scope = dto.DELIVERY_RATE_SCOPE_SAME_CITY
+	} else if originCity.ProvinceID == destinationCity.ProvinceID {
+		scope = dto.DELIVERY_RATE_SCOPE_SAME_PROVINCE
+	}
+
+	var deliveryOption entity.DeliveryOption
+	err = r.db.Where("courier_code = ?", input.Courier).First(&deliveryOption).Error
+	if err != nil {
+		return nil, domain.ErrGetDeliveryFeeInternalError
+	}
+
+	var rates []entity.DeliveryRate
+	err = r.db.Where("courier_code = ? AND scope = ?", input.Courier, scope).
+		Order("cost_per_kg asc").
+		Find(&rates).
+		Error
+	if err != nil || len(rates) == 0 {
+		return nil, domain.ErrGetDeliveryFeeInternalError
+	}
+
+	courierCost := dto.RajaOngkirCourierCostDTO{
+		Code: input.Courier,
+		Name: deliveryOption.CourierName,
+	}
+	weightInKg := float64(input.Weight / dto.DELIVERY_RATE_WEIGHT_BUCKET)
+	for _, rate := range rates {
+		courierCost.Costs = append(courierCost.Costs, dto.RajaOngkirCostDTO{
+			Service:     rate.ServiceCode,
+			Description: rate.Description,
+			Cost: []dto.RajaOngkirCostValueDTO{{
+				Value: rate.CostPerKg * weightInKg,
+				Etd:   rate.Etd,
+			}},
+		})
 	}
 
+	var output dto.RajaOngkirDeliveryInfoResDTO
+	output.Rajaongkir.Status.Code = http.StatusOK
+	output.Rajaongkir.Results = []dto.RajaOngkirCourierCostDTO{courierCost}
+
 	return &output, nil
 }
 
@@ -151,3 +270,49 @@ func (r *deliveryRepositoryImpl) DeleteMerchantDeliveryOptions(merchantId uint,
 	}
 	return err
 }
+
+func (r *deliveryRepositoryImpl) GetMerchantShippingRules(merchantId uint, courierCode string) ([]entity.MerchantShippingRule, error) {
+	var rules []entity.MerchantShippingRule
+	sq := r.db.Select("merchant_delivery_options.id").
+		Model(&entity.MerchantDeliveryOption{}).
+		Joins("JOIN delivery_options ON delivery_options.id = merchant_delivery_options.delivery_option_id").
+		Where("merchant_delivery_options.merchant_id = ? AND delivery_options.courier_code = ?", merchantId, courierCode)
+	err := r.db.Where("merchant_delivery_option_id in (?)", sq).
+		Order("id asc").
+		Find(&rules).
+		Error
+	if err != nil {
+		return nil, domain.ErrGetMerchantShippingRuleInternalError
+	}
+
+	return rules, nil
+}
+
+func (r *deliveryRepositoryImpl) CreateMerchantShippingRule(rule entity.MerchantShippingRule) (*entity.MerchantShippingRule, error) {
+	err := r.db.Create(&rule).Error
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return nil, domain.ErrMerchantShippingRuleDuplicate
+		}
+		return nil, domain.ErrUpdateMerchantShippingRuleInternalError
+	}
+
+	return &rule, nil
+}
+
+func (r *deliveryRepositoryImpl) DeleteMerchantShippingRule(merchantId uint, ruleId uint) error {
+	sq := r.db.Select("id").
+		Model(&entity.MerchantDeliveryOption{}).
+		Where("merchant_id = ?", merchantId)
+	res := r.db.Where("id = ?", ruleId).
+		Where("merchant_delivery_option_id in (?)", sq).
+		Delete(&entity.MerchantShippingRule{})
+	if res.Error != nil {
+		return domain.ErrUpdateMerchantShippingRuleInternalError
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrMerchantShippingRuleNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cache"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"github.com/rs/zerolog/log"
+)
+
+type EventStreamRepository interface {
+	Publish(channels []string, event dto.StreamEventDTO) error
+	Subscribe(ctx context.Context, channels []string) (<-chan dto.StreamEventDTO, func(), error)
+}
+
+type EventStreamRepositoryConfig struct {
+	RDB *cache.RDBConnection
+}
+
+type eventStreamRepositoryImpl struct {
+	rdb *cache.RDBConnection
+}
+
+func NewEventStreamRepository(c EventStreamRepositoryConfig) EventStreamRepository {
+	return &eventStreamRepositoryImpl{
+		rdb: c.RDB,
+	}
+}
+
+func (r *eventStreamRepositoryImpl) Publish(channels []string, event dto.StreamEventDTO) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return domain.ErrPublishStreamEvent
+	}
+
+	for _, channel := range channels {
+		err = r.rdb.Publish(context.Background(), channel, payload).Err()
+		if err != nil {
+			log.Error().Msgf("error publishing stream event to %s: %v", channel, err)
+			return domain.ErrPublishStreamEvent
+		}
+	}
+
+	return nil
+}
+
+// Subscribe relays the events published to the channels until ctx is done or
+// the returned close function is called. Slow consumers drop events instead
+// of blocking the redis connection.
+func (r *eventStreamRepositoryImpl) Subscribe(ctx context.Context, channels []string) (<-chan dto.StreamEventDTO, func(), error) {
+	pubsub := r.rdb.Subscribe(ctx, channels...)
+	_, err := pubsub.Receive(ctx)
+	if err != nil {
+		_ = pubsub.Close()
+		return nil, nil, domain.ErrSubscribeStreamEvent
+	}
+
+	events := make(chan dto.StreamEventDTO, dto.EVENT_STREAM_BUFFER_SIZE)
+	go func() {
+		defer close(events)
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var event dto.StreamEventDTO
+				err := json.Unmarshal([]byte(message.Payload), &event)
+				if err != nil {
+					log.Error().Msgf("error decoding stream event: %v", err)
+					continue
+				}
+
+				select {
+				case events <- event:
+				default:
+					log.Warn().Msgf("stream event %s dropped, consumer is too slow", event.Event)
+				}
+			}
+		}
+	}()
+
+	return events, func() { _ = pubsub.Close() }, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LedgerRepository interface {
+	GetWalletAccountTx(tx *gorm.DB, walletId uint) (*entity.LedgerAccount, error)
+	GetUserWalletAccountTx(tx *gorm.DB, userId uint) (*entity.LedgerAccount, error)
+	GetMerchantHoldingAccountTx(tx *gorm.DB, merchantHoldingAccId uint) (*entity.LedgerAccount, error)
+	GetExternalAccountTx(tx *gorm.DB, code string) (*entity.LedgerAccount, error)
+
+	PostEntryTx(tx *gorm.DB, entry entity.LedgerJournalEntry) error
+
+	GetAccountBalances() ([]entity.LedgerAccountBalance, error)
+}
+
+type LedgerRepositoryConfig struct {
+	DB *gorm.DB
+}
+
+type ledgerRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(c LedgerRepositoryConfig) LedgerRepository {
+	return &ledgerRepositoryImpl{
+		db: c.DB,
+	}
+}
+
+func (r *ledgerRepositoryImpl) GetWalletAccountTx(tx *gorm.DB, walletId uint) (*entity.LedgerAccount, error) {
+	return r.getOrCreateAccountTx(tx, entity.LedgerAccount{
+		Code:      fmt.Sprintf("%s:%d", dto.LEDGER_OWNER_TYPE_WALLET, walletId),
+		OwnerType: dto.LEDGER_OWNER_TYPE_WALLET,
+		OwnerId:   walletId,
+	})
+}
+
+func (r *ledgerRepositoryImpl) GetUserWalletAccountTx(tx *gorm.DB, userId uint) (*entity.LedgerAccount, error) {
+	var wallet entity.Wallet
+	err := tx.Model(&wallet).Select("id").Where("user_id = ?", userId).First(&wallet).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrWalletNotFound
+		}
+		return nil, domain.ErrLedgerGetAccount
+	}
+
+	return r.GetWalletAccountTx(tx, wallet.ID)
+}
+
+func (r *ledgerRepositoryImpl) GetMerchantHoldingAccountTx(tx *gorm.DB, merchantHoldingAccId uint) (*entity.LedgerAccount, error) {
+	return r.getOrCreateAccountTx(tx, entity.LedgerAccount{
+		Code:      fmt.Sprintf("%s:%d", dto.LEDGER_OWNER_TYPE_MERCHANT_HOLDING_ACC, merchantHoldingAccId),
+		OwnerType: dto.LEDGER_OWNER_TYPE_MERCHANT_HOLDING_ACC,
+		OwnerId:   merchantHoldingAccId,
+	})
+}
+
+func (r *ledgerRepositoryImpl) GetExternalAccountTx(tx *gorm.DB, code string) (*entity.LedgerAccount, error) {
+	return r.getOrCreateAccountTx(tx, entity.LedgerAccount{
+		Code:      code,
+		OwnerType: dto.LEDGER_OWNER_TYPE_EXTERNAL,
+	})
+}
+
+func (r *ledgerRepositoryImpl) PostEntryTx(tx *gorm.DB, entry entity.LedgerJournalEntry) error {
+	var totalDebit, totalCredit float64
+	postings := make([]entity.LedgerPosting, 0, len(entry.LedgerPostings))
+	for _, posting := range entry.LedgerPostings {
+		if posting.Amount == 0 {
+			continue
+		}
+
+		if posting.Amount < 0 {
+			return domain.ErrLedgerUnbalancedEntry
+		}
+
+		switch posting.Direction {
+		case dto.LEDGER_DEBIT_CODE:
+			totalDebit += posting.Amount
+		case dto.LEDGER_CREDIT_CODE:
+			totalCredit += posting.Amount
+		default:
+			return domain.ErrLedgerUnbalancedEntry
+		}
+
+		posting.LedgerAccount = entity.LedgerAccount{}
+		postings = append(postings, posting)
+	}
+
+	if len(postings) == 0 {
+		return nil
+	}
+
+	if math.Abs(totalDebit-totalCredit) >= dto.LEDGER_DRIFT_TOLERANCE {
+		log.Error().Msgf("unbalanced ledger entry %s %s: debit %.2f credit %.2f", entry.Type, entry.Reference, totalDebit, totalCredit)
+		return domain.ErrLedgerUnbalancedEntry
+	}
+
+	entry.LedgerPostings = postings
+	err := tx.Create(&entry).Error
+	if err != nil {
+		log.Error().Msgf("cannot post ledger entry %s %s: %v", entry.Type, entry.Reference, err)
+		return domain.ErrLedgerPostEntry
+	}
+
+	return nil
+}
+
+func (r *ledgerRepositoryImpl) GetAccountBalances() ([]entity.LedgerAccountBalance, error) {
+	var balances []entity.LedgerAccountBalance
+	err := r.db.Raw(`
+		select ? as owner_type, w.id as owner_id, w.balance as actual_balance,
+			coalesce(sum(case when lp.direction = ? then lp.amount else -lp.amount end), 0) as ledger_balance
+		from wallets w
+		left join ledger_accounts la on la.owner_type = ? and la.owner_id = w.id and la.deleted_at is null
+		left join ledger_postings lp on lp.ledger_account_id = la.id and lp.deleted_at is null
+		where w.deleted_at is null
+		group by w.id, w.balance
+		union all
+		select ? as owner_type, mha.id as owner_id, mha.balance as actual_balance,
+			coalesce(sum(case when lp.direction = ? then lp.amount else -lp.amount end), 0) as ledger_balance
+		from merchant_holding_accounts mha
+		left join ledger_accounts la on la.owner_type = ? and la.owner_id = mha.id and la.deleted_at is null
+		left join ledger_postings lp on lp.ledger_account_id = la.id and lp.deleted_at is null
+		where mha.deleted_at is null
+		group by mha.id, mha.balance
+		order by owner_type, owner_id
+	`,
+		dto.LEDGER_OWNER_TYPE_WALLET, dto.LEDGER_CREDIT_CODE, dto.LEDGER_OWNER_TYPE_WALLET,
+		dto.LEDGER_OWNER_TYPE_MERCHANT_HOLDING_ACC, dto.LEDGER_CREDIT_CODE, dto.LEDGER_OWNER_TYPE_MERCHANT_HOLDING_ACC,
+	).Scan(&balances).Error
+	if err != nil {
+		log.Error().Msgf("cannot get ledger account balances: %v", err)
+		return nil, domain.ErrLedgerGetAccountBalances
+	}
+
+	return balances, nil
+}
+
+func (r *ledgerRepositoryImpl) getOrCreateAccountTx(tx *gorm.DB, account entity.LedgerAccount) (*entity.LedgerAccount, error) {
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoNothing: true,
+	}).Create(&account).Error
+	if err != nil {
+		log.Error().Msgf("cannot create ledger account %s: %v", account.Code, err)
+		return nil, domain.ErrLedgerGetAccount
+	}
+
+	var foundAccount entity.LedgerAccount
+	err = tx.Where("code = ?", account.Code).First(&foundAccount).Error
+	if err != nil {
+		log.Error().Msgf("cannot get ledger account %s: %v", account.Code, err)
+		return nil, domain.ErrLedgerGetAccount
+	}
+
+	return &foundAccount, nil
+}
@@ -21,9 +21,6 @@ type MarketplaceVoucherRepository interface {
 	CreateMarketplaceVoucher(voucher *entity.MarketplaceVoucher) (*entity.MarketplaceVoucher, error)
 	UpdateMarketplaceVoucher(voucher *entity.MarketplaceVoucher) (*entity.MarketplaceVoucher, error)
 	DeleteMarketplaceVoucher(voucher *entity.MarketplaceVoucher) (*entity.MarketplaceVoucher, error)
-
-	DecreaseMarketplaceVoucherQuotaTx(tx *gorm.DB, voucherID uint) error
-	IncreaseMarketplaceVoucherQuotaTx(tx *gorm.DB, voucherID uint) error
 }
 
 type MarketplaceVoucherRepositoryConfig struct {
@@ -154,25 +151,3 @@ func (r *mpVoucherRepositoryImpl) DeleteMarketplaceVoucher(voucher *entity.Marke
 
 	return voucher, nil
 }
-
-func (r *mpVoucherRepositoryImpl) DecreaseMarketplaceVoucherQuotaTx(tx *gorm.DB, voucherID uint) error {
-	err := tx.Model(&entity.MarketplaceVoucher{}).
-		Where("id = ?", voucherID).
-		Update("quota", gorm.Expr("quota - ?", 1)).Error
-	if err != nil {
-		return domain.ErrDecreaseMarketplaceVoucherQuota
-	}
-
-	return nil
-}
-
-func (r *mpVoucherRepositoryImpl) IncreaseMarketplaceVoucherQuotaTx(tx *gorm.DB, voucherID uint) error {
-	err := tx.Model(&entity.MarketplaceVoucher{}).
-		Where("id = ?", voucherID).
-		Update("quota", gorm.Expr("quota + ?", 1)).Error
-	if err != nil {
-		return domain.ErrIncreaseMarketplaceVoucherQuota
-	}
-
-	return nil
-}
@@ -12,6 +12,9 @@ import (
 type MerchantAnalyticsRepository interface {
 	GetMerchantDailyAnalytics(merchantDomain string, startDate, endDate time.Time) ([]entity.MerchantDailyAnalyticsHist, error)
 	UpdateMerchantDailyAnalytics(datePartition string) error
+
+	GetMerchantDailyResponsiveness(merchantDomain string, startDate, endDate time.Time) ([]entity.MerchantDailyResponsivenessHist, error)
+	UpdateMerchantDailyResponsiveness(datePartition string) error
 }
 
 type MerchantAnalyticsRepositoryConfig struct {
@@ -79,9 +82,21 @@ func (r *mAnalyticsRepositoryImpl) UpdateMerchantDailyAnalytics(datePartition st
 			and DATE('{{selected_date}}')
 		where ts.on_processed_at is not null and ts.on_delivered_at is not null
 		group by t.merchant_domain 
+	), cte_merchant_chat_responsiveness as (
+		select
+			cc.merchant_domain,
+			avg(cm.response_seconds)/3600 as crt
+		from chat_messages cm
+		join chat_conversations cc
+		on cc.id = cm.conversation_id
+		where cm.response_seconds is not null
+		and cm.created_at
+			between DATE('{{selected_date}}') - interval '30 day'
+			and DATE('{{selected_date}}')
+		group by cc.merchant_domain
 	)
 	INSERT INTO merchant_daily_analytics_hists
-	(date_partition, "domain", revenue, trx_count, avg_review, count_review, oad, osd)
+	(date_partition, "domain", revenue, trx_count, avg_review, count_review, oad, osd, crt)
 	
 	select 
 		DATE('{{selected_date}}') as date_partition, 
@@ -91,14 +106,17 @@ func (r *mAnalyticsRepositoryImpl) UpdateMerchantDailyAnalytics(datePartition st
 		cmr.avg_review,
 		cmr.count_review,
 		cmr2.oad,
-		cmr2.osd
+		cmr2.osd,
+		cmcr.crt
 	from merchants m
 	left join cte_merchant_trx_and_revenue cmtar
 	on m.domain = cmtar.merchant_domain
 	left join cte_merchant_review cmr
 	on m.domain = cmr.merchant_domain
 	left join cte_merchant_responsiveness cmr2
-	on m.domain = cmr2.merchant_domain;`, "{{selected_date}}", datePartition)
+	on m.domain = cmr2.merchant_domain
+	left join cte_merchant_chat_responsiveness cmcr
+	on m.domain = cmcr.merchant_domain;`, "{{selected_date}}", datePartition)
 
 	err := r.db.
 		Exec(query).
@@ -109,3 +127,94 @@ func (r *mAnalyticsRepositoryImpl) UpdateMerchantDailyAnalytics(datePartition st
 
 	return nil
 }
+
+func (r *mAnalyticsRepositoryImpl) GetMerchantDailyResponsiveness(merchantDomain string, startDate, endDate time.Time) ([]entity.MerchantDailyResponsivenessHist, error) {
+	var responsiveness []entity.MerchantDailyResponsivenessHist
+	err := r.db.Model(&responsiveness).
+		Where("date_partition BETWEEN ? AND ?", startDate, endDate).
+		Where("domain = ?", merchantDomain).
+		Order("date_partition asc, metric asc").
+		Find(&responsiveness).Error
+	if err != nil {
+		return nil, domain.ErrGetMerchantAnalytics
+	}
+
+	return responsiveness, nil
+}
+
+// UpdateMerchantDailyResponsiveness replaces the percentiles of the date with the
+// latencies, in hours, of the transitions that happened in the 30 days before it.
+func (r *mAnalyticsRepositoryImpl) UpdateMerchantDailyResponsiveness(datePartition string) error {
+	query := strings.ReplaceAll(`
+	with cte_latency_samples as (
+		select
+			t.merchant_domain,
+			'ACCEPT' as metric,
+			extract(epoch from ts.on_processed_at - ts.on_waited_at)/3600 as latency
+		from transaction_statuses ts
+		join transactions t
+		on t.id = ts.transaction_id
+		where ts.on_waited_at is not null and ts.on_processed_at is not null
+		and ts.on_processed_at
+			between DATE('{{selected_date}}') - interval '30 day'
+			and DATE('{{selected_date}}')
+		union all
+		select
+			t.merchant_domain,
+			'SHIP' as metric,
+			extract(epoch from tds.on_delivery_at - ts.on_processed_at)/3600 as latency
+		from transaction_statuses ts
+		join transactions t
+		on t.id = ts.transaction_id
+		join transaction_delivery_statuses tds
+		on tds.transaction_id = t.id
+		where ts.on_processed_at is not null and tds.on_delivery_at is not null
+		and tds.on_delivery_at
+			between DATE('{{selected_date}}') - interval '30 day'
+			and DATE('{{selected_date}}')
+		union all
+		select
+			t.merchant_domain,
+			'REFUND' as metric,
+			extract(epoch from coalesce(rrs.accepted_by_seller_at, rrs.rejected_by_seller_at) - rrs.created_at)/3600 as latency
+		from refund_request_statuses rrs
+		join refund_requests rr
+		on rr.id = rrs.refund_request_id
+		join transactions t
+		on t.id = rr.transaction_id
+		where coalesce(rrs.accepted_by_seller_at, rrs.rejected_by_seller_at) is not null
+		and coalesce(rrs.accepted_by_seller_at, rrs.rejected_by_seller_at)
+			between DATE('{{selected_date}}') - interval '30 day'
+			and DATE('{{selected_date}}')
+	)
+	INSERT INTO merchant_daily_responsiveness_hists
+	(date_partition, "domain", metric, sample_count, p50, p90, p95)
+
+	select
+		DATE('{{selected_date}}') as date_partition,
+		cls.merchant_domain,
+		cls.metric,
+		count(*) as sample_count,
+		percentile_cont(0.5) within group (order by cls.latency) as p50,
+		percentile_cont(0.9) within group (order by cls.latency) as p90,
+		percentile_cont(0.95) within group (order by cls.latency) as p95
+	from cte_latency_samples cls
+	group by cls.merchant_domain, cls.metric;`, "{{selected_date}}", datePartition)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Where("date_partition = DATE(?)", datePartition).
+			Delete(&entity.MerchantDailyResponsivenessHist{}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(query).Error
+	})
+	if err != nil {
+		return domain.ErrUpdateMerchantAnalytics
+	}
+
+	return nil
+}
@@ -20,17 +20,20 @@ type MerchantHoldingAccountRepository interface {
 type MerchantHoldingAccountRepositoryImpl struct {
 	db               *gorm.DB
 	walletRepository WalletRepository
+	ledgerRepository LedgerRepository
 }
 
 type MerchantHoldingAccountRepositoryConfig struct {
 	DB               *gorm.DB
 	WalletRepository WalletRepository
+	LedgerRepository LedgerRepository
 }
 
 func NewMerchantHoldingAccountRepository(c MerchantHoldingAccountRepositoryConfig) MerchantHoldingAccountRepository {
 	return &MerchantHoldingAccountRepositoryImpl{
 		db:               c.DB,
 		walletRepository: c.WalletRepository,
+		ledgerRepository: c.LedgerRepository,
 	}
 }
 
@@ -120,6 +123,32 @@ func (r *MerchantHoldingAccountRepositoryImpl) WithdrawBalance(userId uint, merc
 		return nil, err
 	}
 
+	// record withdrawal in ledger
+	holdingLedgerAcc, err := r.ledgerRepository.GetMerchantHoldingAccountTx(tx, updatedHoldingAcc.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	walletLedgerAcc, err := r.ledgerRepository.GetUserWalletAccountTx(tx, userId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = r.ledgerRepository.PostEntryTx(tx, entity.LedgerJournalEntry{
+		Type:      dto.LEDGER_ENTRY_TYPE_MERCHANT_WITHDRAWAL,
+		Reference: fmt.Sprintf("MHA%d", newHistoryMerchantAcc.ID),
+		Notes:     "Withdrawal to wallet",
+		LedgerPostings: []entity.LedgerPosting{
+			{LedgerAccountId: holdingLedgerAcc.ID, Direction: dto.LEDGER_DEBIT_CODE, Amount: float64(amount)},
+			{LedgerAccountId: walletLedgerAcc.ID, Direction: dto.LEDGER_CREDIT_CODE, Amount: float64(amount)},
+		},
+	})
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("Failed to post merchant withdrawal ledger entry: %v", err)
+		return nil, err
+	}
+
 	err = tx.Commit().Error
 	if err != nil {
 		tx.Rollback()
@@ -33,9 +33,6 @@ type MerchantRepository interface {
 	CreateMerchantVoucher(voucher *entity.MerchantVoucher) (*entity.MerchantVoucher, error)
 	UpdateMerchantVoucher(voucher *entity.MerchantVoucher) (*entity.MerchantVoucher, error)
 	DeleteMerchantVoucher(merchantDomain string, voucher *entity.MerchantVoucher) (*entity.MerchantVoucher, error)
-
-	DecreaseMerchantVoucherQuotaTx(tx *gorm.DB, merchantDomain string, voucherID uint) error
-	IncreaseMerchantVoucherQuotaTx(tx *gorm.DB, merchantDomain string, voucherID uint) error
 	IncreaseMerchantNumOfSaleTx(tx *gorm.DB, merchantId uint, delta uint) error
 	UpdateMerchantRatingAndNumOfReviewTx(tx *gorm.DB, merchantId uint, rating float64) error
 }
@@ -375,29 +372,6 @@ func (r *merchantRepositoryImpl) UpdateMerchantVoucher(voucher *entity.MerchantV
 	return voucher, nil
 }
 
-func (r *merchantRepositoryImpl) DecreaseMerchantVoucherQuotaTx(tx *gorm.DB, merchantDomain string, voucherId uint) error {
-	err := tx.Model(&entity.MerchantVoucher{}).
-		Where("merchant_domain = ? AND id = ?", merchantDomain, voucherId).
-		Where("is_invalid = ? AND quota > ?", MERCHANT_VOUCHER_IS_VALID, MERCHANT_VOUCHER_MIN_QUOTA).
-		Update("quota", gorm.Expr("quota - ?", 1)).Error
-	if err != nil {
-		return domain.ErrDecreaseMerchantVoucherQuota
-	}
-
-	return nil
-}
-
-func (r *merchantRepositoryImpl) IncreaseMerchantVoucherQuotaTx(tx *gorm.DB, merchantDomain string, voucherId uint) error {
-	err := tx.Model(&entity.MerchantVoucher{}).
-		Where("merchant_domain = ? AND id = ?", merchantDomain, voucherId).
-		Update("quota", gorm.Expr("quota + ?", 1)).Error
-	if err != nil {
-		return domain.ErrIncreaseMarketplaceVoucherQuota
-	}
-
-	return nil
-}
-
 func (r *merchantRepositoryImpl) DeleteMerchantVoucher(merchantDomain string, voucher *entity.MerchantVoucher) (*entity.MerchantVoucher, error) {
 	err := r.db.Model(&voucher).
 		Where("merchant_domain = ?", merchantDomain).
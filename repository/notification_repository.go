@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"gorm.io/gorm"
+)
+
+type NotificationRepository interface {
+	Create(notifications []entity.Notification) error
+	CreateTx(tx *gorm.DB, notifications []entity.Notification) error
+	CreateTransactionNotifications(transactions []entity.Transaction, notificationType string) error
+
+	GetList(userId uint, req dto.NotificationListReqParamDTO) ([]entity.Notification, int64, error)
+	CountUnread(userId uint) (int64, error)
+	MarkRead(userId uint, notificationId uint) (*entity.Notification, error)
+	MarkAllRead(userId uint) error
+}
+
+type NotificationRepositoryConfig struct {
+	DB *gorm.DB
+}
+
+type notificationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(c NotificationRepositoryConfig) NotificationRepository {
+	return &notificationRepositoryImpl{
+		db: c.DB,
+	}
+}
+
+func (r *notificationRepositoryImpl) Create(notifications []entity.Notification) error {
+	return r.CreateTx(r.db, notifications)
+}
+
+func (r *notificationRepositoryImpl) CreateTx(tx *gorm.DB, notifications []entity.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	err := tx.Create(&notifications).Error
+	if err != nil {
+		return domain.ErrCreateNotification
+	}
+
+	return nil
+}
+
+// CreateTransactionNotifications sends the typed notification of every
+// transaction to its buyer, or to the merchant owner for merchant types.
+func (r *notificationRepositoryImpl) CreateTransactionNotifications(transactions []entity.Transaction, notificationType string) error {
+	template, ok := dto.NotificationTemplates[notificationType]
+	if !ok {
+		return domain.ErrCreateNotification
+	}
+
+	var merchantUserIds = make(map[string]uint)
+	if template.IsForMerchant {
+		var merchantDomains []string
+		for _, transaction := range transactions {
+			if transaction.Merchant.UserId != 0 {
+				merchantUserIds[transaction.MerchantDomain] = transaction.Merchant.UserId
+			} else {
+				merchantDomains = append(merchantDomains, transaction.MerchantDomain)
+			}
+		}
+
+		if len(merchantDomains) > 0 {
+			var merchants []entity.Merchant
+			err := r.db.Where("domain IN ?", merchantDomains).Find(&merchants).Error
+			if err != nil {
+				return domain.ErrCreateNotification
+			}
+			for _, merchant := range merchants {
+				merchantUserIds[merchant.Domain] = merchant.UserId
+			}
+		}
+	}
+
+	var notifications []entity.Notification
+	for _, transaction := range transactions {
+		notification := entity.Notification{
+			UserId:  transaction.UserId,
+			Type:    notificationType,
+			Title:   template.Title,
+			Message: fmt.Sprintf(template.Message, transaction.InvoiceCode),
+			Link:    "/transactions/" + transaction.InvoiceCode,
+		}
+		if template.IsForMerchant {
+			notification.UserId = merchantUserIds[transaction.MerchantDomain]
+			notification.Link = "/merchant/transactions/" + transaction.InvoiceCode
+		}
+
+		if notification.UserId != 0 {
+			notifications = append(notifications, notification)
+		}
+	}
+
+	return r.Create(notifications)
+}
+
+func (r *notificationRepositoryImpl) GetList(userId uint, req dto.NotificationListReqParamDTO) ([]entity.Notification, int64, error) {
+	var notifications []entity.Notification
+	var totalData int64
+
+	query := r.db.Model(&entity.Notification{}).Where("user_id = ?", userId).Session(&gorm.Session{})
+	if req.Type != "" {
+		query = query.Where("type = ?", req.Type)
+	}
+	if req.IsUnread {
+		query = query.Where("read_at IS NULL")
+	}
+
+	err := query.Count(&totalData).Error
+	if err != nil {
+		return nil, 0, domain.ErrGetNotification
+	}
+
+	err = query.
+		Order("created_at DESC, id DESC").
+		Limit(req.Limit).
+		Offset((req.Page - 1) * req.Limit).
+		Find(&notifications).Error
+	if err != nil {
+		return nil, 0, domain.ErrGetNotification
+	}
+
+	return notifications, totalData, nil
+}
+
+func (r *notificationRepositoryImpl) CountUnread(userId uint) (int64, error) {
+	var unreadCount int64
+	err := r.db.Model(&entity.Notification{}).
+		Where("user_id = ?", userId).
+		Where("read_at IS NULL").
+		Count(&unreadCount).Error
+	if err != nil {
+		return 0, domain.ErrGetNotification
+	}
+
+	return unreadCount, nil
+}
+
+func (r *notificationRepositoryImpl) MarkRead(userId uint, notificationId uint) (*entity.Notification, error) {
+	var notification entity.Notification
+	err := r.db.Where("id = ?", notificationId).Where("user_id = ?", userId).First(&notification).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotificationNotFound
+		}
+		return nil, domain.ErrGetNotification
+	}
+
+	if notification.ReadAt != nil {
+		return &notification, nil
+	}
+
+	timeNow := time.Now()
+	err = r.db.Model(&notification).Update("read_at", timeNow).Error
+	if err != nil {
+		return nil, domain.ErrUpdateNotification
+	}
+	notification.ReadAt = &timeNow
+
+	return &notification, nil
+}
+
+func (r *notificationRepositoryImpl) MarkAllRead(userId uint) error {
+	err := r.db.Model(&entity.Notification{}).
+		Where("user_id = ?", userId).
+		Where("read_at IS NULL").
+		Update("read_at", time.Now()).Error
+	if err != nil {
+		return domain.ErrUpdateNotification
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"sync"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+)
+
+// PaymentProvider is implemented by every payment gateway that can be used
+// at checkout. Providers are looked up by entity.PaymentMethod.Code.
+type PaymentProvider interface {
+	Code() string
+	CreateCharge(req dto.PaymentChargeReqDTO) (*dto.PaymentChargeResDTO, error)
+	VerifyCallback(req dto.PaymentCallbackReqDTO) (*dto.PaymentCallbackResDTO, error)
+	Cancel(paymentId string) error
+	Refund(paymentId string, amount uint) error
+	QueryStatus(paymentId string) (*dto.PaymentStatusResDTO, error)
+}
+
+type PaymentProviderRegistry interface {
+	Register(provider PaymentProvider)
+	Get(paymentMethodCode string) (PaymentProvider, error)
+}
+
+type PaymentProviderRegistryConfig struct {
+	Providers []PaymentProvider
+}
+
+type paymentProviderRegistryImpl struct {
+	mu        sync.RWMutex
+	providers map[string]PaymentProvider
+}
+
+func NewPaymentProviderRegistry(c PaymentProviderRegistryConfig) PaymentProviderRegistry {
+	registry := &paymentProviderRegistryImpl{
+		providers: make(map[string]PaymentProvider),
+	}
+	for _, provider := range c.Providers {
+		registry.Register(provider)
+	}
+
+	return registry
+}
+
+func (r *paymentProviderRegistryImpl) Register(provider PaymentProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.providers[provider.Code()] = provider
+}
+
+func (r *paymentProviderRegistryImpl) Get(paymentMethodCode string) (PaymentProvider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider, ok := r.providers[paymentMethodCode]
+	if !ok {
+		return nil, domain.ErrPaymentProviderNotFound
+	}
+
+	return provider, nil
+}
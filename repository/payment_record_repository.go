@@ -12,10 +12,14 @@ import (
 type PaymentRecordRepository interface {
 	CreateTx(tx *gorm.DB, input entity.PaymentRecord) (*entity.PaymentRecord, error)
 	UpdateTx(tx *gorm.DB, input entity.PaymentRecord) (*entity.PaymentRecord, error)
+	MarkPaidTx(tx *gorm.DB, paymentId string, paidAt time.Time) (bool, error)
+	MarkCanceledTx(tx *gorm.DB, paymentId string, canceledAt time.Time) (bool, error)
 	GetByPaymentId(paymentId string) (*entity.PaymentRecord, error)
 	GetDetailByPaymentId(paymentId string) (*entity.PaymentRecord, error)
 
 	GetWaitingForPayment(userId uint) ([]entity.PaymentRecord, error)
+	GetPendingByPaymentMethod(paymentMethodId uint, createdAfter time.Time, createdBefore time.Time, limit int) ([]entity.PaymentRecord, error)
+	UpdateLastCheckedAt(paymentIds []string) error
 }
 
 type PaymentRecordRepositoryConfig struct {
@@ -75,6 +79,28 @@ func (r *paymentRecordRepositoryImpl) UpdateTx(tx *gorm.DB, input entity.Payment
 	return &input, nil
 }
 
+func (r *paymentRecordRepositoryImpl) MarkPaidTx(tx *gorm.DB, paymentId string, paidAt time.Time) (bool, error) {
+	return r.settleTx(tx, paymentId, "paid_at", paidAt)
+}
+
+func (r *paymentRecordRepositoryImpl) MarkCanceledTx(tx *gorm.DB, paymentId string, canceledAt time.Time) (bool, error) {
+	return r.settleTx(tx, paymentId, "canceled_at", canceledAt)
+}
+
+// settleTx moves an unsettled payment to paid or canceled, it reports false
+// when a concurrent callback or reconcile run already settled it.
+func (r *paymentRecordRepositoryImpl) settleTx(tx *gorm.DB, paymentId string, column string, at time.Time) (bool, error) {
+	res := tx.Model(&entity.PaymentRecord{}).
+		Where("payment_id = ?", paymentId).
+		Where("paid_at IS NULL AND canceled_at IS NULL").
+		Update(column, at)
+	if res.Error != nil {
+		return false, domain.ErrUpdatePaymentRecord
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
 func (r *paymentRecordRepositoryImpl) GetByPaymentId(paymentId string) (*entity.PaymentRecord, error) {
 	var result entity.PaymentRecord
 	err := r.db.
@@ -112,3 +138,39 @@ func (r *paymentRecordRepositoryImpl) GetDetailByPaymentId(paymentId string) (*e
 
 	return &result, nil
 }
+
+// GetPendingByPaymentMethod returns the unsettled payments created between
+// createdAfter and createdBefore, the ones checked least recently first.
+func (r *paymentRecordRepositoryImpl) GetPendingByPaymentMethod(paymentMethodId uint, createdAfter time.Time, createdBefore time.Time, limit int) ([]entity.PaymentRecord, error) {
+	var result []entity.PaymentRecord
+	err := r.db.
+		Preload("PaymentMethod").
+		Where("payment_method_id = ?", paymentMethodId).
+		Where("paid_at IS NULL AND canceled_at IS NULL").
+		Where("created_at >= ?", createdAfter).
+		Where("created_at <= ?", createdBefore).
+		Order("last_checked_at ASC NULLS FIRST").
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, domain.ErrGetPaymentRecord
+	}
+
+	return result, nil
+}
+
+func (r *paymentRecordRepositoryImpl) UpdateLastCheckedAt(paymentIds []string) error {
+	if len(paymentIds) == 0 {
+		return nil
+	}
+
+	err := r.db.Model(&entity.PaymentRecord{}).
+		Where("payment_id IN ?", paymentIds).
+		Update("last_checked_at", time.Now()).Error
+	if err != nil {
+		return domain.ErrUpdatePaymentRecord
+	}
+
+	return nil
+}
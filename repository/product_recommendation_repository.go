@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"fmt"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cache"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+type ProductRecommendationRepository interface {
+	RefreshProductSimilarities() error
+	GetUserRecommendationScores(userId uint) ([]dto.ProductRecommendationScoreDTO, error)
+
+	RefreshBoughtTogetherProducts() error
+	RefreshSimilarProducts() error
+	GetRelatedProductIds(productId uint, relationType string) ([]uint, error)
+}
+
+type ProductRecommendationRepositoryConfig struct {
+	DB  *gorm.DB
+	RDB *cache.RDBConnection
+}
+
+type productRecommendationRepositoryImpl struct {
+	db  *gorm.DB
+	rdb *cache.RDBConnection
+}
+
+func NewProductRecommendationRepository(c ProductRecommendationRepositoryConfig) ProductRecommendationRepository {
+	return &productRecommendationRepositoryImpl{
+		db:  c.DB,
+		rdb: c.RDB,
+	}
+}
+
+// productInteractionQuery lists every (user, product) pair with the strongest
+// signal of the user towards the product: purchase, cart or favorite.
+const productInteractionQuery = `
+	select user_id, product_id, max(weight) as weight from (
+		select t.user_id, (ci->>'product_id')::bigint as product_id, @purchase_weight::numeric as weight
+		from transactions t
+		cross join jsonb_array_elements(t.cart_items) ci
+		left join transaction_statuses ts on ts.transaction_id = t.id
+		where t.deleted_at is null and ts.on_canceled_at is null
+		union all
+		select user_id, product_id, @cart_weight::numeric
+		from cart_items
+		where deleted_at is null
+		union all
+		select user_id, product_id, @favorite_weight::numeric
+		from user_favorite_products
+		where deleted_at is null
+	) as signals
+	group by user_id, product_id
+`
+
+func productInteractionWeights() map[string]interface{} {
+	return map[string]interface{}{
+		"purchase_weight": dto.PRODUCT_INTERACTION_WEIGHT_PURCHASE,
+		"cart_weight":     dto.PRODUCT_INTERACTION_WEIGHT_CART,
+		"favorite_weight": dto.PRODUCT_INTERACTION_WEIGHT_FAVORITE,
+	}
+}
+
+// RefreshProductSimilarities rebuilds the item-to-item cosine similarity of
+// products over the users interacting with them, keeping the closest neighbors.
+func (r *productRecommendationRepositoryImpl) RefreshProductSimilarities() (refreshErr error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			log.Error().Msgf("Recovered in RefreshProductSimilarities repo: %v", r)
+			refreshErr = domain.ErrRefreshProductSimilarities
+		}
+	}()
+
+	err := tx.Exec("delete from product_similarities").Error
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("error: clear product similarities: %v", err)
+		return domain.ErrRefreshProductSimilarities
+	}
+
+	params := productInteractionWeights()
+	params["max_neighbors"] = dto.PRODUCT_SIMILARITY_MAX_NEIGHBORS
+	err = tx.Exec(`
+		with interactions as (`+productInteractionQuery+`),
+		norms as (
+			select product_id, sqrt(sum(weight * weight)) as norm
+			from interactions
+			group by product_id
+		),
+		similarities as (
+			select
+				a.product_id,
+				b.product_id as related_product_id,
+				sum(a.weight * b.weight) / (na.norm * nb.norm) as score
+			from interactions a
+			join interactions b on b.user_id = a.user_id and b.product_id <> a.product_id
+			join norms na on na.product_id = a.product_id
+			join norms nb on nb.product_id = b.product_id
+			group by a.product_id, b.product_id, na.norm, nb.norm
+		)
+		insert into product_similarities (product_id, related_product_id, score, updated_at)
+		select product_id, related_product_id, score, now()
+		from (
+			select *, row_number() over (partition by product_id order by score desc) as rank
+			from similarities
+		) as ranked
+		where rank <= @max_neighbors
+	`, params).Error
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("error: refresh product similarities: %v", err)
+		return domain.ErrRefreshProductSimilarities
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return domain.ErrRefreshProductSimilarities
+	}
+
+	return nil
+}
+
+// GetUserRecommendationScores ranks products similar to the ones the user has
+// interacted with, excluding those products themselves.
+func (r *productRecommendationRepositoryImpl) GetUserRecommendationScores(userId uint) ([]dto.ProductRecommendationScoreDTO, error) {
+	var scores []dto.ProductRecommendationScoreDTO
+
+	params := productInteractionWeights()
+	params["user_id"] = userId
+	params["limit"] = dto.PRODUCT_RECOMMENDATION_LIMIT
+	err := r.db.Raw(`
+		with seeds as (
+			select product_id, weight from (`+productInteractionQuery+`) as interactions
+			where user_id = @user_id
+		)
+		select
+			ps.related_product_id as product_id,
+			sum(ps.score * seeds.weight) as score
+		from product_similarities ps
+		join seeds on seeds.product_id = ps.product_id
+		join products p on p.id = ps.related_product_id
+		where p.deleted_at is null
+			and p.is_archived = false
+			and ps.related_product_id not in (select product_id from seeds)
+		group by ps.related_product_id
+		order by score desc, ps.related_product_id
+		limit @limit
+	`, params).Scan(&scores).Error
+	if err != nil {
+		log.Error().Msgf("error: get user %d recommendations: %v", userId, err)
+		return nil, domain.ErrGetProductRecommendations
+	}
+
+	return scores, nil
+}
+
+// RefreshBoughtTogetherProducts counts how often two products were bought in
+// the same transaction.
+func (r *productRecommendationRepositoryImpl) RefreshBoughtTogetherProducts() error {
+	return r.refreshRelatedProducts(dto.PRODUCT_RELATION_TYPE_BOUGHT_TOGETHER, `
+		with baskets as (
+			select distinct t.id as transaction_id, (ci->>'product_id')::bigint as product_id
+			from transactions t
+			cross join jsonb_array_elements(t.cart_items) ci
+			left join transaction_statuses ts on ts.transaction_id = t.id
+			where t.deleted_at is null and ts.on_canceled_at is null
+		),
+		related as (
+			select a.product_id, b.product_id as related_product_id, count(*)::numeric as score
+			from baskets a
+			join baskets b on b.transaction_id = a.transaction_id and b.product_id <> a.product_id
+			group by a.product_id, b.product_id
+		)
+	`, nil)
+}
+
+// RefreshSimilarProducts relates products of the same top level category
+// within a close price band, preferring the closest category and price.
+func (r *productRecommendationRepositoryImpl) RefreshSimilarProducts() error {
+	return r.refreshRelatedProducts(dto.PRODUCT_RELATION_TYPE_SIMILAR, `
+		with product_categories as (
+			select p.id, p.min_real_price as price, c.id as category_id, c.parent_id, c.grandparent_id
+			from products p
+			join categories c on c.id = p.category_id
+			where p.deleted_at is null and p.is_archived = false
+		),
+		related as (
+			select
+				a.id as product_id,
+				b.id as related_product_id,
+				case
+					when b.category_id = a.category_id then 1.0
+					when b.parent_id = a.parent_id or b.parent_id = a.category_id or a.parent_id = b.category_id then 0.6
+					else 0.3
+				end + 0.5 * (1 - abs(b.price - a.price) / greatest(a.price, 1)) as score
+			from product_categories a
+			join product_categories b on b.id <> a.id
+				and coalesce(b.grandparent_id, b.parent_id, b.category_id) = coalesce(a.grandparent_id, a.parent_id, a.category_id)
+			where abs(b.price - a.price) <= @price_band * greatest(a.price, 1)
+		)
+	`, map[string]interface{}{"price_band": dto.PRODUCT_SIMILAR_PRICE_BAND})
+}
+
+// refreshRelatedProducts replaces the relations of the given type with the
+// top scored rows of the "related" CTE defined by withQuery.
+func (r *productRecommendationRepositoryImpl) refreshRelatedProducts(relationType string, withQuery string, params map[string]interface{}) (refreshErr error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			log.Error().Msgf("Recovered in refreshRelatedProducts repo: %v", r)
+			refreshErr = domain.ErrRefreshRelatedProducts
+		}
+	}()
+
+	err := tx.Exec("delete from product_related_products where relation_type = ?", relationType).Error
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("error: clear %s related products: %v", relationType, err)
+		return domain.ErrRefreshRelatedProducts
+	}
+
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	params["relation_type"] = relationType
+	params["max_neighbors"] = dto.PRODUCT_RELATED_MAX_NEIGHBORS
+	err = tx.Exec(withQuery+`
+		insert into product_related_products (product_id, related_product_id, relation_type, score, updated_at)
+		select product_id, related_product_id, @relation_type, score, now()
+		from (
+			select *, row_number() over (partition by product_id order by score desc, related_product_id) as rank
+			from related
+		) as ranked
+		where rank <= @max_neighbors
+	`, params).Error
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("error: refresh %s related products: %v", relationType, err)
+		return domain.ErrRefreshRelatedProducts
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return domain.ErrRefreshRelatedProducts
+	}
+
+	// cached relations would otherwise be served until they expire
+	cachePattern := fmt.Sprintf("product-related:%s:*", relationType)
+	err = r.rdb.DeleteCacheByPattern(cachePattern)
+	if err != nil {
+		log.Error().Msgf("error: clear cached related products %s: %v", cachePattern, err)
+	}
+
+	return nil
+}
+
+func (r *productRecommendationRepositoryImpl) GetRelatedProductIds(productId uint, relationType string) ([]uint, error) {
+	cacheKey := fmt.Sprintf("product-related:%s:%d", relationType, productId)
+
+	var productIdList []uint
+	err := r.rdb.GetCache(cacheKey, &productIdList)
+	if err == nil {
+		return productIdList, nil
+	}
+
+	err = r.db.Table("product_related_products").
+		Where("product_id = ?", productId).
+		Where("relation_type = ?", relationType).
+		Order("score desc, related_product_id").
+		Pluck("related_product_id", &productIdList).Error
+	if err != nil {
+		return nil, domain.ErrGetRelatedProducts
+	}
+
+	err = r.rdb.SetCache(cacheKey, productIdList, dto.PRODUCT_RELATED_CACHE_MINUTES)
+	if err != nil {
+		log.Error().Msgf("error: cache related products %s: %v", cacheKey, err)
+	}
+
+	return productIdList, nil
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
@@ -8,11 +11,16 @@ import (
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository interface {
 	GetProductList(req dto.ProductListReqParamDTO) ([]entity.Product, int64, error)
+	GetProductListFacets(req dto.ProductListReqParamDTO) (*dto.ProductSearchFacetsDTO, error)
+	GetProductSearchHighlights(search string, productIdList []uint) (map[uint]string, error)
 	GetRecommendationProductList(req dto.PaginationRequest) ([]entity.Product, int64, error)
+	GetRecommendationProductIds(limit int) ([]uint, error)
+	GetProductListByIds(productIdList []uint) ([]entity.Product, error)
 	GetProductBySlug(slug string) (*entity.Product, error)
 	GetProductVariantDetailByProductID(productId uint) (*entity.Product, error)
 	GetProductsByProductIds(productIdList []uint) ([]entity.Product, error)
@@ -35,16 +43,19 @@ type ProductRepository interface {
 }
 
 type ProductRepositoryConfig struct {
-	DB *gorm.DB
+	DB                                *gorm.DB
+	BackInStockSubscriptionRepository BackInStockSubscriptionRepository
 }
 
 type productRepositoryImpl struct {
-	db *gorm.DB
+	db                                *gorm.DB
+	backInStockSubscriptionRepository BackInStockSubscriptionRepository
 }
 
 func NewProductRepository(c ProductRepositoryConfig) ProductRepository {
 	return &productRepositoryImpl{
-		db: c.DB,
+		db:                                c.DB,
+		backInStockSubscriptionRepository: c.BackInStockSubscriptionRepository,
 	}
 }
 
@@ -76,14 +87,9 @@ func (r *productRepositoryImpl) getBaseProductQuery() *gorm.DB {
 		Preload("Merchant.City")
 }
 
-func (r *productRepositoryImpl) GetProductList(req dto.ProductListReqParamDTO) ([]entity.Product, int64, error) {
-	var products []entity.Product
-	var total int64
-	PageOffset := req.Pagination.Limit * (req.Pagination.Page - 1)
-	if req.CategoryId == 0 && req.MerchantDomain == "" && req.Search == "" {
-		return products, total, nil
-	}
-
+// productListFilterQuery returns the product list query with every filter of
+// the request applied, without selected columns, ordering or pagination.
+func (r *productRepositoryImpl) productListFilterQuery(req dto.ProductListReqParamDTO) *gorm.DB {
 	subQueryValidPromotion := r.db.Raw(`
 		select product_id, min_discounted_price, max_discounted_price
 		from product_promotions pp
@@ -108,12 +114,6 @@ func (r *productRepositoryImpl) GetProductList(req dto.ProductListReqParamDTO) (
 	fromTable := "products as prod, (?) as cte, product_analytics as ProductAnalytic"
 
 	query := r.db.Unscoped().Table(fromTable, subQueryDiscountedProduct).
-		Preload("ProductImages").
-		Preload("ProductAnalytic").
-		Preload("Merchant.City").
-		Select("prod.*, min_discounted_price, max_discounted_price").
-		Limit(req.Pagination.Limit).
-		Offset(PageOffset).
 		Where("prod.deleted_at is null").
 		Where("prod.id = cte.id").
 		Where("prod.product_analytic_id = ProductAnalytic.id").
@@ -122,13 +122,11 @@ func (r *productRepositoryImpl) GetProductList(req dto.ProductListReqParamDTO) (
 		Where("avg_rating >= ?", req.MinRating)
 
 	if req.Search != "" {
-		query = query.Where("documentq_weights @@ plainto_tsquery(?)", req.Search)
+		// the trigram word similarity keeps results for misspelled queries
+		query = query.Where("(documentq_weights @@ plainto_tsquery(?) or ? <% prod.title)", req.Search, req.Search)
 	}
 	if !req.IsMerchant {
-		query = query.Where("is_archived = ?", false).Order(req.SortBy + " " + req.SortDir)
-	}
-	if req.IsMerchant {
-		query = query.Order("created_at desc")
+		query = query.Where("is_archived = ?", false)
 	}
 
 	if req.CategoryId != 0 {
@@ -145,6 +143,39 @@ func (r *productRepositoryImpl) GetProductList(req dto.ProductListReqParamDTO) (
 		query = query.Table(fromTable, subQueryDiscountedProduct).Where("merchants.domain = prod.merchant_domain").Where("city_id IN ?", req.SellerCityIdList)
 	}
 
+	return query
+}
+
+func (r *productRepositoryImpl) GetProductList(req dto.ProductListReqParamDTO) ([]entity.Product, int64, error) {
+	var products []entity.Product
+	var total int64
+	PageOffset := req.Pagination.Limit * (req.Pagination.Page - 1)
+	if req.CategoryId == 0 && req.MerchantDomain == "" && req.Search == "" {
+		return products, total, nil
+	}
+
+	query := r.productListFilterQuery(req).
+		Preload("ProductImages").
+		Preload("ProductAnalytic").
+		Preload("Merchant.City").
+		Select("prod.*, min_discounted_price, max_discounted_price").
+		Limit(req.Pagination.Limit).
+		Offset(PageOffset)
+
+	if !req.IsMerchant && req.SortBy == dto.PRODUCT_SORT_BY_RELEVANCE && req.Search != "" {
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "ts_rank(documentq_weights, plainto_tsquery(?)) + word_similarity(?, prod.title) " + req.SortDir,
+			Vars: []interface{}{req.Search, req.Search},
+		}})
+	} else if !req.IsMerchant && req.SortBy == dto.PRODUCT_SORT_BY_RELEVANCE {
+		query = query.Order("avg_rating " + req.SortDir)
+	} else if !req.IsMerchant {
+		query = query.Order(req.SortBy + " " + req.SortDir)
+	}
+	if req.IsMerchant {
+		query = query.Order("created_at desc")
+	}
+
 	res := query.Find(&products).Limit(-1).Offset(-1).Count(&total)
 	if res.Error != nil {
 		return nil, total, domain.ErrGetProducts
@@ -152,6 +183,128 @@ func (r *productRepositoryImpl) GetProductList(req dto.ProductListReqParamDTO) (
 	return products, total, nil
 }
 
+func (r *productRepositoryImpl) GetProductListFacets(req dto.ProductListReqParamDTO) (*dto.ProductSearchFacetsDTO, error) {
+	facets := dto.ProductSearchFacetsDTO{
+		Categories:   make([]dto.ProductSearchFacetDTO, 0),
+		SellerCities: make([]dto.ProductSearchFacetDTO, 0),
+		PriceRanges:  make([]dto.ProductPriceFacetDTO, 0),
+		Ratings:      make([]dto.ProductRatingFacetDTO, 0),
+	}
+	if req.CategoryId == 0 && req.MerchantDomain == "" && req.Search == "" {
+		return &facets, nil
+	}
+
+	subQueryProductIds := r.productListFilterQuery(req).Select("prod.id")
+
+	err := r.db.Table("products").
+		Select("categories.id, categories.name, categories.slug, count(*) as count").
+		Joins("join categories on categories.id = products.category_id").
+		Where("products.id in (?)", subQueryProductIds).
+		Group("categories.id, categories.name, categories.slug").
+		Order("count desc").
+		Limit(dto.PRODUCT_SEARCH_FACET_LIMIT).
+		Scan(&facets.Categories).Error
+	if err != nil {
+		return nil, domain.ErrGetProductFacets
+	}
+
+	err = r.db.Table("products").
+		Select("cities.id, cities.name, count(*) as count").
+		Joins("join merchants on merchants.domain = products.merchant_domain").
+		Joins("join cities on cities.id = merchants.city_id").
+		Where("products.id in (?)", subQueryProductIds).
+		Group("cities.id, cities.name").
+		Order("count desc").
+		Limit(dto.PRODUCT_SEARCH_FACET_LIMIT).
+		Scan(&facets.SellerCities).Error
+	if err != nil {
+		return nil, domain.ErrGetProductFacets
+	}
+
+	priceBucketBounds := make([]string, len(dto.ProductSearchPriceBuckets))
+	for i, bound := range dto.ProductSearchPriceBuckets {
+		priceBucketBounds[i] = strconv.FormatFloat(bound, 'f', -1, 64)
+	}
+
+	var priceBuckets []struct {
+		Bucket int
+		Count  int64
+	}
+	err = r.db.Table("products").
+		Select(fmt.Sprintf("width_bucket(products.min_discount_price, array[%s]::numeric[]) as bucket, count(*) as count", strings.Join(priceBucketBounds, ","))).
+		Where("products.id in (?)", subQueryProductIds).
+		Group("bucket").
+		Order("bucket").
+		Scan(&priceBuckets).Error
+	if err != nil {
+		return nil, domain.ErrGetProductFacets
+	}
+	for _, priceBucket := range priceBuckets {
+		priceFacet := dto.ProductPriceFacetDTO{Count: priceBucket.Count}
+		if priceBucket.Bucket > 0 {
+			priceFacet.MinPrice = dto.ProductSearchPriceBuckets[priceBucket.Bucket-1]
+		}
+		if priceBucket.Bucket < len(dto.ProductSearchPriceBuckets) {
+			maxPrice := dto.ProductSearchPriceBuckets[priceBucket.Bucket]
+			priceFacet.MaxPrice = &maxPrice
+		}
+		facets.PriceRanges = append(facets.PriceRanges, priceFacet)
+	}
+
+	var ratingBuckets []struct {
+		Rating int
+		Count  int64
+	}
+	err = r.db.Table("products").
+		Select("floor(product_analytics.avg_rating)::int as rating, count(*) as count").
+		Joins("join product_analytics on product_analytics.id = products.product_analytic_id").
+		Where("products.id in (?)", subQueryProductIds).
+		Group("rating").
+		Scan(&ratingBuckets).Error
+	if err != nil {
+		return nil, domain.ErrGetProductFacets
+	}
+	// ratings are counted as "n stars and up" to match the min_rating filter
+	for minRating := 5; minRating >= 1; minRating-- {
+		var count int64
+		for _, ratingBucket := range ratingBuckets {
+			if ratingBucket.Rating >= minRating {
+				count += ratingBucket.Count
+			}
+		}
+		facets.Ratings = append(facets.Ratings, dto.ProductRatingFacetDTO{MinRating: minRating, Count: count})
+	}
+
+	return &facets, nil
+}
+
+func (r *productRepositoryImpl) GetProductSearchHighlights(search string, productIdList []uint) (map[uint]string, error) {
+	highlights := make(map[uint]string)
+	if search == "" || len(productIdList) == 0 {
+		return highlights, nil
+	}
+
+	var rows []struct {
+		ID        uint
+		Highlight string
+	}
+	// the text is merchant input, escape it so only the <mark> tags stay html
+	escapedText := `replace(replace(replace(replace(replace(title || ' ' || coalesce(description, ''), '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+	err := r.db.Table("products").
+		Select("id, ts_headline("+escapedText+", plainto_tsquery(?), ?) as highlight", search, dto.PRODUCT_SEARCH_HEADLINE_OPTIONS).
+		Where("id in ?", productIdList).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, domain.ErrGetProductHighlights
+	}
+
+	for _, row := range rows {
+		highlights[row.ID] = row.Highlight
+	}
+
+	return highlights, nil
+}
+
 func (r *productRepositoryImpl) GetRecommendationProductList(req dto.PaginationRequest) ([]entity.Product, int64, error) {
 	var products []entity.Product
 	var total int64
@@ -182,6 +335,45 @@ func (r *productRepositoryImpl) GetRecommendationProductList(req dto.PaginationR
 	return products, total, nil
 }
 
+// GetRecommendationProductIds returns the ids of the global recommendation list
+// in the same order as GetRecommendationProductList.
+func (r *productRepositoryImpl) GetRecommendationProductIds(limit int) ([]uint, error) {
+	var productIdList []uint
+	err := r.db.Table("products as prod").
+		Joins("join product_analytics pa on pa.id = prod.product_analytic_id").
+		Where("prod.deleted_at is null").
+		Where("prod.is_archived = ?", false).
+		Order("pa.avg_rating desc").
+		Limit(limit).
+		Pluck("prod.id", &productIdList).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return productIdList, nil
+}
+
+func (r *productRepositoryImpl) GetProductListByIds(productIdList []uint) ([]entity.Product, error) {
+	var products []entity.Product
+	if len(productIdList) == 0 {
+		return products, nil
+	}
+
+	res := r.getBaseProductQuery().
+		Select("prod.*, min_discounted_price, max_discounted_price").
+		Where("prod.deleted_at is null").
+		Where("prod.id = cte.id").
+		Where("prod.product_analytic_id = ProductAnalytic.id").
+		Where("is_archived = ?", false).
+		Where("prod.id in ?", productIdList).
+		Find(&products)
+	if res.Error != nil {
+		return nil, domain.ErrGetProducts
+	}
+
+	return products, nil
+}
+
 func (r *productRepositoryImpl) GetProductBySlug(slug string) (*entity.Product, error) {
 	var product entity.Product
 	res := r.db.Where("slug = ?", slug).First(&product)
@@ -372,6 +564,11 @@ func (r *productRepositoryImpl) IncreaseProductStockTx(tx *gorm.DB, productId ui
 		return err
 	}
 
+	err = r.backInStockSubscriptionRepository.TriggerRestockedTx(tx, productId)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -768,7 +965,8 @@ func (r *productRepositoryImpl) UpdateMerchantProduct(product *entity.Product, r
 			)
 			return maskedErr
 		}
-		return nil
+
+		return r.backInStockSubscriptionRepository.TriggerRestockedTx(tx, product.ID)
 	})
 
 	if err != nil {
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductStockAlertRepository interface {
+	UpsertThreshold(productId uint, threshold int) (*entity.ProductStockAlert, error)
+	GetLowStockProducts(merchantDomain string, req dto.PaginationRequest) ([]dto.LowStockProductDTO, int64, error)
+	GetUnalertedLowStockProducts(limit int) ([]dto.LowStockProductAlertDTO, error)
+	MarkLowStockAlerted(notifications map[uint]entity.Notification) ([]uint, error)
+	ResetRecoveredProducts() error
+}
+
+type ProductStockAlertRepositoryConfig struct {
+	DB                     *gorm.DB
+	NotificationRepository NotificationRepository
+}
+
+type productStockAlertRepositoryImpl struct {
+	db                     *gorm.DB
+	notificationRepository NotificationRepository
+}
+
+func NewProductStockAlertRepository(c ProductStockAlertRepositoryConfig) ProductStockAlertRepository {
+	return &productStockAlertRepositoryImpl{
+		db:                     c.DB,
+		notificationRepository: c.NotificationRepository,
+	}
+}
+
+// lowStockQuery selects active products whose lowest variant stock is at or
+// below the product threshold, falling back to the default threshold.
+func (r *productStockAlertRepositoryImpl) lowStockQuery() *gorm.DB {
+	variantStock := r.db.Table("variant_items").
+		Select("product_id, MIN(stock) AS min_stock").
+		Where("deleted_at IS NULL").
+		Group("product_id")
+
+	return r.db.Table("products p").
+		Joins("JOIN product_analytics pa ON pa.id = p.product_analytic_id").
+		Joins("JOIN (?) vs ON vs.product_id = p.id", variantStock).
+		Joins("LEFT JOIN product_stock_alerts psa ON psa.product_id = p.id").
+		Where("p.deleted_at IS NULL").
+		Where("p.is_archived = ?", false).
+		Where("vs.min_stock <= COALESCE(psa.threshold, ?)", dto.PRODUCT_LOW_STOCK_DEFAULT_THRESHOLD)
+}
+
+func (r *productStockAlertRepositoryImpl) UpsertThreshold(productId uint, threshold int) (*entity.ProductStockAlert, error) {
+	alert := entity.ProductStockAlert{
+		ProductId: productId,
+		Threshold: threshold,
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"threshold", "updated_at"}),
+	}).Create(&alert).Error
+	if err != nil {
+		return nil, domain.ErrUpdateLowStockThreshold
+	}
+
+	err = r.db.Where("product_id = ?", productId).First(&alert).Error
+	if err != nil {
+		return nil, domain.ErrUpdateLowStockThreshold
+	}
+
+	return &alert, nil
+}
+
+func (r *productStockAlertRepositoryImpl) GetLowStockProducts(merchantDomain string, req dto.PaginationRequest) ([]dto.LowStockProductDTO, int64, error) {
+	var products []dto.LowStockProductDTO
+	var totalData int64
+
+	query := r.lowStockQuery().
+		Where("p.merchant_domain = ?", merchantDomain).
+		Session(&gorm.Session{})
+
+	err := query.Count(&totalData).Error
+	if err != nil {
+		return nil, 0, domain.ErrGetLowStockProducts
+	}
+
+	err = query.
+		Select("p.id, p.title, p.slug, pa.total_stock, vs.min_stock, COALESCE(psa.threshold, ?) AS threshold", dto.PRODUCT_LOW_STOCK_DEFAULT_THRESHOLD).
+		Order("vs.min_stock ASC, p.id ASC").
+		Limit(req.Limit).
+		Offset((req.Page - 1) * req.Limit).
+		Scan(&products).Error
+	if err != nil {
+		return nil, 0, domain.ErrGetLowStockProducts
+	}
+
+	return products, totalData, nil
+}
+
+func (r *productStockAlertRepositoryImpl) GetUnalertedLowStockProducts(limit int) ([]dto.LowStockProductAlertDTO, error) {
+	var products []dto.LowStockProductAlertDTO
+
+	err := r.lowStockQuery().
+		Joins("JOIN merchants m ON m.id = p.merchant_id").
+		Joins("JOIN users u ON u.id = m.user_id").
+		Where("COALESCE(psa.is_low_stock, false) = ?", false).
+		Select("p.id, p.title, p.slug, pa.total_stock, vs.min_stock, COALESCE(psa.threshold, ?) AS threshold, "+
+			"m.user_id AS merchant_user_id, m.name AS merchant_name, u.email AS merchant_email", dto.PRODUCT_LOW_STOCK_DEFAULT_THRESHOLD).
+		Order("m.user_id ASC, vs.min_stock ASC").
+		Limit(limit).
+		Scan(&products).Error
+	if err != nil {
+		return nil, domain.ErrGetLowStockProducts
+	}
+
+	return products, nil
+}
+
+// MarkLowStockAlerted flags the products keyed in notifications as alerted and
+// stores their notification in the same transaction. Products another run
+// already alerted are skipped, the ones marked by this call are returned.
+func (r *productStockAlertRepositoryImpl) MarkLowStockAlerted(notifications map[uint]entity.Notification) (alertedIds []uint, markErr error) {
+	if len(notifications) == 0 {
+		return nil, nil
+	}
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			alertedIds = nil
+			markErr = domain.ErrUpdateLowStockState
+		}
+	}()
+
+	productIds := make([]uint, 0, len(notifications))
+	for productId := range notifications {
+		productIds = append(productIds, productId)
+	}
+
+	timeNow := time.Now()
+	err := tx.Raw(`INSERT INTO product_stock_alerts (product_id, threshold, is_low_stock, alerted_at, created_at, updated_at)
+		SELECT p.id, ?, true, ?, ?, ? FROM products p WHERE p.id IN ?
+		ON CONFLICT (product_id) DO UPDATE SET is_low_stock = true, alerted_at = EXCLUDED.alerted_at, updated_at = EXCLUDED.updated_at
+		WHERE product_stock_alerts.is_low_stock = false
+		RETURNING product_id`, dto.PRODUCT_LOW_STOCK_DEFAULT_THRESHOLD, timeNow, timeNow, timeNow, productIds).
+		Scan(&alertedIds).
+		Error
+	if err != nil {
+		tx.Rollback()
+		return nil, domain.ErrUpdateLowStockState
+	}
+
+	var alertNotifications []entity.Notification
+	for _, productId := range alertedIds {
+		alertNotifications = append(alertNotifications, notifications[productId])
+	}
+
+	err = r.notificationRepository.CreateTx(tx, alertNotifications)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return nil, domain.ErrUpdateLowStockState
+	}
+
+	return alertedIds, nil
+}
+
+// ResetRecoveredProducts re-arms the alert of products restocked above their threshold.
+func (r *productStockAlertRepositoryImpl) ResetRecoveredProducts() error {
+	err := r.db.Exec(`
+		UPDATE product_stock_alerts psa
+		SET is_low_stock = false, updated_at = now()
+		WHERE psa.is_low_stock = true
+		AND NOT EXISTS (
+			SELECT 1 FROM variant_items vi
+			WHERE vi.product_id = psa.product_id
+			AND vi.deleted_at IS NULL
+			AND vi.stock <= psa.threshold
+		)`).Error
+	if err != nil {
+		return domain.ErrUpdateLowStockState
+	}
+
+	return nil
+}
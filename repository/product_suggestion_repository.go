@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cache"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+type ProductSuggestionRepository interface {
+	GetSuggestions(query string, limit int) (*dto.ProductSuggestionResDTO, error)
+	GetDidYouMean(search string) (string, error)
+}
+
+type ProductSuggestionRepositoryConfig struct {
+	DB  *gorm.DB
+	RDB *cache.RDBConnection
+}
+
+type productSuggestionRepositoryImpl struct {
+	db  *gorm.DB
+	rdb *cache.RDBConnection
+}
+
+func NewProductSuggestionRepository(c ProductSuggestionRepositoryConfig) ProductSuggestionRepository {
+	return &productSuggestionRepositoryImpl{
+		db:  c.DB,
+		rdb: c.RDB,
+	}
+}
+
+func (r *productSuggestionRepositoryImpl) GetSuggestions(query string, limit int) (*dto.ProductSuggestionResDTO, error) {
+	prefix := strings.ToLower(strings.TrimSpace(query))
+	cacheKey := fmt.Sprintf("product-suggestion:%d:%s", limit, prefix)
+
+	var suggestions dto.ProductSuggestionResDTO
+	err := r.rdb.GetCache(cacheKey, &suggestions)
+	if err == nil {
+		return &suggestions, nil
+	}
+
+	suggestions = dto.ProductSuggestionResDTO{
+		Products:   make([]dto.ProductTitleSuggestionDTO, 0),
+		Categories: make([]dto.CategorySuggestionDTO, 0),
+		Merchants:  make([]dto.MerchantSuggestionDTO, 0),
+	}
+	// escape LIKE wildcards so they are matched literally
+	likePrefix := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+
+	err = r.db.Table("products").
+		Select("products.title, products.slug, products.merchant_domain").
+		Joins("join product_analytics on product_analytics.id = products.product_analytic_id").
+		Where("lower(products.title) like ?", likePrefix).
+		Where("products.is_archived = ?", false).
+		Where("products.deleted_at is null").
+		Order("product_analytics.num_of_sale desc, product_analytics.score desc").
+		Limit(limit).
+		Scan(&suggestions.Products).Error
+	if err != nil {
+		return nil, domain.ErrGetProductSuggestions
+	}
+
+	err = r.db.Table("categories").
+		Select("categories.name, categories.slug").
+		Joins("left join products on products.category_id = categories.id and products.deleted_at is null").
+		Joins("left join product_analytics on product_analytics.id = products.product_analytic_id").
+		Where("lower(categories.name) like ?", likePrefix).
+		Where("categories.deleted_at is null").
+		Group("categories.id, categories.name, categories.slug").
+		Order("coalesce(sum(product_analytics.num_of_sale), 0) desc, coalesce(sum(product_analytics.score), 0) desc").
+		Limit(limit).
+		Scan(&suggestions.Categories).Error
+	if err != nil {
+		return nil, domain.ErrGetProductSuggestions
+	}
+
+	err = r.db.Table("merchants").
+		Select("merchants.name, merchants.domain").
+		Joins("left join products on products.merchant_domain = merchants.domain and products.deleted_at is null").
+		Joins("left join product_analytics on product_analytics.id = products.product_analytic_id").
+		Where("lower(merchants.name) like ?", likePrefix).
+		Where("merchants.deleted_at is null").
+		Group("merchants.id, merchants.name, merchants.domain").
+		Order("coalesce(sum(product_analytics.num_of_sale), 0) desc, coalesce(sum(product_analytics.score), 0) desc").
+		Limit(limit).
+		Scan(&suggestions.Merchants).Error
+	if err != nil {
+		return nil, domain.ErrGetProductSuggestions
+	}
+
+	err = r.rdb.SetCache(cacheKey, suggestions, dto.PRODUCT_SUGGESTION_CACHE_MINUTES)
+	if err != nil {
+		log.Error().Msgf("error: cache product suggestions %s: %v", cacheKey, err)
+	}
+
+	return &suggestions, nil
+}
+
+// GetDidYouMean returns the product title or category name closest to the
+// search term, or an empty string when nothing is similar enough.
+func (r *productSuggestionRepositoryImpl) GetDidYouMean(search string) (string, error) {
+	var correction struct {
+		Term       string
+		Similarity float64
+	}
+	err := r.db.Raw(`
+		select term, similarity from (
+			select title as term, similarity(lower(title), lower(@search)) as similarity
+			from products
+			where deleted_at is null and is_archived = false and lower(title) % lower(@search)
+			union all
+			select name as term, similarity(lower(name), lower(@search)) as similarity
+			from categories
+			where deleted_at is null and lower(name) % lower(@search)
+		) as candidates
+		order by similarity desc
+		limit 1
+	`, map[string]interface{}{"search": search}).Scan(&correction).Error
+	if err != nil {
+		return "", domain.ErrGetProductSuggestions
+	}
+
+	if correction.Similarity < dto.PRODUCT_DID_YOU_MEAN_MIN_SIMILARITY || strings.EqualFold(correction.Term, search) {
+		return "", nil
+	}
+
+	return correction.Term, nil
+}
@@ -664,8 +664,8 @@ func (r *refundRequestRepositoryImpl) countAmountAndPromotionTrx(transaction ent
 		return 0, 0, domain.ErrUnmarshalJSONPaymentDetails
 	}
 
-	amount = transactionPaymentDetails.Subtotal + transactionPaymentDetails.DeliveryFee - transactionPaymentDetails.MerchantVoucherNominal
-	promotionMarketplace = transactionPaymentDetails.MarketplaceVoucherNominal
+	amount = transactionPaymentDetails.PaidAmount()
+	promotionMarketplace = transactionPaymentDetails.MarketplaceSubsidy()
 
 	return amount, promotionMarketplace, nil
 }
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SealabspayCallbackRepository interface {
+	Create(input entity.SealabspayCallback) (*entity.SealabspayCallback, error)
+	Update(input entity.SealabspayCallback) (*entity.SealabspayCallback, error)
+	GetById(callbackId uint) (*entity.SealabspayCallback, error)
+	GetList(req dto.SealabspayCallbackListReqParamDTO) ([]entity.SealabspayCallback, int64, error)
+	GetDueForRetry(limit int) ([]entity.SealabspayCallback, error)
+	Claim(callback *entity.SealabspayCallback, lease time.Duration) (bool, error)
+	ResetForReplay(callback *entity.SealabspayCallback) (bool, error)
+}
+
+type SealabspayCallbackRepositoryConfig struct {
+	DB *gorm.DB
+}
+
+type sealabspayCallbackRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewSealabspayCallbackRepository(c SealabspayCallbackRepositoryConfig) SealabspayCallbackRepository {
+	return &sealabspayCallbackRepositoryImpl{
+		db: c.DB,
+	}
+}
+
+// Create stores the callback in the inbox. A callback with an already stored
+// TxnId is not inserted again, the existing row is returned instead.
+func (r *sealabspayCallbackRepositoryImpl) Create(input entity.SealabspayCallback) (*entity.SealabspayCallback, error) {
+	res := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "txn_id"}},
+		DoNothing: true,
+	}).Create(&input)
+	if res.Error != nil {
+		log.Error().Msgf("error: create sealabspay callback %s: %v", input.TxnId, res.Error)
+		return nil, domain.ErrCreateSealabspayCallback
+	}
+
+	if res.RowsAffected == 0 {
+		var existing entity.SealabspayCallback
+		err := r.db.Where("txn_id = ?", input.TxnId).First(&existing).Error
+		if err != nil {
+			return nil, domain.ErrGetSealabspayCallback
+		}
+
+		return &existing, nil
+	}
+
+	return &input, nil
+}
+
+func (r *sealabspayCallbackRepositoryImpl) Update(input entity.SealabspayCallback) (*entity.SealabspayCallback, error) {
+	err := r.db.Save(&input).Error
+	if err != nil {
+		log.Error().Msgf("error: update sealabspay callback %d: %v", input.ID, err)
+		return nil, domain.ErrUpdateSealabspayCallback
+	}
+
+	return &input, nil
+}
+
+func (r *sealabspayCallbackRepositoryImpl) GetById(callbackId uint) (*entity.SealabspayCallback, error) {
+	var callback entity.SealabspayCallback
+	err := r.db.Where("id = ?", callbackId).First(&callback).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrSealabspayCallbackNotFound
+		}
+		return nil, domain.ErrGetSealabspayCallback
+	}
+
+	return &callback, nil
+}
+
+func (r *sealabspayCallbackRepositoryImpl) GetList(req dto.SealabspayCallbackListReqParamDTO) ([]entity.SealabspayCallback, int64, error) {
+	var callbacks []entity.SealabspayCallback
+	var count int64
+	pageOffset := req.Limit * (req.Page - 1)
+
+	query := r.db.Model(&entity.SealabspayCallback{})
+	if req.Status != "" {
+		query = query.Where("process_status = ?", req.Status)
+	} else {
+		query = query.Where("process_status != ?", dto.SLP_CALLBACK_STATUS_PROCESSED)
+	}
+
+	err := query.Count(&count).Error
+	if err != nil {
+		return nil, 0, domain.ErrGetSealabspayCallback
+	}
+
+	err = query.
+		Limit(req.Limit).
+		Offset(pageOffset).
+		Order("created_at DESC").
+		Find(&callbacks).
+		Error
+	if err != nil {
+		return nil, 0, domain.ErrGetSealabspayCallback
+	}
+
+	return callbacks, count, nil
+}
+
+func (r *sealabspayCallbackRepositoryImpl) GetDueForRetry(limit int) ([]entity.SealabspayCallback, error) {
+	var callbacks []entity.SealabspayCallback
+	err := r.db.
+		Where("process_status = ?", dto.SLP_CALLBACK_STATUS_PENDING).
+		Where("next_attempt_at IS NULL OR next_attempt_at <= ?", time.Now()).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&callbacks).
+		Error
+	if err != nil {
+		return nil, domain.ErrGetSealabspayCallback
+	}
+
+	return callbacks, nil
+}
+
+// Claim marks a pending callback as being processed so the same callback is
+// not handled concurrently by the callback request and the retry worker.
+func (r *sealabspayCallbackRepositoryImpl) Claim(callback *entity.SealabspayCallback, lease time.Duration) (bool, error) {
+	nextAttemptAt := time.Now().Add(lease)
+	res := r.db.Model(&entity.SealabspayCallback{}).
+		Where("id = ? AND attempts = ? AND process_status = ?", callback.ID, callback.Attempts, dto.SLP_CALLBACK_STATUS_PENDING).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": nextAttemptAt,
+		})
+	if res.Error != nil {
+		log.Error().Msgf("error: claim sealabspay callback %d: %v", callback.ID, res.Error)
+		return false, domain.ErrUpdateSealabspayCallback
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+
+	callback.Attempts++
+	callback.NextAttemptAt = &nextAttemptAt
+	return true, nil
+}
+
+// ResetForReplay puts a callback back to pending with fresh attempts, unless
+// it changed since it was read or a worker holds its claim lease.
+func (r *sealabspayCallbackRepositoryImpl) ResetForReplay(callback *entity.SealabspayCallback) (bool, error) {
+	res := r.db.Model(&entity.SealabspayCallback{}).
+		Where("id = ? AND attempts = ? AND process_status = ?", callback.ID, callback.Attempts, callback.ProcessStatus).
+		Where("process_status != ?", dto.SLP_CALLBACK_STATUS_PROCESSED).
+		Where("next_attempt_at IS NULL OR next_attempt_at <= ?", time.Now()).
+		Updates(map[string]interface{}{
+			"process_status":  dto.SLP_CALLBACK_STATUS_PENDING,
+			"attempts":        0,
+			"next_attempt_at": nil,
+		})
+	if res.Error != nil {
+		log.Error().Msgf("error: reset sealabspay callback %d: %v", callback.ID, res.Error)
+		return false, domain.ErrUpdateSealabspayCallback
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+
+	callback.ProcessStatus = dto.SLP_CALLBACK_STATUS_PENDING
+	callback.Attempts = 0
+	callback.NextAttemptAt = nil
+	return true, nil
+}
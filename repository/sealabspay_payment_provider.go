@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+)
+
+type SealabspayPaymentProviderConfig struct {
+	SealabspayRepository SealabspayRepository
+}
+
+type sealabspayPaymentProviderImpl struct {
+	sealabspayRepository SealabspayRepository
+}
+
+func NewSealabspayPaymentProvider(c SealabspayPaymentProviderConfig) PaymentProvider {
+	return &sealabspayPaymentProviderImpl{
+		sealabspayRepository: c.SealabspayRepository,
+	}
+}
+
+func (p *sealabspayPaymentProviderImpl) Code() string {
+	return dto.PAYMENT_METHOD_CODE_SLP
+}
+
+func (p *sealabspayPaymentProviderImpl) CreateCharge(req dto.PaymentChargeReqDTO) (*dto.PaymentChargeResDTO, error) {
+	var redirectUrl, paymentId string
+	var err error
+	if req.RedirectUrl != "" {
+		redirectUrl, paymentId, err = p.sealabspayRepository.MakePaymentCustomRedirect(req.AccountNumber, req.Amount, req.RedirectUrl)
+	} else {
+		redirectUrl, paymentId, err = p.sealabspayRepository.MakePayment(req.AccountNumber, req.Amount)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PaymentChargeResDTO{
+		RedirectUrl: redirectUrl,
+		PaymentId:   paymentId,
+	}, nil
+}
+
+func (p *sealabspayPaymentProviderImpl) VerifyCallback(req dto.PaymentCallbackReqDTO) (*dto.PaymentCallbackResDTO, error) {
+	err := util.ValidateResSlpSignature(dto.SealabspayReqDTO{
+		Amount:       req.Amount,
+		MerchantCode: req.MerchantCode,
+		Message:      req.Message,
+		Signature:    req.Signature,
+		Status:       req.Status,
+		TxnId:        req.PaymentId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	txnId, err := strconv.ParseUint(req.PaymentId, 10, 64)
+	if err != nil {
+		return nil, domain.ErrSealabspayTxnIdNotValid
+	}
+
+	amount, err := strconv.ParseUint(req.Amount, 10, 64)
+	if err != nil {
+		return nil, domain.ErrSealabspayAmountNotValid
+	}
+
+	if req.Status != dto.SLP_SUCCESS_CODE && req.Status != dto.SLP_FAILED_CODE {
+		return nil, domain.ErrPaymentProviderInvalidCallbackStatus
+	}
+
+	return &dto.PaymentCallbackResDTO{
+		PaymentId: fmt.Sprintf("SLP%d", txnId),
+		Amount:    uint(amount),
+		IsSuccess: req.Status == dto.SLP_SUCCESS_CODE,
+	}, nil
+}
+
+// Cancel is not offered by SeaLabsPay, an unpaid charge simply expires.
+func (p *sealabspayPaymentProviderImpl) Cancel(paymentId string) error {
+	return nil
+}
+
+// Refund is not offered by SeaLabsPay, refunds are credited to the buyer wallet instead.
+func (p *sealabspayPaymentProviderImpl) Refund(paymentId string, amount uint) error {
+	return domain.ErrPaymentProviderOperationNotSupported
+}
+
+func (p *sealabspayPaymentProviderImpl) QueryStatus(paymentId string) (*dto.PaymentStatusResDTO, error) {
+	if len(paymentId) <= len(dto.PAYMENT_METHOD_CODE_SLP) {
+		return nil, domain.ErrSealabspayTxnIdNotValid
+	}
+
+	txnId := paymentId[len(dto.PAYMENT_METHOD_CODE_SLP):]
+	res, err := p.sealabspayRepository.GetPaymentStatus(txnId)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := strconv.ParseUint(res.Amount, 10, 64)
+	if err != nil {
+		return nil, domain.ErrSealabspayAmountNotValid
+	}
+
+	status := dto.PAYMENT_STATUS_PENDING
+	if res.Status == dto.SLP_SUCCESS_CODE {
+		status = dto.PAYMENT_STATUS_PAID
+	}
+	if res.Status == dto.SLP_FAILED_CODE {
+		status = dto.PAYMENT_STATUS_FAILED
+	}
+
+	return &dto.PaymentStatusResDTO{
+		PaymentId: paymentId,
+		Amount:    uint(amount),
+		Status:    status,
+	}, nil
+}
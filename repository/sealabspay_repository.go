@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/config"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
@@ -18,20 +19,40 @@ import (
 type SealabspayRepository interface {
 	MakePayment(cardNumber string, amount uint) (redirectUrl string, paymentId string, slpError error)
 	MakePaymentCustomRedirect(cardNumber string, amount uint, redirectPaymentUrl string) (redirectUrl string, paymentId string, slpError error)
+	GetPaymentStatus(txnId string) (*dto.SealabspayStatusDataDTO, error)
 }
 
+// SealabspayRepositoryConfig allows replacing the HTTP client and base url,
+// e.g. to point the repository at a local stub server.
 type SealabspayRepositoryConfig struct {
+	HttpClient *http.Client
+	Url        string
 }
 
 type SealabspayRepositoryImpl struct {
+	httpClient *http.Client
+	url        string
 }
 
 func NewSealabspayRepository(c SealabspayRepositoryConfig) SealabspayRepository {
-	return &SealabspayRepositoryImpl{}
+	httpClient := c.HttpClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	baseUrl := c.Url
+	if baseUrl == "" {
+		baseUrl = config.Config.SeaLabsPayConfig.Url
+	}
+
+	return &SealabspayRepositoryImpl{
+		httpClient: httpClient,
+		url:        baseUrl,
+	}
 }
 
 func (r *SealabspayRepositoryImpl) MakePaymentCustomRedirect(cardNumber string, amount uint, redirectPaymentUrl string) (redirectUrl string, paymentId string, slpError error) {
-	apiUrl := config.Config.SeaLabsPayConfig.Url + "/transaction/pay"
+	apiUrl := r.url + "/transaction/pay"
 	merchantCode := config.Config.SeaLabsPayConfig.MerchantCode
 	signature, err := util.GenerateReqSlpSignature(cardNumber, amount, merchantCode)
 	if err != nil {
@@ -48,7 +69,7 @@ func (r *SealabspayRepositoryImpl) MakePaymentCustomRedirect(cardNumber string,
 
 	urlStr := apiUrl
 
-	client := &http.Client{}
+	client := *r.httpClient
 	rHttp, _ := http.NewRequest(http.MethodPost, urlStr, strings.NewReader(data.Encode()))
 	rHttp.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
@@ -93,3 +114,34 @@ func (r *SealabspayRepositoryImpl) MakePaymentCustomRedirect(cardNumber string,
 func (r *SealabspayRepositoryImpl) MakePayment(cardNumber string, amount uint) (redirectUrl string, paymentId string, slpError error) {
 	return r.MakePaymentCustomRedirect(cardNumber, amount, config.Config.SeaLabsPayConfig.RedirectUrl)
 }
+
+func (r *SealabspayRepositoryImpl) GetPaymentStatus(txnId string) (*dto.SealabspayStatusDataDTO, error) {
+	merchantCode := config.Config.SeaLabsPayConfig.MerchantCode
+	signature, err := util.GenerateStatusSlpSignature(txnId, merchantCode)
+	if err != nil {
+		return nil, domain.ErrSlpCannotGenerateSignature
+	}
+
+	data := url.Values{}
+	data.Set("merchant_code", merchantCode)
+	data.Set("signature", signature)
+	urlStr := fmt.Sprintf("%s/transaction/%s?%s", r.url, url.PathEscape(txnId), data.Encode())
+
+	response, err := r.httpClient.Get(urlStr)
+	if err != nil {
+		return nil, domain.ErrSlpRequest
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, domain.ErrPaymentProviderQueryStatus
+	}
+
+	slpResDTO := dto.SealabspayStatusResDTO{}
+	err = json.NewDecoder(response.Body).Decode(&slpResDTO)
+	if err != nil {
+		return nil, domain.ErrPaymentProviderQueryStatus
+	}
+
+	return &slpResDTO.Data, nil
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockReservationRepository interface {
+	ReserveStockTx(tx *gorm.DB, orderCode string, orderItems []entity.OrderItem, expiresAt time.Time) error
+	ConsumeReservationTx(tx *gorm.DB, orderCode string) error
+	ReleaseUserReservationsTx(tx *gorm.DB, userId uint) error
+	CheckAvailableStockTx(tx *gorm.DB, orderCode string, orderItems []entity.OrderItem) error
+	GetActiveReservationsByOrderCode(orderCode string) ([]entity.StockReservation, error)
+	GetReservedStock(variantItemIds []uint, excludeOrderCode string) (map[uint]uint, error)
+	GetReservedStockByOtherUsers(variantItemIds []uint, userId uint) (map[uint]uint, error)
+	ReleaseExpiredReservations() (int64, error)
+}
+
+type StockReservationRepositoryConfig struct {
+	DB *gorm.DB
+}
+
+type stockReservationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewStockReservationRepository(c StockReservationRepositoryConfig) StockReservationRepository {
+	return &stockReservationRepositoryImpl{
+		db: c.DB,
+	}
+}
+
+func (r *stockReservationRepositoryImpl) activeReservationQuery(db *gorm.DB) *gorm.DB {
+	return db.Model(&entity.StockReservation{}).
+		Where("consumed_at IS NULL").
+		Where("released_at IS NULL").
+		Where("expires_at > ?", time.Now())
+}
+
+// ReserveStockTx holds stock of every order item for orderCode. Variant rows are
+// locked in id order so concurrent checkouts of the same variants serialize
+// instead of deadlocking.
+func (r *stockReservationRepositoryImpl) ReserveStockTx(tx *gorm.DB, orderCode string, orderItems []entity.OrderItem, expiresAt time.Time) error {
+	// lines sharing a variant are checked against its stock together
+	err := r.CheckAvailableStockTx(tx, orderCode, orderItems)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range orderItems {
+		err = tx.Create(&entity.StockReservation{
+			OrderCode:     orderCode,
+			ProductId:     item.ProductId,
+			VariantItemId: item.VariantItemId,
+			Quantity:      item.Quantity,
+			ExpiresAt:     expiresAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *stockReservationRepositoryImpl) lockAvailableStockTx(tx *gorm.DB, orderCode string, productId uint, variantItemId uint) (uint, error) {
+	var variantItem entity.VariantItem
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", variantItemId).
+		Where("product_id = ?", productId).
+		First(&variantItem).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, domain.ErrOrderProductNotAvailable
+		}
+		return 0, err
+	}
+
+	var reserved uint
+	err = r.activeReservationQuery(tx).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("variant_item_id = ?", variantItemId).
+		Where("order_code <> ?", orderCode).
+		Scan(&reserved).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if variantItem.Stock < reserved {
+		return 0, nil
+	}
+	return variantItem.Stock - reserved, nil
+}
+
+// CheckAvailableStockTx makes sure the stock not held by other orders still
+// covers orderItems. The order's own reservation may have expired or been
+// released, so this is checked again right before the stock is decreased.
+func (r *stockReservationRepositoryImpl) CheckAvailableStockTx(tx *gorm.DB, orderCode string, orderItems []entity.OrderItem) error {
+	var variantItemIds []uint
+	var productIdMap = make(map[uint]uint)
+	var quantityMap = make(map[uint]uint)
+	for _, item := range orderItems {
+		if _, ok := quantityMap[item.VariantItemId]; !ok {
+			variantItemIds = append(variantItemIds, item.VariantItemId)
+		}
+		productIdMap[item.VariantItemId] = item.ProductId
+		quantityMap[item.VariantItemId] += item.Quantity
+	}
+	sort.Slice(variantItemIds, func(i, j int) bool {
+		return variantItemIds[i] < variantItemIds[j]
+	})
+
+	for _, variantItemId := range variantItemIds {
+		available, err := r.lockAvailableStockTx(tx, orderCode, productIdMap[variantItemId], variantItemId)
+		if err != nil {
+			return err
+		}
+		if available < quantityMap[variantItemId] {
+			return domain.ErrStockReservationInsufficient
+		}
+	}
+
+	return nil
+}
+
+func (r *stockReservationRepositoryImpl) ConsumeReservationTx(tx *gorm.DB, orderCode string) error {
+	return tx.Model(&entity.StockReservation{}).
+		Where("order_code = ?", orderCode).
+		Where("consumed_at IS NULL").
+		Where("released_at IS NULL").
+		Update("consumed_at", time.Now()).Error
+}
+
+// ReleaseUserReservationsTx drops the holds of the user's earlier orders, a
+// user only keeps the stock of the latest checkout.
+func (r *stockReservationRepositoryImpl) ReleaseUserReservationsTx(tx *gorm.DB, userId uint) error {
+	userOrderCodes := tx.Model(&entity.UserOrder{}).
+		Select("order_code").
+		Where("user_id = ?", userId)
+
+	return tx.Model(&entity.StockReservation{}).
+		Where("consumed_at IS NULL").
+		Where("released_at IS NULL").
+		Where("order_code IN (?)", userOrderCodes).
+		Update("released_at", time.Now()).Error
+}
+
+func (r *stockReservationRepositoryImpl) GetActiveReservationsByOrderCode(orderCode string) ([]entity.StockReservation, error) {
+	var reservations []entity.StockReservation
+	err := r.activeReservationQuery(r.db).
+		Where("order_code = ?", orderCode).
+		Find(&reservations).Error
+	if err != nil {
+		return nil, domain.ErrGetStockReservation
+	}
+
+	return reservations, nil
+}
+
+// GetReservedStock returns the quantity held per variant item by active
+// reservations of orders other than excludeOrderCode.
+func (r *stockReservationRepositoryImpl) GetReservedStock(variantItemIds []uint, excludeOrderCode string) (map[uint]uint, error) {
+	return r.getReservedStock(variantItemIds, r.activeReservationQuery(r.db).
+		Where("order_code <> ?", excludeOrderCode))
+}
+
+// GetReservedStockByOtherUsers returns the quantity held per variant item by
+// active reservations of orders that don't belong to userId.
+func (r *stockReservationRepositoryImpl) GetReservedStockByOtherUsers(variantItemIds []uint, userId uint) (map[uint]uint, error) {
+	userOrderCodes := r.db.Model(&entity.UserOrder{}).
+		Select("order_code").
+		Where("user_id = ?", userId)
+
+	return r.getReservedStock(variantItemIds, r.activeReservationQuery(r.db).
+		Where("order_code NOT IN (?)", userOrderCodes))
+}
+
+func (r *stockReservationRepositoryImpl) getReservedStock(variantItemIds []uint, query *gorm.DB) (map[uint]uint, error) {
+	reservedStock := make(map[uint]uint)
+	if len(variantItemIds) == 0 {
+		return reservedStock, nil
+	}
+
+	var rows []struct {
+		VariantItemId uint
+		Quantity      uint
+	}
+	err := query.
+		Select("variant_item_id, SUM(quantity) AS quantity").
+		Where("variant_item_id IN ?", variantItemIds).
+		Group("variant_item_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, domain.ErrGetStockReservation
+	}
+
+	for _, row := range rows {
+		reservedStock[row.VariantItemId] = row.Quantity
+	}
+
+	return reservedStock, nil
+}
+
+func (r *stockReservationRepositoryImpl) ReleaseExpiredReservations() (int64, error) {
+	res := r.db.Model(&entity.StockReservation{}).
+		Where("consumed_at IS NULL").
+		Where("released_at IS NULL").
+		Where("expires_at <= ?", time.Now()).
+		Update("released_at", time.Now())
+	if res.Error != nil {
+		return 0, domain.ErrReleaseStockReservation
+	}
+
+	return res.RowsAffected, nil
+}
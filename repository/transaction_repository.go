@@ -8,6 +8,7 @@ import (
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 )
@@ -52,6 +53,7 @@ type TransactionRepository interface {
 
 	UpdateTransactionStatusCanceled(transaction entity.Transaction, amount float64, cartItems []entity.TransactionCartItem) (trxStatus *entity.TransactionStatus, cancelTrx error)
 	UpdateTransactionStatusCanceledTx(tx *gorm.DB, transaction entity.Transaction, amount float64, cartItems []entity.TransactionCartItem) (trxStatus *entity.TransactionStatus, cancelTrx error)
+	InvalidateVoucherQuotas(transactions []entity.Transaction)
 	UpdateTransactionStatusCompleted(transaction entity.Transaction, amount float64, amountPromotionMarketplace float64) (trsStatus *entity.TransactionStatus, cancelTrx error)
 	UpdateTransactionStatusCompletedTx(tx *gorm.DB, transaction entity.Transaction, amount float64, amountPromotionMarketplace float64) (trsStatus *entity.TransactionStatus, cancelTrx error)
 	UpdateTransactionStatusRefundedTx(tx *gorm.DB, transaction entity.Transaction, amount float64, cartItems []entity.TransactionCartItem) (trsStatus *entity.TransactionStatus, cancelTrx error)
@@ -59,7 +61,7 @@ type TransactionRepository interface {
 
 type transactionRepositoryImpl struct {
 	db                                      *gorm.DB
-	marketplaceVoucherRepository            MarketplaceVoucherRepository
+	voucherRedemptionRepository             VoucherRedemptionRepository
 	merchantRepository                      MerchantRepository
 	productRepository                       ProductRepository
 	paymentRecordRepository                 PaymentRecordRepository
@@ -69,11 +71,13 @@ type transactionRepositoryImpl struct {
 	walletRepository                        WalletRepository
 	merchantHoldingAccountRepository        MerchantHoldingAccountRepository
 	merchantHoldingAccountHistoryRepository MerchantHoldingAccountHistoryRepository
+	ledgerRepository                        LedgerRepository
+	stockReservationRepository              StockReservationRepository
 }
 
 type TransactionRepositoryConfig struct {
 	DB                                      *gorm.DB
-	MarketplaceVoucherRepository            MarketplaceVoucherRepository
+	VoucherRedemptionRepository             VoucherRedemptionRepository
 	MerchantRepository                      MerchantRepository
 	ProductRepository                       ProductRepository
 	PaymentRecordRepository                 PaymentRecordRepository
@@ -83,12 +87,14 @@ type TransactionRepositoryConfig struct {
 	WalletRepository                        WalletRepository
 	MerchantHoldingAccountRepository        MerchantHoldingAccountRepository
 	MerchantHoldingAccountHistoryRepository MerchantHoldingAccountHistoryRepository
+	LedgerRepository                        LedgerRepository
+	StockReservationRepository              StockReservationRepository
 }
 
 func NewTransactionRepository(c TransactionRepositoryConfig) TransactionRepository {
 	return &transactionRepositoryImpl{
 		db:                                      c.DB,
-		marketplaceVoucherRepository:            c.MarketplaceVoucherRepository,
+		voucherRedemptionRepository:             c.VoucherRedemptionRepository,
 		merchantRepository:                      c.MerchantRepository,
 		productRepository:                       c.ProductRepository,
 		paymentRecordRepository:                 c.PaymentRecordRepository,
@@ -98,6 +104,8 @@ func NewTransactionRepository(c TransactionRepositoryConfig) TransactionReposito
 		walletRepository:                        c.WalletRepository,
 		merchantHoldingAccountRepository:        c.MerchantHoldingAccountRepository,
 		merchantHoldingAccountHistoryRepository: c.MerchantHoldingAccountHistoryRepository,
+		ledgerRepository:                        c.LedgerRepository,
+		stockReservationRepository:              c.StockReservationRepository,
 	}
 }
 
@@ -241,27 +249,51 @@ func (r *transactionRepositoryImpl) MakeTransaction(transactions []entity.Transa
 		return domain.ErrCreateTransaction
 	}
 
-	// decrease voucher marketplace quota
+	// redeem voucher marketplace once for the whole checkout
 	if transactions[0].MarketplaceVoucherId != nil {
-		err = r.marketplaceVoucherRepository.DecreaseMarketplaceVoucherQuotaTx(tx, *transactions[0].MarketplaceVoucherId)
+		err = r.voucherRedemptionRepository.RedeemMarketplaceVoucherTx(tx, *transactions[0].MarketplaceVoucherId, orderSummary.MarketplaceCodeId, transactions[0].UserId, paymentId)
 		if err != nil {
 			tx.Rollback()
-			log.Error().Msgf("Error decrease marketplace voucher quota: %v", err)
-			return domain.ErrCreateTransaction
+			log.Error().Msgf("Error redeem marketplace voucher: %v", err)
+			return err
 		}
 	}
 
-	// decrease product stock and product promotion and merchant voucher quota
-	for _, order := range orderSummary.Orders {
-		if order.MerchantVoucherId != nil {
-			err = r.merchantRepository.DecreaseMerchantVoucherQuotaTx(tx, order.Merchant.MerchantDomain, *order.MerchantVoucherId)
+	// redeem merchant vouchers
+	for i, transaction := range transactions {
+		if transaction.MerchantVoucherId != nil {
+			err = r.voucherRedemptionRepository.RedeemMerchantVoucherTx(tx, *transaction.MerchantVoucherId, orderSummary.Orders[i].MerchantCodeId, transaction.UserId, transaction.ID, paymentId)
 			if err != nil {
 				tx.Rollback()
-				log.Error().Msgf("Error decrease merchant voucher quota: %v", err)
-				return domain.ErrCreateTransaction
+				log.Error().Msgf("Error redeem merchant voucher: %v", err)
+				return err
 			}
 		}
+	}
+
+	// the order reservation may be gone by now, check the stock other orders don't hold
+	var orderItems []entity.OrderItem
+	for _, order := range orderSummary.Orders {
+		for _, item := range order.Items {
+			orderItems = append(orderItems, entity.OrderItem{
+				ProductId:     item.ProductId,
+				VariantItemId: *item.VariantItemId,
+				Quantity:      uint(item.Quantity),
+			})
+		}
+	}
+	err = r.stockReservationRepository.CheckAvailableStockTx(tx, orderSummary.OrderCode, orderItems)
+	if err != nil {
+		tx.Rollback()
+		if _, ok := err.(httperror.AppError); ok {
+			return err
+		}
+		log.Error().Msgf("Error check available stock: %v", err)
+		return domain.ErrCreateTransaction
+	}
 
+	// decrease product stock and product promotion
+	for _, order := range orderSummary.Orders {
 		//decrease stock and promotion
 		for _, item := range order.Items {
 			//decrease product stock
@@ -291,6 +323,14 @@ func (r *transactionRepositoryImpl) MakeTransaction(transactions []entity.Transa
 		}
 	}
 
+	// stock is now decreased, the order reservation no longer holds it
+	err = r.stockReservationRepository.ConsumeReservationTx(tx, orderSummary.OrderCode)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("Error consume stock reservation: %v", err)
+		return domain.ErrCreateTransaction
+	}
+
 	// update transaction payment record order code
 	err = r.transactionPaymentRecordRepository.UpdateRecordOrderCodebyPaymentIdTx(tx, orderSummary.OrderCode, paymentId)
 	if err != nil {
@@ -339,21 +379,24 @@ func (r *transactionRepositoryImpl) UpdateTransactionPaymentSuccess(transactions
 	trxIds := r.parseTransactionstoTransacionIds(transactions)
 	timeNow := time.Now()
 
-	//update transaction status
-	err := r.transactionStatusRepository.UpdateTransactionStatusWaitedAtTx(tx, trxIds, timeNow)
+	//update payment record status, only the first callback or reconcile run settles it
+	settled, err := r.paymentRecordRepository.MarkPaidTx(tx, paymentRec.PaymentId, timeNow)
 	if err != nil {
 		tx.Rollback()
-		log.Error().Msgf("Error update transaction status: %v", err)
-		return domain.ErrUpdateTransactionStatusPayment
+		log.Error().Msgf("Error update payment record status: %v", err)
+		return domain.ErrUpdateTransactionPayment
+	}
+	if !settled {
+		tx.Rollback()
+		return domain.ErrPaymentAlreadySettled
 	}
 
-	//update payment record status
-	paymentRec.PaidAt = &timeNow
-	_, err = r.paymentRecordRepository.UpdateTx(tx, paymentRec)
+	//update transaction status
+	err = r.transactionStatusRepository.UpdateTransactionStatusWaitedAtTx(tx, trxIds, timeNow)
 	if err != nil {
 		tx.Rollback()
-		log.Error().Msgf("Error update payment record status: %v", err)
-		return domain.ErrUpdateTransactionPayment
+		log.Error().Msgf("Error update transaction status: %v", err)
+		return domain.ErrUpdateTransactionStatusPayment
 	}
 
 	//update balance marketplace (add amount)
@@ -380,6 +423,14 @@ func (r *transactionRepositoryImpl) UpdateTransactionPaymentSuccess(transactions
 		}
 	}
 
+	//record payment in ledger
+	err = r.postPaymentLedgerTx(tx, transactions[0].UserId, paymentRec)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("Error post payment ledger entry: %v", err)
+		return domain.ErrUpdateTransactionPayment
+	}
+
 	err = tx.Commit().Error
 	if err != nil {
 		return domain.ErrUpdateTransactionPayment
@@ -403,8 +454,20 @@ func (r *transactionRepositoryImpl) UpdateTransactionPaymentFailed(transactions
 	timeNow := time.Now()
 	createdTransactionTime := transactions[0].CreatedAt
 
+	//update payment record status, only the first callback or reconcile run settles it
+	settled, err := r.paymentRecordRepository.MarkCanceledTx(tx, paymentRec.PaymentId, timeNow)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("Error update payment record status: %v", err)
+		return domain.ErrUpdateTransactionPayment
+	}
+	if !settled {
+		tx.Rollback()
+		return domain.ErrPaymentAlreadySettled
+	}
+
 	//delete transaction
-	err := tx.Where("id in ?", trxIds).Delete(&entity.Transaction{}).Error
+	err = tx.Where("id in ?", trxIds).Delete(&entity.Transaction{}).Error
 	if err != nil {
 		tx.Rollback()
 		log.Error().Msgf("Error delete transaction: %v", err)
@@ -427,37 +490,14 @@ func (r *transactionRepositoryImpl) UpdateTransactionPaymentFailed(transactions
 		return domain.ErrUpdateTransactionPayment
 	}
 
-	//update payment record status
-	paymentRec.CanceledAt = &timeNow
-	_, err = r.paymentRecordRepository.UpdateTx(tx, paymentRec)
+	//return marketplace and merchant vouchers if exist
+	err = r.voucherRedemptionRepository.ReleaseVouchersTx(tx, r.parseTransactionstoTransacionIds(transactions))
 	if err != nil {
 		tx.Rollback()
-		log.Error().Msgf("Error update payment record status: %v", err)
+		log.Error().Msgf("Error release vouchers: %v", err)
 		return domain.ErrUpdateTransactionPayment
 	}
 
-	//return marketplace voucher if exist
-	if transactions[0].MarketplaceVoucherId != nil {
-		err = r.marketplaceVoucherRepository.IncreaseMarketplaceVoucherQuotaTx(tx, *transactions[0].MarketplaceVoucherId)
-		if err != nil {
-			tx.Rollback()
-			log.Error().Msgf("Error increase marketplace voucher quota: %v", err)
-			return domain.ErrUpdateTransactionPayment
-		}
-	}
-
-	//return merchant voucher if exist
-	for _, transaction := range transactions {
-		if transaction.MerchantVoucherId != nil {
-			err = r.merchantRepository.IncreaseMerchantVoucherQuotaTx(tx, transaction.MerchantDomain, *transaction.MerchantVoucherId)
-			if err != nil {
-				tx.Rollback()
-				log.Error().Msgf("Error increase merchant voucher quota: %v", err)
-				return domain.ErrUpdateTransactionPayment
-			}
-		}
-	}
-
 	//return stock and promotions
 	//return product stock
 	for _, cartItem := range cartItems {
@@ -503,6 +543,7 @@ func (r *transactionRepositoryImpl) UpdateTransactionPaymentFailed(transactions
 	if err != nil {
 		return domain.ErrUpdateTransactionPayment
 	}
+	r.InvalidateVoucherQuotas(transactions)
 
 	return nil
 }
@@ -530,22 +571,18 @@ func (r *transactionRepositoryImpl) UpdateTransactionStatusCanceledTx(tx *gorm.D
 		return nil, domain.ErrUpdateTransactionStatusToRefundedAddWalletHistory
 	}
 
-	//return marketplace voucher if exist
-	if transaction.MarketplaceVoucherId != nil {
-		err = r.marketplaceVoucherRepository.IncreaseMarketplaceVoucherQuotaTx(tx, *transaction.MarketplaceVoucherId)
-		if err != nil {
-			log.Error().Msgf("Error increase marketplace voucher quota: %v", err)
-			return nil, domain.ErrUpdateTransactionStatusToCancel
-		}
+	//record refund of canceled transaction in ledger
+	err = r.postRefundLedgerTx(tx, dto.LEDGER_ENTRY_TYPE_CANCEL, transaction, amount)
+	if err != nil {
+		log.Error().Msgf("Error post cancel ledger entry: %v", err)
+		return nil, domain.ErrUpdateTransactionStatusToCancel
 	}
 
-	//return merchant voucher if exist
-	if transaction.MerchantVoucherId != nil {
-		err = r.merchantRepository.IncreaseMerchantVoucherQuotaTx(tx, transaction.MerchantDomain, *transaction.MerchantVoucherId)
-		if err != nil {
-			log.Error().Msgf("Error increase merchant voucher quota: %v", err)
-			return nil, domain.ErrUpdateTransactionStatusToCancel
-		}
+	//return marketplace and merchant vouchers if exist
+	err = r.voucherRedemptionRepository.ReleaseVouchersTx(tx, []uint{transaction.ID})
+	if err != nil {
+		log.Error().Msgf("Error release vouchers: %v", err)
+		return nil, domain.ErrUpdateTransactionStatusToCancel
 	}
 
 	//return stock and promotions
@@ -613,24 +650,20 @@ func (r *transactionRepositoryImpl) UpdateTransactionStatusCanceled(transaction
 		return nil, domain.ErrUpdateTransactionStatusToRefundedAddWalletHistory
 	}
 
-	//return marketplace voucher if exist
-	if transaction.MarketplaceVoucherId != nil {
-		err = r.marketplaceVoucherRepository.IncreaseMarketplaceVoucherQuotaTx(tx, *transaction.MarketplaceVoucherId)
-		if err != nil {
-			tx.Rollback()
-			log.Error().Msgf("Error increase marketplace voucher quota: %v", err)
-			return nil, domain.ErrUpdateTransactionStatusToCancel
-		}
+	//record refund of canceled transaction in ledger
+	err = r.postRefundLedgerTx(tx, dto.LEDGER_ENTRY_TYPE_CANCEL, transaction, amount)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("Error post cancel ledger entry: %v", err)
+		return nil, domain.ErrUpdateTransactionStatusToCancel
 	}
 
-	//return merchant voucher if exist
-	if transaction.MerchantVoucherId != nil {
-		err = r.merchantRepository.IncreaseMerchantVoucherQuotaTx(tx, transaction.MerchantDomain, *transaction.MerchantVoucherId)
-		if err != nil {
-			tx.Rollback()
-			log.Error().Msgf("Error increase merchant voucher quota: %v", err)
-			return nil, domain.ErrUpdateTransactionStatusToCancel
-		}
+	//return marketplace and merchant vouchers if exist
+	err = r.voucherRedemptionRepository.ReleaseVouchersTx(tx, []uint{transaction.ID})
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("Error release vouchers: %v", err)
+		return nil, domain.ErrUpdateTransactionStatusToCancel
 	}
 
 	//return stock and promotions
@@ -666,6 +699,7 @@ func (r *transactionRepositoryImpl) UpdateTransactionStatusCanceled(transaction
 	if err != nil {
 		return nil, domain.ErrUpdateTransactionStatusToCancel
 	}
+	r.InvalidateVoucherQuotas([]entity.Transaction{transaction})
 
 	return trxNewStatus, nil
 }
@@ -699,8 +733,9 @@ func (r *transactionRepositoryImpl) UpdateTransactionStatusCompletedTx(tx *gorm.
 		}
 	}
 
-	// add money to merchants wallet
-	merchantHoldingAcc, rowAffected, err := r.merchantHoldingAccountRepository.UpdateBalanceTx(tx, transaction.Merchant.ID, int(amount+amountPromotionMarketplace))
+	// add money to merchants wallet, credited with exactly what was deducted from marketplace wallets
+	payoutAmount := int(amount) + int(amountPromotionMarketplace)
+	merchantHoldingAcc, rowAffected, err := r.merchantHoldingAccountRepository.UpdateBalanceTx(tx, transaction.Merchant.ID, payoutAmount)
 	if err != nil || rowAffected <= 0 {
 		tx.Rollback()
 		log.Error().Msgf("Error update balance to merchant wallet: %v rows affect: %d", err, rowAffected)
@@ -708,13 +743,21 @@ func (r *transactionRepositoryImpl) UpdateTransactionStatusCompletedTx(tx *gorm.
 	}
 
 	//add history merchant holding account
-	err = r.merchantHoldingAccountHistoryRepository.AddHistoryTx(tx, merchantHoldingAcc.ID, float64(amount+amountPromotionMarketplace), fmt.Sprintf("Income from %s", transaction.InvoiceCode))
+	err = r.merchantHoldingAccountHistoryRepository.AddHistoryTx(tx, merchantHoldingAcc.ID, float64(payoutAmount), fmt.Sprintf("Income from %s", transaction.InvoiceCode))
 	if err != nil {
 		tx.Rollback()
 		log.Error().Msgf("Error create history merchant holding acc: %v", err)
 		return nil, domain.ErrUpdateTransactionStatusToCompletedFundActivities
 	}
 
+	//record payout to merchant in ledger
+	err = r.postPayoutLedgerTx(tx, transaction, merchantHoldingAcc.ID, amount, amountPromotionMarketplace)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("Error post payout ledger entry: %v", err)
+		return nil, domain.ErrUpdateTransactionStatusToCompletedFundActivities
+	}
+
 	var cartItems []entity.TransactionCartItem
 	err = json.Unmarshal([]byte(transaction.CartItems.Bytes), &cartItems)
 	if err != nil {
@@ -807,6 +850,14 @@ func (r *transactionRepositoryImpl) UpdateTransactionStatusRefundedTx(tx *gorm.D
 		return nil, domain.ErrUpdateTransactionStatusToRefundedAddWalletHistory
 	}
 
+	//record refund in ledger
+	err = r.postRefundLedgerTx(tx, dto.LEDGER_ENTRY_TYPE_REFUND, transaction, amount)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("Error post refund ledger entry: %v", err)
+		return nil, domain.ErrUpdateTransactionStatusToRefundedAddWalletHistory
+	}
+
 	//decrease pending product pending sale
 	for _, cartItem := range cartItems {
 		err = r.productRepository.ChangeNumOfPendingSaleTx(tx, cartItem.ProductId, -1)
@@ -820,6 +871,19 @@ func (r *transactionRepositoryImpl) UpdateTransactionStatusRefundedTx(tx *gorm.D
 	return trxNewStatus, nil
 }
 
+// InvalidateVoucherQuotas drops the cached quota of the vouchers used by released
+// transactions. It is only called after commit so a rollback can't raise them.
+func (r *transactionRepositoryImpl) InvalidateVoucherQuotas(transactions []entity.Transaction) {
+	for _, transaction := range transactions {
+		if transaction.MarketplaceVoucherId != nil {
+			r.voucherRedemptionRepository.InvalidateMarketplaceVoucherQuota(*transaction.MarketplaceVoucherId)
+		}
+		if transaction.MerchantVoucherId != nil {
+			r.voucherRedemptionRepository.InvalidateMerchantVoucherQuota(*transaction.MerchantVoucherId)
+		}
+	}
+}
+
 func (r *transactionRepositoryImpl) parseTransactionstoTransacionIds(transactions []entity.Transaction) []uint {
 	var transactionIds []uint
 	for _, transaction := range transactions {
@@ -828,3 +892,90 @@ func (r *transactionRepositoryImpl) parseTransactionstoTransacionIds(transaction
 
 	return transactionIds
 }
+
+func (r *transactionRepositoryImpl) postPaymentLedgerTx(tx *gorm.DB, userId uint, paymentRec entity.PaymentRecord) error {
+	amount := float64(int(paymentRec.Amount))
+
+	mpLedgerAcc, err := r.ledgerRepository.GetWalletAccountTx(tx, dto.WALLET_ID_ADMIN)
+	if err != nil {
+		return err
+	}
+
+	var sourceLedgerAcc *entity.LedgerAccount
+	if paymentRec.PaymentMethodId == dto.PAYMENT_METHOD_ID_WALLET {
+		sourceLedgerAcc, err = r.ledgerRepository.GetUserWalletAccountTx(tx, userId)
+	} else {
+		sourceLedgerAcc, err = r.ledgerRepository.GetExternalAccountTx(tx, dto.LEDGER_ACCOUNT_CODE_SEALABSPAY)
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.ledgerRepository.PostEntryTx(tx, entity.LedgerJournalEntry{
+		Type:      dto.LEDGER_ENTRY_TYPE_PAYMENT,
+		Reference: paymentRec.PaymentId,
+		Notes:     "Payment for transaction",
+		LedgerPostings: []entity.LedgerPosting{
+			{LedgerAccountId: sourceLedgerAcc.ID, Direction: dto.LEDGER_DEBIT_CODE, Amount: amount},
+			{LedgerAccountId: mpLedgerAcc.ID, Direction: dto.LEDGER_CREDIT_CODE, Amount: amount},
+		},
+	})
+}
+
+func (r *transactionRepositoryImpl) postRefundLedgerTx(tx *gorm.DB, entryType string, transaction entity.Transaction, amount float64) error {
+	refundAmount := float64(int(amount))
+
+	mpLedgerAcc, err := r.ledgerRepository.GetWalletAccountTx(tx, dto.WALLET_ID_ADMIN)
+	if err != nil {
+		return err
+	}
+
+	userLedgerAcc, err := r.ledgerRepository.GetUserWalletAccountTx(tx, transaction.UserId)
+	if err != nil {
+		return err
+	}
+
+	return r.ledgerRepository.PostEntryTx(tx, entity.LedgerJournalEntry{
+		Type:      entryType,
+		Reference: transaction.InvoiceCode,
+		Notes:     fmt.Sprintf("Refund from %s", transaction.InvoiceCode),
+		LedgerPostings: []entity.LedgerPosting{
+			{LedgerAccountId: mpLedgerAcc.ID, Direction: dto.LEDGER_DEBIT_CODE, Amount: refundAmount},
+			{LedgerAccountId: userLedgerAcc.ID, Direction: dto.LEDGER_CREDIT_CODE, Amount: refundAmount},
+		},
+	})
+}
+
+func (r *transactionRepositoryImpl) postPayoutLedgerTx(tx *gorm.DB, transaction entity.Transaction, merchantHoldingAccId uint, amount float64, amountPromotionMarketplace float64) error {
+	mpAmount := float64(int(amount))
+	promotionAmount := float64(int(amountPromotionMarketplace))
+
+	mpLedgerAcc, err := r.ledgerRepository.GetWalletAccountTx(tx, dto.WALLET_ID_ADMIN)
+	if err != nil {
+		return err
+	}
+
+	merchantLedgerAcc, err := r.ledgerRepository.GetMerchantHoldingAccountTx(tx, merchantHoldingAccId)
+	if err != nil {
+		return err
+	}
+
+	postings := []entity.LedgerPosting{
+		{LedgerAccountId: mpLedgerAcc.ID, Direction: dto.LEDGER_DEBIT_CODE, Amount: mpAmount},
+		{LedgerAccountId: merchantLedgerAcc.ID, Direction: dto.LEDGER_CREDIT_CODE, Amount: mpAmount + promotionAmount},
+	}
+	if promotionAmount > 0 {
+		promotionLedgerAcc, err := r.ledgerRepository.GetWalletAccountTx(tx, dto.WALLET_ID_ADMIN_PROMOTION)
+		if err != nil {
+			return err
+		}
+		postings = append(postings, entity.LedgerPosting{LedgerAccountId: promotionLedgerAcc.ID, Direction: dto.LEDGER_DEBIT_CODE, Amount: promotionAmount})
+	}
+
+	return r.ledgerRepository.PostEntryTx(tx, entity.LedgerJournalEntry{
+		Type:           dto.LEDGER_ENTRY_TYPE_PAYOUT,
+		Reference:      transaction.InvoiceCode,
+		Notes:          fmt.Sprintf("Income from %s", transaction.InvoiceCode),
+		LedgerPostings: postings,
+	})
+}
@@ -19,9 +19,9 @@ type TransactionStatusRepository interface {
 	UpdateTransactionStatus(transactionStatus entity.TransactionStatus) (*entity.TransactionStatus, int64, error)
 	UpdateTransactionStatusTx(tx *gorm.DB, transactionStatus entity.TransactionStatus) (*entity.TransactionStatus, int64, error)
 
-	CronUpdateTransactionWaitingStatusToCanceled(batchNumber int) error
-	CronUpdateTransactionProcessedStatusToCanceled(batchNumber int) error
-	CronUpdateTransactionDeliveredStatusToCompleted(batchNumber int) error
+	CronUpdateTransactionWaitingStatusToCanceled(batchNumber int) ([]entity.Transaction, error)
+	CronUpdateTransactionProcessedStatusToCanceled(batchNumber int) ([]entity.Transaction, error)
+	CronUpdateTransactionDeliveredStatusToCompleted(batchNumber int) ([]entity.Transaction, error)
 }
 
 type transactionStatusRepositoryImpl struct {
@@ -92,7 +92,8 @@ func (r *transactionStatusRepositoryImpl) UpdateTransactionStatusTx(tx *gorm.DB,
 	return &transactionStatus, res.RowsAffected, nil
 }
 
-func (r *transactionStatusRepositoryImpl) updateTransactionToCancelledTx(tx *gorm.DB, transactionStatuses []entity.TransactionStatus) {
+func (r *transactionStatusRepositoryImpl) updateTransactionToCancelledTx(tx *gorm.DB, transactionStatuses []entity.TransactionStatus) []entity.Transaction {
+	var canceledTransactions []entity.Transaction
 	for _, transactionStatus := range transactionStatuses {
 		transactionTmp := transactionStatus.Transaction
 		transactionTmp.TransactionStatus = &transactionStatus
@@ -118,10 +119,13 @@ func (r *transactionStatusRepositoryImpl) updateTransactionToCancelledTx(tx *gor
 			log.Error().Msgf("CronUpdateTransactionWaitingStatusToCanceled Update Status Trx: %v", err)
 			continue
 		}
+		canceledTransactions = append(canceledTransactions, transactionStatus.Transaction)
 	}
+
+	return canceledTransactions
 }
 
-func (r *transactionStatusRepositoryImpl) CronUpdateTransactionWaitingStatusToCanceled(batchNumber int) (errCron error) {
+func (r *transactionStatusRepositoryImpl) CronUpdateTransactionWaitingStatusToCanceled(batchNumber int) (updatedTransactions []entity.Transaction, errCron error) {
 	tx := r.db.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -151,21 +155,22 @@ func (r *transactionStatusRepositoryImpl) CronUpdateTransactionWaitingStatusToCa
 
 	if err != nil {
 		log.Error().Msgf("CronUpdateTransactionWaitingStatusToCanceled Error: %v", err)
-		return domain.ErrCronUpdateTransactionWaitingStatusToCanceled
+		return nil, domain.ErrCronUpdateTransactionWaitingStatusToCanceled
 	}
 
-	r.updateTransactionToCancelledTx(tx, transactionStatuses)
+	canceledTransactions := r.updateTransactionToCancelledTx(tx, transactionStatuses)
 
 	err = tx.Commit().Error
 	if err != nil {
 		log.Error().Msgf("CronUpdateTransactionWaitingStatusToCanceled Commit: %v", err)
-		return domain.ErrCronUpdateTransactionWaitingStatusToCanceled
+		return nil, domain.ErrCronUpdateTransactionWaitingStatusToCanceled
 	}
+	r.transactionRepositoryPtr.InvalidateVoucherQuotas(canceledTransactions)
 
-	return nil
+	return canceledTransactions, nil
 }
 
-func (r *transactionStatusRepositoryImpl) CronUpdateTransactionProcessedStatusToCanceled(batchNumber int) (errCron error) {
+func (r *transactionStatusRepositoryImpl) CronUpdateTransactionProcessedStatusToCanceled(batchNumber int) (updatedTransactions []entity.Transaction, errCron error) {
 	tx := r.db.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -193,21 +198,22 @@ func (r *transactionStatusRepositoryImpl) CronUpdateTransactionProcessedStatusTo
 
 	if err != nil {
 		log.Error().Msgf("CronUpdateTransactionProcessedStatusToCanceled Error: %v", err)
-		return domain.ErrCronUpdateTransactionStatusToCanceled
+		return nil, domain.ErrCronUpdateTransactionStatusToCanceled
 	}
 
-	r.updateTransactionToCancelledTx(tx, transactionStatuses)
+	canceledTransactions := r.updateTransactionToCancelledTx(tx, transactionStatuses)
 
 	err = tx.Commit().Error
 	if err != nil {
 		log.Error().Msgf("CronUpdateTransactionDeliveredStatusToCompleted Commit: %v", err)
-		return domain.ErrCronUpdateTransactionStatusToCanceled
+		return nil, domain.ErrCronUpdateTransactionStatusToCanceled
 	}
+	r.transactionRepositoryPtr.InvalidateVoucherQuotas(canceledTransactions)
 
-	return nil
+	return canceledTransactions, nil
 }
 
-func (r *transactionStatusRepositoryImpl) CronUpdateTransactionDeliveredStatusToCompleted(batchNumber int) (errCron error) {
+func (r *transactionStatusRepositoryImpl) CronUpdateTransactionDeliveredStatusToCompleted(batchNumber int) (updatedTransactions []entity.Transaction, errCron error) {
 	tx := r.db.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -234,9 +240,10 @@ func (r *transactionStatusRepositoryImpl) CronUpdateTransactionDeliveredStatusTo
 
 	if err != nil {
 		log.Error().Msgf("CronUpdateTransactionDeliveredStatusToCompleted Error: %v", err)
-		return domain.ErrCronUpdateTransactionStatusToCompleted
+		return nil, domain.ErrCronUpdateTransactionStatusToCompleted
 	}
 
+	var completedTransactions []entity.Transaction
 	for _, transactionStatus := range transactionStatuses {
 		transactionTmp := transactionStatus.Transaction
 		transactionTmp.TransactionStatus = &transactionStatus
@@ -253,16 +260,17 @@ func (r *transactionStatusRepositoryImpl) CronUpdateTransactionDeliveredStatusTo
 			log.Error().Msgf("CronUpdateTransactionDeliveredStatusToCompleted Update Status Trx: %v", err)
 			continue
 		}
+		completedTransactions = append(completedTransactions, transactionStatus.Transaction)
 	}
 
 	err = tx.Commit().Error
 	if err != nil {
 		tx.Rollback()
 		log.Error().Msgf("CronUpdateTransactionDeliveredStatusToCompleted Commit: %v", err)
-		return domain.ErrCronUpdateTransactionStatusToCompleted
+		return nil, domain.ErrCronUpdateTransactionStatusToCompleted
 	}
 
-	return nil
+	return completedTransactions, nil
 }
 
 func (r *transactionStatusRepositoryImpl) countAmountAndPromotionTrx(transaction entity.Transaction) (float64, float64, error) {
@@ -275,8 +283,8 @@ func (r *transactionStatusRepositoryImpl) countAmountAndPromotionTrx(transaction
 		return 0, 0, domain.ErrUnmarshalJSONPaymentDetails
 	}
 
-	amount = trxPaymentDetails.Subtotal + trxPaymentDetails.DeliveryFee - trxPaymentDetails.MerchantVoucherNominal
-	promotion = trxPaymentDetails.MarketplaceVoucherNominal
+	amount = trxPaymentDetails.PaidAmount()
+	promotion = trxPaymentDetails.MarketplaceSubsidy()
 
 	return amount, promotion, nil
 }
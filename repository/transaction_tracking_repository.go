@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TransactionTrackingRepository interface {
+	GetTrackingEventsByTransactionID(transactionID uint) ([]entity.TransactionTrackingEvent, error)
+	GetTrackableTransactions(limit int, offset int) ([]entity.Transaction, error)
+	CreateTrackingEvents(events []entity.TransactionTrackingEvent) error
+	UpdateTrackedTransactionDelivered(transaction entity.Transaction, deliveredAt time.Time) (bool, error)
+}
+
+type TransactionTrackingRepositoryConfig struct {
+	DB *gorm.DB
+}
+
+type transactionTrackingRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewTransactionTrackingRepository(c TransactionTrackingRepositoryConfig) TransactionTrackingRepository {
+	return &transactionTrackingRepositoryImpl{
+		db: c.DB,
+	}
+}
+
+func (r *transactionTrackingRepositoryImpl) GetTrackingEventsByTransactionID(transactionID uint) ([]entity.TransactionTrackingEvent, error) {
+	var events []entity.TransactionTrackingEvent
+	err := r.db.Where("transaction_id = ?", transactionID).
+		Order("occurred_at desc, id desc").
+		Find(&events).
+		Error
+	if err != nil {
+		return nil, domain.ErrGetTransactionTracking
+	}
+
+	return events, nil
+}
+
+// GetTrackableTransactions returns shipped transactions with a receipt number
+// that are neither delivered nor closed yet.
+func (r *transactionTrackingRepositoryImpl) GetTrackableTransactions(limit int, offset int) ([]entity.Transaction, error) {
+	var transactions []entity.Transaction
+	err := r.db.Model(&transactions).
+		Preload("Merchant").
+		Preload("TransactionStatus").
+		Preload("TransactionDeliveryStatus").
+		Joins("JOIN transaction_delivery_statuses tds ON tds.transaction_id = transactions.id AND tds.deleted_at IS NULL").
+		Joins("JOIN transaction_statuses ts ON ts.transaction_id = transactions.id AND ts.deleted_at IS NULL").
+		Where("tds.on_delivery_at IS NOT NULL").
+		Where("tds.on_delivered_at IS NULL").
+		Where("tds.receipt_number IS NOT NULL AND tds.receipt_number <> ''").
+		Where("ts.on_delivered_at IS NULL").
+		Where("ts.on_completed_at IS NULL").
+		Where("ts.on_canceled_at IS NULL").
+		Where("ts.on_refunded_at IS NULL").
+		Where("ts.on_request_refund_at IS NULL").
+		Order("transactions.id asc").
+		Limit(limit).
+		Offset(offset).
+		Find(&transactions).
+		Error
+	if err != nil {
+		return nil, domain.ErrGetTransactionTracking
+	}
+
+	return transactions, nil
+}
+
+func (r *transactionTrackingRepositoryImpl) CreateTrackingEvents(events []entity.TransactionTrackingEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&events).
+		Error
+	if err != nil {
+		return domain.ErrCreateTransactionTracking
+	}
+
+	return nil
+}
+
+// UpdateTrackedTransactionDelivered marks both the delivery and transaction status
+// as delivered, reporting false when the merchant already did so.
+func (r *transactionTrackingRepositoryImpl) UpdateTrackedTransactionDelivered(transaction entity.Transaction, deliveredAt time.Time) (isUpdated bool, err error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			err = domain.ErrUpdateTransactionTrackingDelivered
+		}
+	}()
+
+	res := tx.Model(&entity.TransactionDeliveryStatus{}).
+		Where("transaction_id = ?", transaction.ID).
+		Where("on_delivered_at IS NULL").
+		Update("on_delivered_at", deliveredAt)
+	if res.Error != nil {
+		tx.Rollback()
+		return false, domain.ErrUpdateTransactionTrackingDelivered
+	}
+	if res.RowsAffected == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	err = tx.Model(&entity.TransactionStatus{}).
+		Where("transaction_id = ?", transaction.ID).
+		Where("on_delivered_at IS NULL").
+		Update("on_delivered_at", deliveredAt).
+		Error
+	if err != nil {
+		tx.Rollback()
+		return false, domain.ErrUpdateTransactionTrackingDelivered
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return false, domain.ErrUpdateTransactionTrackingDelivered
+	}
+
+	return true, nil
+}
@@ -5,30 +5,70 @@ import (
 
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 )
 
 type UserOrderRepository interface {
-	CreateOrder(newOrder entity.UserOrder) (*entity.UserOrder, error)
+	CreateOrder(newOrder entity.UserOrder, reservationExpiresAt time.Time) (*entity.UserOrder, error)
 	FindOrderByOrderCode(orderCode string, userId uint) (*entity.UserOrder, error)
 }
 
 type UserOrderRepositoryConfig struct {
-	DB *gorm.DB
+	DB                         *gorm.DB
+	StockReservationRepository StockReservationRepository
 }
 
 type userOrderRepositoryImpl struct {
-	db *gorm.DB
+	db                         *gorm.DB
+	stockReservationRepository StockReservationRepository
 }
 
 func NewUserOrderRepository(c UserOrderRepositoryConfig) UserOrderRepository {
 	return &userOrderRepositoryImpl{
-		db: c.DB,
+		db:                         c.DB,
+		stockReservationRepository: c.StockReservationRepository,
 	}
 }
 
-func (r *userOrderRepositoryImpl) CreateOrder(newOrder entity.UserOrder) (*entity.UserOrder, error) {
-	err := r.db.Create(&newOrder).Error
+func (r *userOrderRepositoryImpl) CreateOrder(newOrder entity.UserOrder, reservationExpiresAt time.Time) (createdOrder *entity.UserOrder, errCreateOrder error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			log.Error().Msgf("Recovered in CreateOrder repo: %v", r)
+			errCreateOrder = domain.ErrCreateUserOrder
+		}
+	}()
+
+	err := tx.Create(&newOrder).Error
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("Error create user order: %v", err)
+		return nil, domain.ErrCreateUserOrder
+	}
+
+	// a new checkout replaces the user's earlier holds so one user can't lock all the stock
+	err = r.stockReservationRepository.ReleaseUserReservationsTx(tx, newOrder.UserId)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msgf("Error release user stock reservations: %v", err)
+		return nil, domain.ErrCreateUserOrder
+	}
+
+	// hold the stock until the order is paid or the reservation expires
+	err = r.stockReservationRepository.ReserveStockTx(tx, newOrder.OrderCode, newOrder.OrderItems, reservationExpiresAt)
+	if err != nil {
+		tx.Rollback()
+		if _, ok := err.(httperror.AppError); ok {
+			return nil, err
+		}
+		log.Error().Msgf("Error reserve order stock: %v", err)
+		return nil, domain.ErrCreateUserOrder
+	}
+
+	err = tx.Commit().Error
 	if err != nil {
 		return nil, domain.ErrCreateUserOrder
 	}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"gorm.io/gorm"
+)
+
+type UserVoucherRepository interface {
+	GetUserVouchers(userID uint) ([]entity.UserVoucher, error)
+	CreateUserVoucher(userVoucher *entity.UserVoucher) (*entity.UserVoucher, error)
+	DeleteUserVoucher(userID uint, userVoucherID uint) error
+}
+
+type UserVoucherRepositoryConfig struct {
+	DB *gorm.DB
+}
+
+type userVoucherRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewUserVoucherRepository(c UserVoucherRepositoryConfig) UserVoucherRepository {
+	return &userVoucherRepositoryImpl{
+		db: c.DB,
+	}
+}
+
+// GetUserVouchers only preloads vouchers that can still be used, the others are
+// left nil for the caller to skip.
+func (r *userVoucherRepositoryImpl) GetUserVouchers(userID uint) ([]entity.UserVoucher, error) {
+	var userVouchers []entity.UserVoucher
+	err := r.db.
+		Preload("MarketplaceVoucher", "is_invalid = ? AND quota > ? AND start_date <= now() AND expired_at >= now()", MP_VOUCHER_IS_VALID, MP_VOUCHER_MIN_QUOTA).
+		Preload("MerchantVoucher", "is_invalid = ? AND quota > ? AND start_date <= now() AND expired_at >= now()", MERCHANT_VOUCHER_IS_VALID, MERCHANT_VOUCHER_MIN_QUOTA).
+		Where("user_id = ?", userID).
+		Order("created_at desc").
+		Find(&userVouchers).
+		Error
+	if err != nil {
+		return nil, domain.ErrGetUserVouchers
+	}
+
+	return userVouchers, nil
+}
+
+func (r *userVoucherRepositoryImpl) CreateUserVoucher(userVoucher *entity.UserVoucher) (*entity.UserVoucher, error) {
+	err := r.db.Create(userVoucher).Error
+	if err != nil {
+		maskedErr := util.PgConsErrMasker(
+			err,
+			entity.ConstraintErrMaskerMap{
+				"user_vouchers_marketplace_voucher_unique": domain.ErrVoucherAlreadyClaimed,
+				"user_vouchers_merchant_voucher_unique":    domain.ErrVoucherAlreadyClaimed,
+			},
+			domain.ErrClaimVoucher,
+		)
+		return nil, maskedErr
+	}
+
+	return userVoucher, nil
+}
+
+func (r *userVoucherRepositoryImpl) DeleteUserVoucher(userID uint, userVoucherID uint) error {
+	res := r.db.
+		Where("id = ? AND user_id = ?", userVoucherID, userID).
+		Delete(&entity.UserVoucher{})
+	if res.Error != nil {
+		return domain.ErrDeleteUserVoucher
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrUserVoucherNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VoucherCodeRepository interface {
+	GetVoucherCodeByCode(code string) (*entity.VoucherCode, error)
+	GetMarketplaceVoucherCodes(voucherID uint, req dto.VoucherCodeListParamReqDTO) ([]entity.VoucherCode, int64, error)
+	GetMerchantVoucherCodes(voucherID uint, req dto.VoucherCodeListParamReqDTO) ([]entity.VoucherCode, int64, error)
+	GetAllMarketplaceVoucherCodes(voucherID uint) ([]entity.VoucherCode, error)
+	GetAllMerchantVoucherCodes(voucherID uint) ([]entity.VoucherCode, error)
+	CreateVoucherCodes(codes []entity.VoucherCode) ([]entity.VoucherCode, error)
+}
+
+type VoucherCodeRepositoryConfig struct {
+	DB *gorm.DB
+}
+
+type voucherCodeRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewVoucherCodeRepository(c VoucherCodeRepositoryConfig) VoucherCodeRepository {
+	return &voucherCodeRepositoryImpl{
+		db: c.DB,
+	}
+}
+
+func (r *voucherCodeRepositoryImpl) GetVoucherCodeByCode(code string) (*entity.VoucherCode, error) {
+	var voucherCode entity.VoucherCode
+	err := r.db.Where("code = ?", code).
+		First(&voucherCode).
+		Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrVoucherCodeNotFound
+		}
+		return nil, domain.ErrGetVoucherCodes
+	}
+
+	return &voucherCode, nil
+}
+
+func (r *voucherCodeRepositoryImpl) GetMarketplaceVoucherCodes(voucherID uint, req dto.VoucherCodeListParamReqDTO) ([]entity.VoucherCode, int64, error) {
+	return r.getVoucherCodes("marketplace_voucher_id", voucherID, req)
+}
+
+func (r *voucherCodeRepositoryImpl) GetMerchantVoucherCodes(voucherID uint, req dto.VoucherCodeListParamReqDTO) ([]entity.VoucherCode, int64, error) {
+	return r.getVoucherCodes("merchant_voucher_id", voucherID, req)
+}
+
+func (r *voucherCodeRepositoryImpl) getVoucherCodes(column string, voucherID uint, req dto.VoucherCodeListParamReqDTO) ([]entity.VoucherCode, int64, error) {
+	var codes []entity.VoucherCode
+	var total int64
+	pageOffset := req.Limit * (req.Page - 1)
+	baseQuery := r.db.Model(&entity.VoucherCode{}).
+		Where(column+" = ?", voucherID).
+		Order("id asc").
+		Limit(req.Limit).
+		Offset(pageOffset)
+
+	if req.Status == dto.VOUCHER_CODE_STATUS_AVAILABLE {
+		baseQuery = baseQuery.Where("redeemed_at IS NULL")
+	}
+	if req.Status == dto.VOUCHER_CODE_STATUS_REDEEMED {
+		baseQuery = baseQuery.Where("redeemed_at IS NOT NULL")
+	}
+
+	err := baseQuery.Find(&codes).Limit(-1).Offset(-1).Count(&total).Error
+	if err != nil {
+		return nil, 0, domain.ErrGetVoucherCodes
+	}
+
+	return codes, total, nil
+}
+
+func (r *voucherCodeRepositoryImpl) GetAllMarketplaceVoucherCodes(voucherID uint) ([]entity.VoucherCode, error) {
+	return r.getAllVoucherCodes("marketplace_voucher_id", voucherID)
+}
+
+func (r *voucherCodeRepositoryImpl) GetAllMerchantVoucherCodes(voucherID uint) ([]entity.VoucherCode, error) {
+	return r.getAllVoucherCodes("merchant_voucher_id", voucherID)
+}
+
+func (r *voucherCodeRepositoryImpl) getAllVoucherCodes(column string, voucherID uint) ([]entity.VoucherCode, error) {
+	var codes []entity.VoucherCode
+	err := r.db.Where(column+" = ?", voucherID).
+		Order("id asc").
+		Find(&codes).
+		Error
+	if err != nil {
+		return nil, domain.ErrGetVoucherCodes
+	}
+
+	return codes, nil
+}
+
+// CreateVoucherCodes skips codes already taken by another generated code or by a
+// voucher's own code, returning only the ones that were stored.
+func (r *voucherCodeRepositoryImpl) CreateVoucherCodes(codes []entity.VoucherCode) ([]entity.VoucherCode, error) {
+	var candidates []string
+	for _, code := range codes {
+		candidates = append(candidates, code.Code)
+	}
+
+	var takenCodes []string
+	err := r.db.Raw(`SELECT code FROM voucher_codes WHERE code IN ? AND deleted_at IS NULL
+		UNION SELECT code FROM marketplace_vouchers WHERE code IN ? AND deleted_at IS NULL
+		UNION SELECT code FROM merchant_vouchers WHERE code IN ? AND deleted_at IS NULL`, candidates, candidates, candidates).
+		Scan(&takenCodes).
+		Error
+	if err != nil {
+		return nil, domain.ErrCreateVoucherCodes
+	}
+
+	var isTaken = make(map[string]bool)
+	for _, code := range takenCodes {
+		isTaken[code] = true
+	}
+	var newCodes []entity.VoucherCode
+	for _, code := range codes {
+		if !isTaken[code.Code] {
+			newCodes = append(newCodes, code)
+		}
+	}
+	if len(newCodes) == 0 {
+		return nil, nil
+	}
+
+	err = r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&newCodes).
+		Error
+	if err != nil {
+		return nil, domain.ErrCreateVoucherCodes
+	}
+
+	// skipped rows leave the returned ids out of order, so read the batch back
+	var newCandidates []string
+	for _, code := range newCodes {
+		newCandidates = append(newCandidates, code.Code)
+	}
+	var createdCodes []entity.VoucherCode
+	err = r.db.Where("code IN ?", newCandidates).
+		Where(&entity.VoucherCode{
+			MarketplaceVoucherId: newCodes[0].MarketplaceVoucherId,
+			MerchantVoucherId:    newCodes[0].MerchantVoucherId,
+		}).
+		Order("id asc").
+		Find(&createdCodes).
+		Error
+	if err != nil {
+		return nil, domain.ErrCreateVoucherCodes
+	}
+
+	return createdCodes, nil
+}
@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cache"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// reserveQuotaScript takes one unit of a cached quota, replying nil when the
+// counter is not cached yet and -1 when it ran out.
+var reserveQuotaScript = redis.NewScript(`
+local quota = redis.call("GET", KEYS[1])
+if not quota then
+	return false
+end
+if tonumber(quota) <= 0 then
+	return -1
+end
+return redis.call("DECR", KEYS[1])
+`)
+
+var releaseQuotaScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return redis.call("INCR", KEYS[1])
+end
+return 0
+`)
+
+type VoucherRedemptionRepository interface {
+	ReserveMarketplaceVoucherQuota(voucherID uint) error
+	ReserveMerchantVoucherQuota(voucherID uint) error
+	CancelMarketplaceVoucherQuotaReservation(voucherID uint)
+	CancelMerchantVoucherQuotaReservation(voucherID uint)
+	InvalidateMarketplaceVoucherQuota(voucherID uint)
+	InvalidateMerchantVoucherQuota(voucherID uint)
+
+	RedeemMarketplaceVoucherTx(tx *gorm.DB, voucherID uint, voucherCodeID *uint, userID uint, paymentID string) error
+	RedeemMerchantVoucherTx(tx *gorm.DB, voucherID uint, voucherCodeID *uint, userID uint, transactionID uint, paymentID string) error
+	ReleaseVouchersTx(tx *gorm.DB, transactionIDs []uint) error
+}
+
+type VoucherRedemptionRepositoryConfig struct {
+	DB  *gorm.DB
+	RDB *cache.RDBConnection
+}
+
+type voucherRedemptionRepositoryImpl struct {
+	db  *gorm.DB
+	rdb *cache.RDBConnection
+}
+
+func NewVoucherRedemptionRepository(c VoucherRedemptionRepositoryConfig) VoucherRedemptionRepository {
+	return &voucherRedemptionRepositoryImpl{
+		db:  c.DB,
+		rdb: c.RDB,
+	}
+}
+
+// ReserveMarketplaceVoucherQuota turns away checkouts of a sold out voucher before
+// they reach the database, which stays the source of truth on redemption.
+func (r *voucherRedemptionRepositoryImpl) ReserveMarketplaceVoucherQuota(voucherID uint) error {
+	return r.reserveQuota(fmt.Sprintf(dto.VOUCHER_QUOTA_KEY_MARKETPLACE, voucherID), func() (int, error) {
+		var voucher entity.MarketplaceVoucher
+		err := r.db.Select("quota").Where("id = ?", voucherID).First(&voucher).Error
+		return voucher.Quota, err
+	})
+}
+
+func (r *voucherRedemptionRepositoryImpl) ReserveMerchantVoucherQuota(voucherID uint) error {
+	return r.reserveQuota(fmt.Sprintf(dto.VOUCHER_QUOTA_KEY_MERCHANT, voucherID), func() (int, error) {
+		var voucher entity.MerchantVoucher
+		err := r.db.Select("quota").Where("id = ?", voucherID).First(&voucher).Error
+		return voucher.Quota, err
+	})
+}
+
+func (r *voucherRedemptionRepositoryImpl) reserveQuota(key string, getQuota func() (int, error)) error {
+	ctx := context.Background()
+	remaining, err := reserveQuotaScript.Run(ctx, r.rdb, []string{key}).Int()
+	if err == redis.Nil {
+		var quota int
+		quota, err = getQuota()
+		if err != nil {
+			return domain.ErrReserveVoucherQuota
+		}
+		// another request may have cached it first, SetNX keeps whichever came first
+		err = r.rdb.SetNX(ctx, key, quota, dto.VOUCHER_QUOTA_CACHE_DURATION).Err()
+		if err != nil {
+			log.Error().Msgf("Error cache voucher quota %s: %v", key, err)
+			return nil
+		}
+		remaining, err = reserveQuotaScript.Run(ctx, r.rdb, []string{key}).Int()
+	}
+	if err != nil {
+		// the database still guards the quota when redis is unavailable
+		log.Error().Msgf("Error reserve voucher quota %s: %v", key, err)
+		return nil
+	}
+	if remaining < 0 {
+		return domain.ErrVoucherQuotaExhausted
+	}
+
+	return nil
+}
+
+func (r *voucherRedemptionRepositoryImpl) CancelMarketplaceVoucherQuotaReservation(voucherID uint) {
+	r.releaseQuota(fmt.Sprintf(dto.VOUCHER_QUOTA_KEY_MARKETPLACE, voucherID))
+}
+
+func (r *voucherRedemptionRepositoryImpl) CancelMerchantVoucherQuotaReservation(voucherID uint) {
+	r.releaseQuota(fmt.Sprintf(dto.VOUCHER_QUOTA_KEY_MERCHANT, voucherID))
+}
+
+func (r *voucherRedemptionRepositoryImpl) releaseQuota(key string) {
+	err := releaseQuotaScript.Run(context.Background(), r.rdb, []string{key}).Err()
+	if err != nil {
+		log.Error().Msgf("Error release voucher quota %s: %v", key, err)
+	}
+}
+
+// InvalidateMarketplaceVoucherQuota drops the cached counter after the quota is
+// edited so the next reservation reloads it.
+func (r *voucherRedemptionRepositoryImpl) InvalidateMarketplaceVoucherQuota(voucherID uint) {
+	err := r.rdb.DeleteCache(fmt.Sprintf(dto.VOUCHER_QUOTA_KEY_MARKETPLACE, voucherID))
+	if err != nil {
+		log.Error().Msgf("Error invalidate marketplace voucher quota %d: %v", voucherID, err)
+	}
+}
+
+func (r *voucherRedemptionRepositoryImpl) InvalidateMerchantVoucherQuota(voucherID uint) {
+	err := r.rdb.DeleteCache(fmt.Sprintf(dto.VOUCHER_QUOTA_KEY_MERCHANT, voucherID))
+	if err != nil {
+		log.Error().Msgf("Error invalidate merchant voucher quota %d: %v", voucherID, err)
+	}
+}
+
+func (r *voucherRedemptionRepositoryImpl) RedeemMarketplaceVoucherTx(tx *gorm.DB, voucherID uint, voucherCodeID *uint, userID uint, paymentID string) error {
+	res := tx.Model(&entity.MarketplaceVoucher{}).
+		Where("id = ? AND quota > 0", voucherID).
+		Update("quota", gorm.Expr("quota - ?", 1))
+	if res.Error != nil {
+		return domain.ErrRedeemVoucher
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrVoucherQuotaExhausted
+	}
+
+	err := r.redeemVoucherCodeTx(tx, voucherCodeID, userID, paymentID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Create(&entity.VoucherRedemption{
+		MarketplaceVoucherId: &voucherID,
+		VoucherCodeId:        voucherCodeID,
+		UserId:               userID,
+		PaymentId:            paymentID,
+	}).Error
+	if err != nil {
+		return domain.ErrRedeemVoucher
+	}
+
+	return nil
+}
+
+func (r *voucherRedemptionRepositoryImpl) RedeemMerchantVoucherTx(tx *gorm.DB, voucherID uint, voucherCodeID *uint, userID uint, transactionID uint, paymentID string) error {
+	res := tx.Model(&entity.MerchantVoucher{}).
+		Where("id = ? AND is_invalid = ? AND quota > 0", voucherID, MERCHANT_VOUCHER_IS_VALID).
+		Update("quota", gorm.Expr("quota - ?", 1))
+	if res.Error != nil {
+		return domain.ErrRedeemVoucher
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrVoucherQuotaExhausted
+	}
+
+	err := r.redeemVoucherCodeTx(tx, voucherCodeID, userID, paymentID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Create(&entity.VoucherRedemption{
+		MerchantVoucherId: &voucherID,
+		VoucherCodeId:     voucherCodeID,
+		UserId:            userID,
+		TransactionId:     &transactionID,
+		PaymentId:         paymentID,
+	}).Error
+	if err != nil {
+		return domain.ErrRedeemVoucher
+	}
+
+	return nil
+}
+
+// redeemVoucherCodeTx marks a single-use code as used, failing when another
+// checkout took it first.
+func (r *voucherRedemptionRepositoryImpl) redeemVoucherCodeTx(tx *gorm.DB, voucherCodeID *uint, userID uint, paymentID string) error {
+	if voucherCodeID == nil {
+		return nil
+	}
+
+	res := tx.Model(&entity.VoucherCode{}).
+		Where("id = ? AND redeemed_at IS NULL", *voucherCodeID).
+		Updates(map[string]interface{}{
+			"redeemed_at": gorm.Expr("now()"),
+			"redeemed_by": userID,
+			"payment_id":  paymentID,
+		})
+	if res.Error != nil {
+		return domain.ErrRedeemVoucher
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrVoucherCodeAlreadyRedeemed
+	}
+
+	return nil
+}
+
+// ReleaseVouchersTx gives back the vouchers held by canceled transactions. A
+// marketplace voucher covers the whole checkout, so it is only given back once no
+// other transaction of the same payment is still active. The cached counters are
+// left alone, the caller drops them once the transaction commits.
+func (r *voucherRedemptionRepositoryImpl) ReleaseVouchersTx(tx *gorm.DB, transactionIDs []uint) error {
+	var merchantRedemptions []entity.VoucherRedemption
+	err := tx.Raw(`UPDATE voucher_redemptions SET released_at = now(), updated_at = now()
+		WHERE merchant_voucher_id IS NOT NULL AND released_at IS NULL AND deleted_at IS NULL
+		AND transaction_id IN ?
+		RETURNING merchant_voucher_id, voucher_code_id`, transactionIDs).
+		Scan(&merchantRedemptions).
+		Error
+	if err != nil {
+		return domain.ErrReleaseVoucher
+	}
+
+	// sibling cancels wait for each other here, so the last one to commit sees
+	// every other cancel and gives the marketplace voucher back
+	var lockedIDs []uint
+	err = tx.Raw(`SELECT id FROM voucher_redemptions
+		WHERE marketplace_voucher_id IS NOT NULL AND released_at IS NULL AND deleted_at IS NULL
+		AND payment_id IN (SELECT payment_id FROM transaction_payment_records WHERE transaction_id IN ?)
+		ORDER BY id
+		FOR UPDATE`, transactionIDs).
+		Scan(&lockedIDs).
+		Error
+	if err != nil {
+		return domain.ErrReleaseVoucher
+	}
+
+	var marketplaceRedemptions []entity.VoucherRedemption
+	err = tx.Raw(`UPDATE voucher_redemptions vr SET released_at = now(), updated_at = now()
+		WHERE vr.marketplace_voucher_id IS NOT NULL AND vr.released_at IS NULL AND vr.deleted_at IS NULL
+		AND vr.payment_id IN (SELECT payment_id FROM transaction_payment_records WHERE transaction_id IN ?)
+		AND NOT EXISTS (
+			SELECT 1 FROM transaction_payment_records tpr
+			JOIN transaction_statuses ts ON ts.transaction_id = tpr.transaction_id AND ts.deleted_at IS NULL
+			WHERE tpr.payment_id = vr.payment_id AND tpr.transaction_id NOT IN ? AND ts.on_canceled_at IS NULL
+		)
+		RETURNING vr.marketplace_voucher_id, vr.voucher_code_id`, transactionIDs, transactionIDs).
+		Scan(&marketplaceRedemptions).
+		Error
+	if err != nil {
+		return domain.ErrReleaseVoucher
+	}
+
+	var voucherCodeIDs []uint
+	for _, redemption := range merchantRedemptions {
+		voucherID := *redemption.MerchantVoucherId
+		err = tx.Model(&entity.MerchantVoucher{}).
+			Where("id = ?", voucherID).
+			Update("quota", gorm.Expr("quota + ?", 1)).
+			Error
+		if err != nil {
+			return domain.ErrReleaseVoucher
+		}
+		if redemption.VoucherCodeId != nil {
+			voucherCodeIDs = append(voucherCodeIDs, *redemption.VoucherCodeId)
+		}
+	}
+
+	for _, redemption := range marketplaceRedemptions {
+		voucherID := *redemption.MarketplaceVoucherId
+		err = tx.Model(&entity.MarketplaceVoucher{}).
+			Where("id = ?", voucherID).
+			Update("quota", gorm.Expr("quota + ?", 1)).
+			Error
+		if err != nil {
+			return domain.ErrReleaseVoucher
+		}
+		if redemption.VoucherCodeId != nil {
+			voucherCodeIDs = append(voucherCodeIDs, *redemption.VoucherCodeId)
+		}
+	}
+
+	// released single-use codes can be used again
+	if len(voucherCodeIDs) > 0 {
+		err = tx.Model(&entity.VoucherCode{}).
+			Where("id IN ?", voucherCodeIDs).
+			Updates(map[string]interface{}{
+				"redeemed_at": nil,
+				"redeemed_by": nil,
+				"payment_id":  nil,
+			}).
+			Error
+		if err != nil {
+			return domain.ErrReleaseVoucher
+		}
+	}
+
+	return nil
+}
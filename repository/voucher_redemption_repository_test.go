@@ -0,0 +1,418 @@
+package repository_test
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cache"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	concurrentCheckouts = 50
+	voucherQuota        = 5
+)
+
+// the tables hold only the columns the redemption repository touches
+var voucherRedemptionTestSchema = []string{
+	`create table marketplace_vouchers (
+		id bigserial primary key,
+		quota int not null,
+		is_invalid boolean not null default false,
+		created_at timestamptz default now(),
+		updated_at timestamptz default now(),
+		deleted_at timestamptz
+	)`,
+	`create table merchant_vouchers (
+		id bigserial primary key,
+		quota int not null,
+		is_invalid boolean not null default false,
+		created_at timestamptz default now(),
+		updated_at timestamptz default now(),
+		deleted_at timestamptz
+	)`,
+	`create table voucher_codes (
+		id bigserial primary key,
+		redeemed_at timestamptz,
+		redeemed_by bigint,
+		payment_id varchar,
+		created_at timestamptz default now(),
+		updated_at timestamptz default now(),
+		deleted_at timestamptz
+	)`,
+	`create table voucher_redemptions (
+		id bigserial primary key,
+		marketplace_voucher_id bigint references marketplace_vouchers(id),
+		merchant_voucher_id bigint references merchant_vouchers(id),
+		voucher_code_id bigint references voucher_codes(id),
+		user_id bigint not null,
+		transaction_id bigint,
+		payment_id varchar not null,
+		released_at timestamptz,
+		created_at timestamptz default now(),
+		updated_at timestamptz default now(),
+		deleted_at timestamptz
+	)`,
+	`create table transaction_payment_records (
+		id bigserial primary key,
+		transaction_id bigint not null,
+		payment_id varchar not null
+	)`,
+	`create table transaction_statuses (
+		id bigserial primary key,
+		transaction_id bigint not null,
+		on_canceled_at timestamptz,
+		deleted_at timestamptz
+	)`,
+}
+
+type voucherRedemptionTestEnv struct {
+	db   *gorm.DB
+	mr   *miniredis.Miniredis
+	repo repository.VoucherRedemptionRepository
+}
+
+// setupVoucherRedemptionTest needs TEST_DATABASE_DSN pointing to a disposable
+// postgres, in the same key=value form the app uses, and skips without it:
+//
+//	TEST_DATABASE_DSN="host=localhost user=postgres password=postgres dbname=postgres port=5432 sslmode=disable" \
+//		go test ./repository -run Voucher
+//
+// Every test gets its own schema, redis is replaced by miniredis.
+func setupVoucherRedemptionTest(t *testing.T) *voucherRedemptionTestEnv {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	gormConfig := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+	adminDB, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	if err != nil {
+		t.Fatalf("connect database: %v", err)
+	}
+
+	schema := fmt.Sprintf("voucher_redemption_test_%d", time.Now().UnixNano())
+	if err := adminDB.Exec("create schema " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		adminDB.Exec("drop schema " + schema + " cascade")
+	})
+
+	db, err := gorm.Open(postgres.Open(dsn+" search_path="+schema), gormConfig)
+	if err != nil {
+		t.Fatalf("connect database: %v", err)
+	}
+	for _, ddl := range voucherRedemptionTestSchema {
+		if err := db.Exec(ddl).Error; err != nil {
+			t.Fatalf("create table: %v", err)
+		}
+	}
+
+	mr := miniredis.RunT(t)
+	rdb := &cache.RDBConnection{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+
+	return &voucherRedemptionTestEnv{
+		db: db,
+		mr: mr,
+		repo: repository.NewVoucherRedemptionRepository(repository.VoucherRedemptionRepositoryConfig{
+			DB:  db,
+			RDB: rdb,
+		}),
+	}
+}
+
+func (e *voucherRedemptionTestEnv) createVoucher(t *testing.T, table string, quota int) uint {
+	t.Helper()
+
+	var id uint
+	err := e.db.Raw("insert into "+table+" (quota) values (?) returning id", quota).Scan(&id).Error
+	if err != nil {
+		t.Fatalf("create voucher: %v", err)
+	}
+	return id
+}
+
+func (e *voucherRedemptionTestEnv) assertVoucherState(t *testing.T, table string, column string, voucherID uint, key string, wantQuota int, wantActive int64) {
+	t.Helper()
+
+	var quota int
+	if err := e.db.Raw("select quota from "+table+" where id = ?", voucherID).Scan(&quota).Error; err != nil {
+		t.Fatalf("get quota: %v", err)
+	}
+	if quota != wantQuota {
+		t.Errorf("quota = %d, want %d", quota, wantQuota)
+	}
+
+	var active int64
+	err := e.db.Model(&entity.VoucherRedemption{}).
+		Where(column+" = ? AND released_at IS NULL", voucherID).
+		Count(&active).
+		Error
+	if err != nil {
+		t.Fatalf("count redemptions: %v", err)
+	}
+	if active != wantActive {
+		t.Errorf("active redemptions = %d, want %d", active, wantActive)
+	}
+
+	if e.mr.Exists(key) {
+		cached, err := e.mr.Get(key)
+		if err != nil {
+			t.Fatalf("get cached quota: %v", err)
+		}
+		counter, err := strconv.Atoi(cached)
+		if err != nil {
+			t.Fatalf("parse cached quota %q: %v", cached, err)
+		}
+		if counter < 0 {
+			t.Errorf("cached quota = %d, want it never below zero", counter)
+		}
+	}
+}
+
+// hammer runs every checkout at once and counts the ones that went through.
+func hammer(n int, checkout func(i int) error) int {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var redeemed int
+	start := make(chan struct{})
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			if checkout(i) == nil {
+				mu.Lock()
+				redeemed++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return redeemed
+}
+
+func (e *voucherRedemptionTestEnv) checkoutMarketplaceVoucher(voucherID uint, i int) error {
+	err := e.repo.ReserveMarketplaceVoucherQuota(voucherID)
+	if err != nil {
+		return err
+	}
+
+	tx := e.db.Begin()
+	err = e.repo.RedeemMarketplaceVoucherTx(tx, voucherID, nil, uint(i+1), fmt.Sprintf("PAY-%d", i))
+	if err != nil {
+		tx.Rollback()
+		e.repo.CancelMarketplaceVoucherQuotaReservation(voucherID)
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func (e *voucherRedemptionTestEnv) checkoutMerchantVoucher(voucherID uint, i int) error {
+	err := e.repo.ReserveMerchantVoucherQuota(voucherID)
+	if err != nil {
+		return err
+	}
+
+	tx := e.db.Begin()
+	err = e.repo.RedeemMerchantVoucherTx(tx, voucherID, nil, uint(i+1), uint(i+1), fmt.Sprintf("PAY-%d", i))
+	if err != nil {
+		tx.Rollback()
+		e.repo.CancelMerchantVoucherQuotaReservation(voucherID)
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func TestRedeemMarketplaceVoucherConcurrently(t *testing.T) {
+	e := setupVoucherRedemptionTest(t)
+	voucherID := e.createVoucher(t, "marketplace_vouchers", voucherQuota)
+
+	redeemed := hammer(concurrentCheckouts, func(i int) error {
+		return e.checkoutMarketplaceVoucher(voucherID, i)
+	})
+
+	if redeemed != voucherQuota {
+		t.Errorf("redeemed = %d, want %d", redeemed, voucherQuota)
+	}
+	e.assertVoucherState(t, "marketplace_vouchers", "marketplace_voucher_id", voucherID, fmt.Sprintf(dto.VOUCHER_QUOTA_KEY_MARKETPLACE, voucherID), 0, voucherQuota)
+}
+
+func TestRedeemMarketplaceVoucherWithStaleCachedQuota(t *testing.T) {
+	e := setupVoucherRedemptionTest(t)
+	voucherID := e.createVoucher(t, "marketplace_vouchers", voucherQuota)
+
+	// a counter cached before the quota was lowered lets every checkout through redis
+	key := fmt.Sprintf(dto.VOUCHER_QUOTA_KEY_MARKETPLACE, voucherID)
+	e.mr.Set(key, strconv.Itoa(concurrentCheckouts))
+
+	redeemed := hammer(concurrentCheckouts, func(i int) error {
+		return e.checkoutMarketplaceVoucher(voucherID, i)
+	})
+
+	if redeemed != voucherQuota {
+		t.Errorf("redeemed = %d, want %d", redeemed, voucherQuota)
+	}
+	e.assertVoucherState(t, "marketplace_vouchers", "marketplace_voucher_id", voucherID, key, 0, voucherQuota)
+}
+
+func TestRedeemMerchantVoucherConcurrently(t *testing.T) {
+	e := setupVoucherRedemptionTest(t)
+	voucherID := e.createVoucher(t, "merchant_vouchers", voucherQuota)
+
+	redeemed := hammer(concurrentCheckouts, func(i int) error {
+		return e.checkoutMerchantVoucher(voucherID, i)
+	})
+
+	if redeemed != voucherQuota {
+		t.Errorf("redeemed = %d, want %d", redeemed, voucherQuota)
+	}
+	e.assertVoucherState(t, "merchant_vouchers", "merchant_voucher_id", voucherID, fmt.Sprintf(dto.VOUCHER_QUOTA_KEY_MERCHANT, voucherID), 0, voucherQuota)
+}
+
+func TestReleaseMerchantVoucherOnCancelConcurrently(t *testing.T) {
+	e := setupVoucherRedemptionTest(t)
+	voucherID := e.createVoucher(t, "merchant_vouchers", voucherQuota)
+	key := fmt.Sprintf(dto.VOUCHER_QUOTA_KEY_MERCHANT, voucherID)
+
+	redeemed := hammer(concurrentCheckouts, func(i int) error {
+		return e.checkoutMerchantVoucher(voucherID, i)
+	})
+	if redeemed != voucherQuota {
+		t.Fatalf("redeemed = %d, want %d", redeemed, voucherQuota)
+	}
+
+	var transactionIDs []uint
+	err := e.db.Model(&entity.VoucherRedemption{}).
+		Where("merchant_voucher_id = ?", voucherID).
+		Pluck("transaction_id", &transactionIDs).
+		Error
+	if err != nil {
+		t.Fatalf("get redeemed transactions: %v", err)
+	}
+
+	// every transaction is canceled twice at once, the quota must come back only once
+	hammer(2*len(transactionIDs), func(i int) error {
+		tx := e.db.Begin()
+		err := e.repo.ReleaseVouchersTx(tx, []uint{transactionIDs[i/2]})
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit().Error
+	})
+
+	e.assertVoucherState(t, "merchant_vouchers", "merchant_voucher_id", voucherID, key, voucherQuota, 0)
+	if cached, _ := e.mr.Get(key); cached != "0" {
+		t.Errorf("cached quota = %q, want it untouched until the caller invalidates it", cached)
+	}
+
+	e.repo.InvalidateMerchantVoucherQuota(voucherID)
+	redeemed = hammer(concurrentCheckouts, func(i int) error {
+		return e.checkoutMerchantVoucher(voucherID, concurrentCheckouts+i)
+	})
+	if redeemed != voucherQuota {
+		t.Errorf("redeemed after release = %d, want %d", redeemed, voucherQuota)
+	}
+	e.assertVoucherState(t, "merchant_vouchers", "merchant_voucher_id", voucherID, key, 0, voucherQuota)
+}
+
+func TestReleaseMarketplaceVoucherOnCancel(t *testing.T) {
+	e := setupVoucherRedemptionTest(t)
+	voucherID := e.createVoucher(t, "marketplace_vouchers", voucherQuota)
+	key := fmt.Sprintf(dto.VOUCHER_QUOTA_KEY_MARKETPLACE, voucherID)
+
+	if err := e.checkoutMarketplaceVoucher(voucherID, 0); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	// the checkout was split into two merchant transactions sharing one payment
+	for _, transactionID := range []uint{1, 2} {
+		e.db.Exec("insert into transaction_payment_records (transaction_id, payment_id) values (?, ?)", transactionID, "PAY-0")
+		e.db.Exec("insert into transaction_statuses (transaction_id) values (?)", transactionID)
+	}
+
+	release := func(transactionID uint, commit bool) {
+		t.Helper()
+		tx := e.db.Begin()
+		if err := e.repo.ReleaseVouchersTx(tx, []uint{transactionID}); err != nil {
+			tx.Rollback()
+			t.Fatalf("release: %v", err)
+		}
+		tx.Exec("update transaction_statuses set on_canceled_at = now() where transaction_id = ?", transactionID)
+		if !commit {
+			tx.Rollback()
+			return
+		}
+		if err := tx.Commit().Error; err != nil {
+			t.Fatalf("commit: %v", err)
+		}
+	}
+
+	// the other transaction is still active so the voucher stays held
+	release(1, true)
+	e.assertVoucherState(t, "marketplace_vouchers", "marketplace_voucher_id", voucherID, key, voucherQuota-1, 1)
+
+	// a rolled back cancel gives nothing back
+	release(2, false)
+	e.assertVoucherState(t, "marketplace_vouchers", "marketplace_voucher_id", voucherID, key, voucherQuota-1, 1)
+
+	release(2, true)
+	e.assertVoucherState(t, "marketplace_vouchers", "marketplace_voucher_id", voucherID, key, voucherQuota, 0)
+	if cached, _ := e.mr.Get(key); cached != strconv.Itoa(voucherQuota-1) {
+		t.Errorf("cached quota = %q, want it untouched until the caller invalidates it", cached)
+	}
+}
+
+func TestReleaseMarketplaceVoucherOnSiblingCancelsConcurrently(t *testing.T) {
+	e := setupVoucherRedemptionTest(t)
+	voucherID := e.createVoucher(t, "marketplace_vouchers", voucherQuota)
+	key := fmt.Sprintf(dto.VOUCHER_QUOTA_KEY_MARKETPLACE, voucherID)
+
+	// every checkout was split into two merchant transactions sharing one payment
+	siblingsCanceled := make([]*sync.WaitGroup, voucherQuota)
+	for i := 0; i < voucherQuota; i++ {
+		if err := e.checkoutMarketplaceVoucher(voucherID, i); err != nil {
+			t.Fatalf("checkout: %v", err)
+		}
+		for _, transactionID := range []int{2*i + 1, 2*i + 2} {
+			e.db.Exec("insert into transaction_payment_records (transaction_id, payment_id) values (?, ?)", transactionID, fmt.Sprintf("PAY-%d", i))
+			e.db.Exec("insert into transaction_statuses (transaction_id) values (?)", transactionID)
+		}
+		siblingsCanceled[i] = &sync.WaitGroup{}
+		siblingsCanceled[i].Add(2)
+	}
+
+	// both siblings are canceled at once, neither sees the other's cancel
+	// before releasing, yet one of them must give the voucher back
+	hammer(2*voucherQuota, func(i int) error {
+		tx := e.db.Begin()
+		tx.Exec("update transaction_statuses set on_canceled_at = now() where transaction_id = ?", i+1)
+		siblingsCanceled[i/2].Done()
+		siblingsCanceled[i/2].Wait()
+
+		err := e.repo.ReleaseVouchersTx(tx, []uint{uint(i + 1)})
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit().Error
+	})
+
+	e.assertVoucherState(t, "marketplace_vouchers", "marketplace_voucher_id", voucherID, key, voucherQuota, 0)
+}
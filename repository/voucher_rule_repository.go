@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"gorm.io/gorm"
+)
+
+type VoucherRuleRepository interface {
+	GetMarketplaceVoucherRules(voucherID uint) ([]entity.VoucherRule, error)
+	GetMerchantVoucherRules(voucherID uint) ([]entity.VoucherRule, error)
+	UpdateMarketplaceVoucherRules(voucherID uint, rules []entity.VoucherRule) ([]entity.VoucherRule, error)
+	UpdateMerchantVoucherRules(voucherID uint, rules []entity.VoucherRule) ([]entity.VoucherRule, error)
+
+	CountUserTransactions(userID uint) (int64, error)
+	CountUserMarketplaceVoucherUsage(userID uint, voucherID uint) (int64, error)
+	CountUserMerchantVoucherUsage(userID uint, voucherID uint) (int64, error)
+	CountProductsInCategories(productIDs []uint, categoryIDs []uint) (int64, error)
+}
+
+type VoucherRuleRepositoryConfig struct {
+	DB *gorm.DB
+}
+
+type voucherRuleRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewVoucherRuleRepository(c VoucherRuleRepositoryConfig) VoucherRuleRepository {
+	return &voucherRuleRepositoryImpl{
+		db: c.DB,
+	}
+}
+
+func (r *voucherRuleRepositoryImpl) GetMarketplaceVoucherRules(voucherID uint) ([]entity.VoucherRule, error) {
+	return r.getVoucherRules("marketplace_voucher_id", voucherID)
+}
+
+func (r *voucherRuleRepositoryImpl) GetMerchantVoucherRules(voucherID uint) ([]entity.VoucherRule, error) {
+	return r.getVoucherRules("merchant_voucher_id", voucherID)
+}
+
+func (r *voucherRuleRepositoryImpl) getVoucherRules(column string, voucherID uint) ([]entity.VoucherRule, error) {
+	var rules []entity.VoucherRule
+	err := r.db.Where(column+" = ?", voucherID).
+		Order("id asc").
+		Find(&rules).
+		Error
+	if err != nil {
+		return nil, domain.ErrGetVoucherRules
+	}
+
+	return rules, nil
+}
+
+func (r *voucherRuleRepositoryImpl) UpdateMarketplaceVoucherRules(voucherID uint, rules []entity.VoucherRule) ([]entity.VoucherRule, error) {
+	for i := range rules {
+		rules[i].MarketplaceVoucherId = &voucherID
+	}
+	return r.replaceVoucherRules("marketplace_voucher_id", voucherID, rules)
+}
+
+func (r *voucherRuleRepositoryImpl) UpdateMerchantVoucherRules(voucherID uint, rules []entity.VoucherRule) ([]entity.VoucherRule, error) {
+	for i := range rules {
+		rules[i].MerchantVoucherId = &voucherID
+	}
+	return r.replaceVoucherRules("merchant_voucher_id", voucherID, rules)
+}
+
+// replaceVoucherRules swaps the voucher's whole rule set in one transaction.
+func (r *voucherRuleRepositoryImpl) replaceVoucherRules(column string, voucherID uint, rules []entity.VoucherRule) (res []entity.VoucherRule, err error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			err = domain.ErrUpdateVoucherRules
+		}
+	}()
+
+	err = tx.Where(column+" = ?", voucherID).
+		Delete(&entity.VoucherRule{}).
+		Error
+	if err != nil {
+		tx.Rollback()
+		return nil, domain.ErrUpdateVoucherRules
+	}
+
+	if len(rules) > 0 {
+		err = tx.Create(&rules).Error
+		if err != nil {
+			tx.Rollback()
+			return nil, domain.ErrUpdateVoucherRules
+		}
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return nil, domain.ErrUpdateVoucherRules
+	}
+
+	return rules, nil
+}
+
+// CountUserTransactions counts the user's transactions that were not canceled.
+func (r *voucherRuleRepositoryImpl) CountUserTransactions(userID uint) (int64, error) {
+	var count int64
+	err := r.activeUserTransactions(userID).
+		Count(&count).
+		Error
+	if err != nil {
+		return 0, domain.ErrCheckVoucherRules
+	}
+
+	return count, nil
+}
+
+// CountUserMarketplaceVoucherUsage counts checkouts rather than transactions, since a
+// marketplace voucher is shared by every merchant transaction of the same payment.
+func (r *voucherRuleRepositoryImpl) CountUserMarketplaceVoucherUsage(userID uint, voucherID uint) (int64, error) {
+	var count int64
+	err := r.activeUserTransactions(userID).
+		Joins("JOIN transaction_payment_records tpr ON tpr.transaction_id = transactions.id").
+		Where("transactions.marketplace_voucher_id = ?", voucherID).
+		Distinct("tpr.payment_id").
+		Count(&count).
+		Error
+	if err != nil {
+		return 0, domain.ErrCheckVoucherRules
+	}
+
+	return count, nil
+}
+
+func (r *voucherRuleRepositoryImpl) CountUserMerchantVoucherUsage(userID uint, voucherID uint) (int64, error) {
+	var count int64
+	err := r.activeUserTransactions(userID).
+		Where("transactions.merchant_voucher_id = ?", voucherID).
+		Count(&count).
+		Error
+	if err != nil {
+		return 0, domain.ErrCheckVoucherRules
+	}
+
+	return count, nil
+}
+
+func (r *voucherRuleRepositoryImpl) activeUserTransactions(userID uint) *gorm.DB {
+	return r.db.Model(&entity.Transaction{}).
+		Joins("JOIN transaction_statuses ts ON ts.transaction_id = transactions.id AND ts.deleted_at IS NULL").
+		Where("transactions.user_id = ?", userID).
+		Where("ts.on_canceled_at IS NULL")
+}
+
+// CountProductsInCategories matches a product against its category and the
+// category's ancestors.
+func (r *voucherRuleRepositoryImpl) CountProductsInCategories(productIDs []uint, categoryIDs []uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&entity.Product{}).
+		Joins("JOIN categories c ON c.id = products.category_id").
+		Where("products.id IN ?", productIDs).
+		Where("c.id IN ? OR c.parent_id IN ? OR c.grandparent_id IN ?", categoryIDs, categoryIDs, categoryIDs).
+		Count(&count).
+		Error
+	if err != nil {
+		return 0, domain.ErrCheckVoucherRules
+	}
+
+	return count, nil
+}
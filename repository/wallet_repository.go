@@ -39,17 +39,20 @@ type WalletRepository interface {
 type WalletRepositoryConfig struct {
 	DB                      *gorm.DB
 	PaymentRecordRepository PaymentRecordRepository
+	LedgerRepository        LedgerRepository
 }
 
 type walletRepositoryImpl struct {
 	db                      *gorm.DB
 	paymentRecordRepository PaymentRecordRepository
+	ledgerRepository        LedgerRepository
 }
 
 func NewWalletRepository(c WalletRepositoryConfig) WalletRepository {
 	return &walletRepositoryImpl{
 		db:                      c.DB,
 		paymentRecordRepository: c.PaymentRecordRepository,
+		ledgerRepository:        c.LedgerRepository,
 	}
 }
 
@@ -256,6 +259,31 @@ func (r *walletRepositoryImpl) BalanceTopUpSuccess(wallet entity.Wallet, transac
 		return domain.ErrUpdateWalletBalance
 	}
 
+	// record top up in ledger, money comes from sealabspay
+	slpAcc, err := r.ledgerRepository.GetExternalAccountTx(tx, dto.LEDGER_ACCOUNT_CODE_SEALABSPAY)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	walletAcc, err := r.ledgerRepository.GetWalletAccountTx(tx, wallet.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = r.ledgerRepository.PostEntryTx(tx, entity.LedgerJournalEntry{
+		Type:      dto.LEDGER_ENTRY_TYPE_TOP_UP,
+		Reference: transaction.PaymentId,
+		Notes:     "Top up from SeaLabsPay",
+		LedgerPostings: []entity.LedgerPosting{
+			{LedgerAccountId: slpAcc.ID, Direction: dto.LEDGER_DEBIT_CODE, Amount: transaction.PaymentRecord.Amount},
+			{LedgerAccountId: walletAcc.ID, Direction: dto.LEDGER_CREDIT_CODE, Amount: transaction.PaymentRecord.Amount},
+		},
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	err = tx.Commit().Error
 	if err != nil {
 		return domain.ErrTopUpWallet
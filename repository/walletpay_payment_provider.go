@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+)
+
+type WalletpayPaymentProviderConfig struct {
+	WalletRepository        WalletRepository
+	PaymentRecordRepository PaymentRecordRepository
+}
+
+type walletpayPaymentProviderImpl struct {
+	walletRepository        WalletRepository
+	paymentRecordRepository PaymentRecordRepository
+}
+
+func NewWalletpayPaymentProvider(c WalletpayPaymentProviderConfig) PaymentProvider {
+	return &walletpayPaymentProviderImpl{
+		walletRepository:        c.WalletRepository,
+		paymentRecordRepository: c.PaymentRecordRepository,
+	}
+}
+
+func (p *walletpayPaymentProviderImpl) Code() string {
+	return dto.PAYMENT_METHOD_CODE_WALLET
+}
+
+func (p *walletpayPaymentProviderImpl) CreateCharge(req dto.PaymentChargeReqDTO) (*dto.PaymentChargeResDTO, error) {
+	redirectUrl, paymentId, paymentRecordId, err := p.walletRepository.MakePayment(req.AccountNumber, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PaymentChargeResDTO{
+		RedirectUrl:     redirectUrl,
+		PaymentId:       paymentId,
+		PaymentRecordId: paymentRecordId,
+	}, nil
+}
+
+// VerifyCallback is not offered by walletpay, the buyer confirms a wallet
+// payment through the walletpay endpoints which check the owner and amount.
+func (p *walletpayPaymentProviderImpl) VerifyCallback(req dto.PaymentCallbackReqDTO) (*dto.PaymentCallbackResDTO, error) {
+	return nil, domain.ErrPaymentProviderOperationNotSupported
+}
+
+// Cancel has nothing to release, the wallet is only debited once the payment succeeds.
+func (p *walletpayPaymentProviderImpl) Cancel(paymentId string) error {
+	return nil
+}
+
+// Refund is handled by the transaction refund flow which credits the wallet directly.
+func (p *walletpayPaymentProviderImpl) Refund(paymentId string, amount uint) error {
+	return domain.ErrPaymentProviderOperationNotSupported
+}
+
+func (p *walletpayPaymentProviderImpl) QueryStatus(paymentId string) (*dto.PaymentStatusResDTO, error) {
+	paymentRecord, err := p.paymentRecordRepository.GetByPaymentId(paymentId)
+	if err != nil {
+		return nil, err
+	}
+
+	status := dto.PAYMENT_STATUS_PENDING
+	if paymentRecord.PaidAt != nil {
+		status = dto.PAYMENT_STATUS_PAID
+	}
+	if paymentRecord.CanceledAt != nil {
+		status = dto.PAYMENT_STATUS_CANCELED
+	}
+
+	return &dto.PaymentStatusResDTO{
+		PaymentId: paymentId,
+		Amount:    uint(paymentRecord.Amount),
+		Status:    status,
+	}, nil
+}
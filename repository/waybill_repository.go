@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/config"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+)
+
+type WaybillRepository interface {
+	GetWaybill(input dto.RajaOngkirWaybillReqDTO) (*dto.RajaOngkirWaybillResDTO, error)
+}
+
+// WaybillRepositoryConfig allows replacing the HTTP client and base url,
+// e.g. to point the repository at a local stub server.
+type WaybillRepositoryConfig struct {
+	HttpClient *http.Client
+	Url        string
+}
+
+type waybillRepositoryImpl struct {
+	httpClient *http.Client
+	url        string
+}
+
+func NewWaybillRepository(c WaybillRepositoryConfig) WaybillRepository {
+	httpClient := c.HttpClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	baseUrl := c.Url
+	if baseUrl == "" {
+		baseUrl = config.Config.RajaOngkirConfig.Url
+	}
+
+	return &waybillRepositoryImpl{
+		httpClient: httpClient,
+		url:        baseUrl,
+	}
+}
+
+func (r *waybillRepositoryImpl) GetWaybill(input dto.RajaOngkirWaybillReqDTO) (*dto.RajaOngkirWaybillResDTO, error) {
+	reqBody, err := json.Marshal(input)
+	if err != nil {
+		return nil, domain.ErrGetWaybillInternalError
+	}
+	req, err := http.NewRequest(http.MethodPost, r.url+"/waybill", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, domain.ErrGetWaybillInternalError
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("key", config.Config.RajaOngkirConfig.ApiKey)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, domain.ErrGetWaybillInternalError
+	}
+	defer resp.Body.Close()
+
+	var output dto.RajaOngkirWaybillResDTO
+	if err := json.NewDecoder(resp.Body).Decode(&output); err != nil {
+		return nil, domain.ErrGetWaybillInternalError
+	}
+	if output.Rajaongkir.Status.Code == http.StatusBadRequest {
+		return nil, domain.ErrWaybillNotFound
+	}
+	if output.Rajaongkir.Status.Code != http.StatusOK {
+		return nil, domain.ErrGetWaybillInternalError
+	}
+
+	return &output, nil
+}
+
+// waybillStubRepositoryImpl fakes courier progress for local development:
+// a waybill is picked up when first queried, in transit an hour later and
+// delivered after two hours.
+type waybillStubRepositoryImpl struct {
+	mu        sync.Mutex
+	firstSeen map[string]time.Time
+}
+
+func NewWaybillStubRepository() WaybillRepository {
+	return &waybillStubRepositoryImpl{
+		firstSeen: map[string]time.Time{},
+	}
+}
+
+func (r *waybillStubRepositoryImpl) GetWaybill(input dto.RajaOngkirWaybillReqDTO) (*dto.RajaOngkirWaybillResDTO, error) {
+	key := input.Courier + ":" + input.Waybill
+
+	r.mu.Lock()
+	pickedUpAt, ok := r.firstSeen[key]
+	if !ok {
+		pickedUpAt = time.Now().Truncate(time.Minute)
+		r.firstSeen[key] = pickedUpAt
+	}
+	r.mu.Unlock()
+
+	checkpoints := []struct {
+		after       time.Duration
+		code        string
+		description string
+		city        string
+	}{
+		{0, "PICKUP", "SHIPMENT PICKED UP BY COURIER", "ORIGIN"},
+		{time.Hour, "TRANSIT", "SHIPMENT FORWARDED TO DESTINATION", "TRANSIT HUB"},
+		{2 * time.Hour, "DELIVERED", "SHIPMENT DELIVERED", "DESTINATION"},
+	}
+
+	var output dto.RajaOngkirWaybillResDTO
+	output.Rajaongkir.Status.Code = http.StatusOK
+	for _, checkpoint := range checkpoints {
+		occurredAt := pickedUpAt.Add(checkpoint.after)
+		if occurredAt.After(time.Now()) {
+			break
+		}
+
+		output.Rajaongkir.Result.Manifest = append(output.Rajaongkir.Result.Manifest, dto.RajaOngkirWaybillManifestDTO{
+			ManifestCode:        checkpoint.code,
+			ManifestDescription: checkpoint.description,
+			ManifestDate:        occurredAt.Format("2006-01-02"),
+			ManifestTime:        occurredAt.Format("15:04"),
+			CityName:            checkpoint.city,
+		})
+		output.Rajaongkir.Result.Delivered = checkpoint.code == "DELIVERED"
+	}
+
+	return &output, nil
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cache"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/handler"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
@@ -41,6 +42,15 @@ type RouterConfig struct {
 	MerchantAnalyticsUsecase         usecase.MerchantAnalyticsUsecase
 	PromotionBannerUsecase           usecase.PromotionBannerUsecase
 	PromotionUsecase                 usecase.PromotionUsecase
+	LedgerUsecase                    usecase.LedgerUsecase
+	ProductStockAlertUsecase         usecase.ProductStockAlertUsecase
+	BackInStockUsecase               usecase.BackInStockUsecase
+	NotificationUsecase              usecase.NotificationUsecase
+	EventStreamUsecase               usecase.EventStreamUsecase
+	ChatUsecase                      usecase.ChatUsecase
+	VoucherRuleUsecase               usecase.VoucherRuleUsecase
+	VoucherCodeUsecase               usecase.VoucherCodeUsecase
+	UserVoucherUsecase               usecase.UserVoucherUsecase
 }
 
 func NewRouter(c RouterConfig) *gin.Engine {
@@ -75,6 +85,15 @@ func NewRouter(c RouterConfig) *gin.Engine {
 		MerchantAnalyticsUsecase:         c.MerchantAnalyticsUsecase,
 		PromotionBannerUsecase:           c.PromotionBannerUsecase,
 		PromotionUsecase:                 c.PromotionUsecase,
+		LedgerUsecase:                    c.LedgerUsecase,
+		ProductStockAlertUsecase:         c.ProductStockAlertUsecase,
+		BackInStockUsecase:               c.BackInStockUsecase,
+		NotificationUsecase:              c.NotificationUsecase,
+		EventStreamUsecase:               c.EventStreamUsecase,
+		ChatUsecase:                      c.ChatUsecase,
+		VoucherRuleUsecase:               c.VoucherRuleUsecase,
+		VoucherCodeUsecase:               c.VoucherCodeUsecase,
+		UserVoucherUsecase:               c.UserVoucherUsecase,
 	})
 
 	r := gin.Default()
@@ -111,6 +130,7 @@ func NewRouter(c RouterConfig) *gin.Engine {
 	v1.POST("/login", h.UserLoginHandler)
 	v1.POST("/login/admin", h.AdminLoginHandler)
 	v1.GET("/refresh", h.UserRefreshHandler)
+	v1.GET("/events", middleware.Authenticate, middleware.Authorize(h), h.StreamEvents)
 	v1.POST("/logout", h.UserLogoutHandler)
 
 	userEndpoints := v1.Group("/users")
@@ -140,6 +160,20 @@ func NewRouter(c RouterConfig) *gin.Engine {
 	authEndpoints.POST("/pin", h.StepUpTokenScopeWithPin)
 	authEndpoints.POST("/pass", h.StepUpTokenScopeWithPass)
 
+	chatEndpoints := userEndpoints.Group("/chats")
+	chatEndpoints.POST("", h.StartChatConversation)
+	chatEndpoints.GET("", h.GetChatConversationList)
+	chatEndpoints.GET("/unread-count", h.GetUnreadChatCount)
+	chatEndpoints.GET("/:conversation_id/messages", h.GetChatMessageList)
+	chatEndpoints.POST("/:conversation_id/messages", h.SendChatMessage)
+	chatEndpoints.PATCH("/:conversation_id/read", h.MarkChatConversationRead)
+
+	notificationEndpoints := userEndpoints.Group("/notifications")
+	notificationEndpoints.GET("", h.GetNotificationList)
+	notificationEndpoints.GET("/unread-count", h.GetUnreadNotificationCount)
+	notificationEndpoints.PATCH("/read-all", h.MarkAllNotificationsRead)
+	notificationEndpoints.PATCH("/:notification_id/read", h.MarkNotificationRead)
+
 	userRefundRequest := userEndpoints.Group("/refund-requests")
 	userRefundRequest.POST("", h.AddRefundRequest)
 	userRefundRequest.GET("", h.GetUserRefundRequestList)
@@ -150,7 +184,7 @@ func NewRouter(c RouterConfig) *gin.Engine {
 	userRefundRequest.POST("/:refund_id/messages", h.BuyerAddMessageRequestRefund)
 
 	transactionEndpoints := userEndpoints.Group("/transactions")
-	transactionEndpoints.POST("", h.MakeTransaction)
+	transactionEndpoints.POST("", middleware.Idempotency(cache.GetClientRDB()), h.MakeTransaction)
 	transactionEndpoints.GET("", h.GetTransactionList)
 	transactionEndpoints.GET("/:invoice_code", h.GetTransactionDetail)
 	transactionEndpoints.PUT(":invoice_code/status", h.UpdateUserTransactionStatus)
@@ -174,15 +208,20 @@ func NewRouter(c RouterConfig) *gin.Engine {
 	merchantEndpoints.PATCH("/profile", h.UpdateMerchantProfile)
 	merchantEndpoints.POST("/products/images", h.UploadProductImage)
 	merchantEndpoints.GET("/products", h.GetMerchantProductList)
+	merchantEndpoints.GET("/products/low-stock", h.GetLowStockProductList)
 	merchantEndpoints.POST("/products", h.CreateProduct)
 	merchantEndpoints.GET("/products/:product_id", h.GetMerchantProductDetails)
 	merchantEndpoints.GET("/products/:product_id/variants", h.GetMerchantProductVariants)
 	merchantEndpoints.PUT("/products/:product_id", h.UpdateMerchantProduct)
 	merchantEndpoints.PATCH("/products/:product_id/status", h.UpdateProductAvailability)
+	merchantEndpoints.PUT("/products/:product_id/low-stock-threshold", h.UpdateLowStockThreshold)
 	merchantEndpoints.DELETE("/products/:product_id", h.DeleteMerchantProduct)
 	merchantEndpoints.POST("/products/check-name", h.CheckMerchantProductName)
 	merchantEndpoints.GET("/deliveries", h.GetMerchantUserDeliveryOption)
 	merchantEndpoints.PUT("/deliveries", h.ChangeMerchantUserDeliveryOption)
+	merchantEndpoints.GET("/deliveries/rules", h.GetMerchantShippingRules)
+	merchantEndpoints.POST("/deliveries/rules", h.CreateMerchantShippingRule)
+	merchantEndpoints.DELETE("/deliveries/rules/:rule_id", h.DeleteMerchantShippingRule)
 	merchantEndpoints.PUT("/transactions/:invoice_code/status", h.UpdateMerchantTransactionStatus)
 	merchantEndpoints.GET("/transactions", h.GetSellerTransactionList)
 	merchantEndpoints.GET("/transactions/:invoice_code", h.GetSellerTransactionDetail)
@@ -191,9 +230,14 @@ func NewRouter(c RouterConfig) *gin.Engine {
 	merchantEndpoints.GET("/vouchers/:voucher_code", h.GetMerchantAdminVoucherDetails)
 	merchantEndpoints.PUT("/vouchers/:voucher_code", h.UpdateMerchantVoucher)
 	merchantEndpoints.DELETE("/vouchers/:voucher_code", h.DeleteMerchantAdminVoucher)
+	merchantEndpoints.GET("/vouchers/:voucher_code/rules", h.GetMerchantVoucherRules)
+	merchantEndpoints.PUT("/vouchers/:voucher_code/rules", h.UpdateMerchantVoucherRules)
+	merchantEndpoints.POST("/vouchers/:voucher_code/codes", h.GenerateMerchantVoucherCodes)
+	merchantEndpoints.GET("/vouchers/:voucher_code/codes", h.GetMerchantVoucherCodes)
+	merchantEndpoints.GET("/vouchers/:voucher_code/codes/export", h.ExportMerchantVoucherCodes)
 	merchantEndpoints.GET("/funds/activities", h.GetMerchantFundActivities)
 	merchantEndpoints.GET("/funds/balance", h.GetMerchantFundBalance)
-	merchantEndpoints.POST("/funds/withdraw", h.WithdrawMerchantFundBalance)
+	merchantEndpoints.POST("/funds/withdraw", middleware.Idempotency(cache.GetClientRDB()), h.WithdrawMerchantFundBalance)
 	merchantEndpoints.PATCH("/addresses/:address_id", h.UpdateMerchantAddress)
 	merchantEndpoints.GET("/promotions", h.GetAllPromotions)
 	merchantEndpoints.GET("/promotions/:promotion_id", h.GetPromotionDetails)
@@ -203,6 +247,7 @@ func NewRouter(c RouterConfig) *gin.Engine {
 
 	merchantDashboardEndpoints := merchantEndpoints.Group("/dashboards")
 	merchantDashboardEndpoints.GET("responsiveness", h.GetMerchantDashboardMerchantResponsivenessStatistics)
+	merchantDashboardEndpoints.GET("responsiveness/percentiles", h.GetMerchantDashboardResponsivenessPercentiles)
 	merchantDashboardEndpoints.GET("sales", h.GetMerchantDashboardSalesStatistics)
 	merchantDashboardEndpoints.GET("customer-satisfactions", h.GetMerchantDashboardCustomerSatisfactionStatistics)
 
@@ -223,7 +268,7 @@ func NewRouter(c RouterConfig) *gin.Engine {
 	walletEndpoints.GET("/transactions", h.GetWalletTransactions)
 	walletEndpoints.POST("/create-pin", h.CreateWallet)
 	walletEndpoints.POST("/change-pin", middleware.AuthorizeAndBlacklist(h, dto.SCOPE_PASSWORD), h.UpdateWalletPin)
-	walletEndpoints.POST("/topup", h.MakeTopUpWalletSlp)
+	walletEndpoints.POST("/topup", middleware.Idempotency(cache.GetClientRDB()), h.MakeTopUpWalletSlp)
 
 	slpEndpoints := userEndpoints.Group("/slp-accounts")
 	slpEndpoints.GET("", h.GetUserSlpAccountList)
@@ -239,13 +284,22 @@ func NewRouter(c RouterConfig) *gin.Engine {
 	addressEndpoints.PUT("/:address_id", h.UpdateUserAddress)
 	addressEndpoints.DELETE("/:address_id", h.DeleteUserAddress)
 
+	userVoucherEndpoints := userEndpoints.Group("/vouchers")
+	userVoucherEndpoints.GET("", h.GetUserVouchers)
+	userVoucherEndpoints.POST("", h.ClaimVoucher)
+	userVoucherEndpoints.DELETE("/:user_voucher_id", h.DeleteUserVoucher)
+
 	productEndpoints := v1.Group("/products")
 	productEndpoints.GET("", h.GetProductList)
-	productEndpoints.GET("/recommendations", h.GetRecommendationProductList)
+	productEndpoints.GET("/suggest", h.GetProductSuggestions)
+	productEndpoints.GET("/recommendations", middleware.AuthenticateWithByPass, h.GetRecommendationProductList)
 	productEndpoints.GET("/:domain/:slug/variants", h.GetProductVariants)
+	productEndpoints.GET("/:domain/:slug/bought-together", h.GetBoughtTogetherProducts)
+	productEndpoints.GET("/:domain/:slug/similar", h.GetSimilarProducts)
 	productEndpoints.Use(middleware.AuthenticateWithByPass)
 	productEndpoints.GET("/:domain/:slug/details", h.GetProductDetails)
 	productEndpoints.GET("/:domain/:slug/reviews", h.GetProductReviewByProductSlug)
+	productEndpoints.POST("/:domain/:slug/variants/:variant_id/notify", middleware.Authenticate, middleware.Authorize(h, dto.ROLE_USER), h.SubscribeBackInStock)
 
 	categoryEndpoints := v1.Group("/categories")
 	categoryEndpoints.GET("", h.GetCategoryTree)
@@ -289,6 +343,11 @@ func NewRouter(c RouterConfig) *gin.Engine {
 	marketplaceEndpoints.GET("/vouchers/:voucher_code", h.GetMarketplaceVoucherDetails)
 	marketplaceEndpoints.PUT("/vouchers/:voucher_code", h.UpdateMarketplaceVoucher)
 	marketplaceEndpoints.DELETE("/vouchers/:voucher_code", h.DeleteMarketplaceVoucher)
+	marketplaceEndpoints.GET("/vouchers/:voucher_code/rules", h.GetMarketplaceVoucherRules)
+	marketplaceEndpoints.PUT("/vouchers/:voucher_code/rules", h.UpdateMarketplaceVoucherRules)
+	marketplaceEndpoints.POST("/vouchers/:voucher_code/codes", h.GenerateMarketplaceVoucherCodes)
+	marketplaceEndpoints.GET("/vouchers/:voucher_code/codes", h.GetMarketplaceVoucherCodes)
+	marketplaceEndpoints.GET("/vouchers/:voucher_code/codes/export", h.ExportMarketplaceVoucherCodes)
 
 	marketplaceCategoryEndpoints := marketplaceEndpoints.Group("/categories")
 	marketplaceCategoryEndpoints.POST("", h.CreateCategory)
@@ -305,6 +364,13 @@ func NewRouter(c RouterConfig) *gin.Engine {
 	marketplaceDashboardEndpoints.PATCH("", h.UpdateMarketplaceDashboard)
 	marketplaceDashboardEndpoints.PATCH("/merchants", h.UpdateMerchantDashboard)
 
+	marketplaceLedgerEndpoints := marketplaceEndpoints.Group("/ledger")
+	marketplaceLedgerEndpoints.GET("/reconciliation", h.GetLedgerReconciliation)
+
+	marketplacePaymentEndpoints := marketplaceEndpoints.Group("/payments")
+	marketplacePaymentEndpoints.GET("/sealabspay/callbacks", h.GetSealabspayCallbackList)
+	marketplacePaymentEndpoints.POST("/sealabspay/callbacks/:callback_id/replay", h.ReplaySealabspayCallback)
+
 	marketplaceRefundReqEndpoints := marketplaceEndpoints.Group("/refund-requests")
 	marketplaceRefundReqEndpoints.GET("", h.GetAdminRefundRequestList)
 	marketplaceRefundReqEndpoints.POST("/:refund_id/accept", h.AdminAcceptRequestRefund)
@@ -2,6 +2,7 @@ package server
 
 import (
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cache"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/config"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cronjob"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/db"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
@@ -28,7 +29,8 @@ func initRouter() *gin.Engine {
 		RDB: cache.GetClientRDB(),
 	})
 	deliveryRepo := repository.NewDeliveryRepository(repository.DeliveryRepositoryConfig{
-		DB: db.Get(),
+		DB:  db.Get(),
+		RDB: cache.GetClientRDB(),
 	})
 	merchantRepo := repository.NewMerchantRepository(repository.MerchantRepositoryConfig{
 		DB:                 db.Get(),
@@ -38,23 +40,40 @@ func initRouter() *gin.Engine {
 	merchantHoldingAccountHistoryRepo := repository.NewMerchantHoldingAccountHistoryRepository(repository.MerchantHoldingAccountHistoryRepositoryConfig{
 		DB: db.Get(),
 	})
+	ledgerRepo := repository.NewLedgerRepository(repository.LedgerRepositoryConfig{
+		DB: db.Get(),
+	})
 	walletRepo := repository.NewWalletRepository(repository.WalletRepositoryConfig{
 		DB:                      db.Get(),
 		PaymentRecordRepository: paymentRecordRepo,
+		LedgerRepository:        ledgerRepo,
 	})
 	merchantHoldingAccountRepo := repository.NewMerchantHoldingAccountRepository(repository.MerchantHoldingAccountRepositoryConfig{
 		DB:               db.Get(),
 		WalletRepository: walletRepo,
+		LedgerRepository: ledgerRepo,
 	})
-	productRepo := repository.NewProductRepository(repository.ProductRepositoryConfig{
+	notificationRepo := repository.NewNotificationRepository(repository.NotificationRepositoryConfig{
 		DB: db.Get(),
 	})
+	backInStockSubscriptionRepo := repository.NewBackInStockSubscriptionRepository(repository.BackInStockSubscriptionRepositoryConfig{
+		DB:                     db.Get(),
+		NotificationRepository: notificationRepo,
+	})
+	productRepo := repository.NewProductRepository(repository.ProductRepositoryConfig{
+		DB:                                db.Get(),
+		BackInStockSubscriptionRepository: backInStockSubscriptionRepo,
+	})
 	productVariantRepo := repository.NewProductVariantRepository(repository.ProductVariantRepositoryConfig{
 		DB: db.Get(),
 	})
 	categoryRepo := repository.NewCategoryRepository(repository.CategoryRepositoryConfig{
 		DB: db.Get(),
 	})
+	productStockAlertRepo := repository.NewProductStockAlertRepository(repository.ProductStockAlertRepositoryConfig{
+		DB:                     db.Get(),
+		NotificationRepository: notificationRepo,
+	})
 	cartItemRepo := repository.NewCartItemRepository(repository.CartItemRepositoryConfig{
 		DB: db.Get(),
 	})
@@ -76,21 +95,45 @@ func initRouter() *gin.Engine {
 		DB:                        db.Get(),
 		ProductAnalyticRepository: productAnalyticRepo,
 	})
-	userOrderRepo := repository.NewUserOrderRepository(repository.UserOrderRepositoryConfig{
+	stockReservationRepo := repository.NewStockReservationRepository(repository.StockReservationRepositoryConfig{
 		DB: db.Get(),
 	})
+	userOrderRepo := repository.NewUserOrderRepository(repository.UserOrderRepositoryConfig{
+		DB:                         db.Get(),
+		StockReservationRepository: stockReservationRepo,
+	})
 	slpAccountRepo := repository.NewSlpAccountsRepository(repository.SlpAccountsRepositoryConfig{
 		DB: db.Get(),
 	})
 	mpVoucherRepo := repository.NewMarketplaceVoucherRepository(repository.MarketplaceVoucherRepositoryConfig{
 		DB: db.Get(),
 	})
+	voucherRedemptionRepo := repository.NewVoucherRedemptionRepository(repository.VoucherRedemptionRepositoryConfig{
+		DB:  db.Get(),
+		RDB: cache.GetClientRDB(),
+	})
+	voucherRuleRepo := repository.NewVoucherRuleRepository(repository.VoucherRuleRepositoryConfig{
+		DB: db.Get(),
+	})
+	voucherCodeRepo := repository.NewVoucherCodeRepository(repository.VoucherCodeRepositoryConfig{
+		DB: db.Get(),
+	})
+	userVoucherRepo := repository.NewUserVoucherRepository(repository.UserVoucherRepositoryConfig{
+		DB: db.Get(),
+	})
 	transactionStatusRepo := repository.NewTransactionStatusRepository(repository.TransactionStatusRepositoryConfig{
 		DB: db.Get(),
 	})
 	transactionDeliveryStatusRepo := repository.NewTransactionDeliveryStatusRepository(repository.TransactionDeliveryStatusRepositoryConfig{
 		DB: db.Get(),
 	})
+	transactionTrackingRepo := repository.NewTransactionTrackingRepository(repository.TransactionTrackingRepositoryConfig{
+		DB: db.Get(),
+	})
+	waybillRepo := repository.NewWaybillRepository(repository.WaybillRepositoryConfig{})
+	if config.Config.RajaOngkirConfig.WaybillStub {
+		waybillRepo = repository.NewWaybillStubRepository()
+	}
 	transactionPaymentRecordRepo := repository.NewTransactionPaymentRecordRepository(repository.TransactionPaymentRecordRepositoryConfig{
 		DB: db.Get(),
 	})
@@ -99,7 +142,7 @@ func initRouter() *gin.Engine {
 	})
 	transactionRepo := repository.NewTransactionRepository(repository.TransactionRepositoryConfig{
 		DB:                                      db.Get(),
-		MarketplaceVoucherRepository:            mpVoucherRepo,
+		VoucherRedemptionRepository:             voucherRedemptionRepo,
 		MerchantRepository:                      merchantRepo,
 		ProductRepository:                       productRepo,
 		PaymentRecordRepository:                 paymentRecordRepo,
@@ -109,6 +152,8 @@ func initRouter() *gin.Engine {
 		TransactionPaymentRecordRepository:      transactionPaymentRecordRepo,
 		MerchantHoldingAccountRepository:        merchantHoldingAccountRepo,
 		MerchantHoldingAccountHistoryRepository: merchantHoldingAccountHistoryRepo,
+		LedgerRepository:                        ledgerRepo,
+		StockReservationRepository:              stockReservationRepo,
 	})
 	transactionStatusRepo = repository.NewTransactionStatusRepository(repository.TransactionStatusRepositoryConfig{
 		DB:                       db.Get(),
@@ -128,6 +173,28 @@ func initRouter() *gin.Engine {
 		DB: db.Get(),
 	})
 	sealabspayRepo := repository.NewSealabspayRepository(repository.SealabspayRepositoryConfig{})
+	productSuggestionRepo := repository.NewProductSuggestionRepository(repository.ProductSuggestionRepositoryConfig{
+		DB:  db.Get(),
+		RDB: cache.GetClientRDB(),
+	})
+	productRecommendationRepo := repository.NewProductRecommendationRepository(repository.ProductRecommendationRepositoryConfig{
+		DB:  db.Get(),
+		RDB: cache.GetClientRDB(),
+	})
+	sealabspayCallbackRepo := repository.NewSealabspayCallbackRepository(repository.SealabspayCallbackRepositoryConfig{
+		DB: db.Get(),
+	})
+	paymentProviderRegistry := repository.NewPaymentProviderRegistry(repository.PaymentProviderRegistryConfig{
+		Providers: []repository.PaymentProvider{
+			repository.NewSealabspayPaymentProvider(repository.SealabspayPaymentProviderConfig{
+				SealabspayRepository: sealabspayRepo,
+			}),
+			repository.NewWalletpayPaymentProvider(repository.WalletpayPaymentProviderConfig{
+				WalletRepository:        walletRepo,
+				PaymentRecordRepository: paymentRecordRepo,
+			}),
+		},
+	})
 	marketplaceAnalyticsRepo := repository.NewMarketplaceAnalyticsRepository(repository.MarketplaceAnalyticsRepositoryConfig{
 		DB: db.Get(),
 	})
@@ -153,13 +220,30 @@ func initRouter() *gin.Engine {
 		WalletRepository: walletRepo,
 		AuthUtil:         authUtil,
 	})
+	chatRepo := repository.NewChatRepository(repository.ChatRepositoryConfig{
+		DB: db.Get(),
+	})
+	eventStreamRepo := repository.NewEventStreamRepository(repository.EventStreamRepositoryConfig{
+		RDB: cache.GetClientRDB(),
+	})
+	eventStreamUsecase := usecase.NewEventStreamUsecase(usecase.EventStreamUsecaseConfig{
+		EventStreamRepository: eventStreamRepo,
+		UserRepository:        userRepo,
+		MerchantRepository:    merchantRepo,
+	})
+	notificationUsecase := usecase.NewNotificationUsecase(usecase.NotificationUsecaseConfig{
+		NotificationRepository: notificationRepo,
+		UserRepository:         userRepo,
+	})
 	merchantUsecase := usecase.NewMerchantUsecase(usecase.MerchantUsecaseConfig{
 		MerchantRepository:                      merchantRepo,
 		CategoryRepository:                      categoryRepo,
 		UserRepository:                          userRepo,
 		MerchantHoldingAccountHistoryRepository: merchantHoldingAccountHistoryRepo,
 		MerchantHoldingAccountRepository:        merchantHoldingAccountRepo,
+		VoucherRedemptionRepository:             voucherRedemptionRepo,
 		GcsUploader:                             gscUploader,
+		NotificationUsecase:                     notificationUsecase,
 	})
 	mediaUsecase := usecase.NewMediaUsecase(usecase.MediaUsecaseConfig{
 		GCSUploader: gscUploader,
@@ -170,12 +254,29 @@ func initRouter() *gin.Engine {
 		MerchantRepository: merchantRepo,
 		MediaUsecase:       mediaUsecase,
 	})
+	productStockAlertUsecase := usecase.NewProductStockAlertUsecase(usecase.ProductStockAlertUsecaseConfig{
+		ProductStockAlertRepository: productStockAlertRepo,
+		ProductRepository:           productRepo,
+		MerchantRepository:          merchantRepo,
+		Cron:                        cronjob.GetCron(),
+	})
 	productUsecase := usecase.NewProductUsecase(usecase.ProductUsecaseConfig{
 		ProductRepository:  productRepo,
 		CategoryRepository: categoryRepo,
 		UserRepository:     userRepo,
 		MerchantRepository: merchantRepo,
 		MediaUsecase:       mediaUsecase,
+
+		ProductSuggestionRepository:     productSuggestionRepo,
+		ProductRecommendationRepository: productRecommendationRepo,
+		Cron:                            cronjob.GetCron(),
+	})
+	backInStockUsecase := usecase.NewBackInStockUsecase(usecase.BackInStockUsecaseConfig{
+		BackInStockSubscriptionRepository: backInStockSubscriptionRepo,
+		UserRepository:                    userRepo,
+		ProductRepository:                 productRepo,
+		ProductVariantRepository:          productVariantRepo,
+		Cron:                              cronjob.GetCron(),
 	})
 	productVariantUsecase := usecase.NewProductVariantUsecase(usecase.ProductVariantUsecaseConfig{
 		ProductVariantRepository: productVariantRepo,
@@ -202,6 +303,24 @@ func initRouter() *gin.Engine {
 	addressUsecase := usecase.NewAddressUsecase(usecase.AddressUsecaseConfig{
 		AddressRepository: addressRepo,
 	})
+	voucherRuleUsecase := usecase.NewVoucherRuleUsecase(usecase.VoucherRuleUsecaseConfig{
+		VoucherRuleRepository:        voucherRuleRepo,
+		MarketplaceVoucherRepository: mpVoucherRepo,
+		MerchantRepository:           merchantRepo,
+	})
+	voucherCodeUsecase := usecase.NewVoucherCodeUsecase(usecase.VoucherCodeUsecaseConfig{
+		VoucherCodeRepository:        voucherCodeRepo,
+		MarketplaceVoucherRepository: mpVoucherRepo,
+		MerchantRepository:           merchantRepo,
+	})
+	userVoucherUsecase := usecase.NewUserVoucherUsecase(usecase.UserVoucherUsecaseConfig{
+		UserVoucherRepository:        userVoucherRepo,
+		UserRepository:               userRepo,
+		MarketplaceVoucherRepository: mpVoucherRepo,
+		MerchantRepository:           merchantRepo,
+		CartItemUsecase:              cartItemUsecase,
+		VoucherRuleUsecase:           voucherRuleUsecase,
+	})
 	orderItemUsecase := usecase.NewOrderItemUsecase(usecase.OrderItemUsecaseConfig{
 		OrderItemRepository:          orderItemRepo,
 		CartItemRepository:           cartItemRepo,
@@ -212,22 +331,38 @@ func initRouter() *gin.Engine {
 		DeliveryRepository:           deliveryRepo,
 		AddressRepository:            addressRepo,
 		MarketplaceVoucherRepository: mpVoucherRepo,
+		VoucherCodeRepository:        voucherCodeRepo,
+		UserVoucherRepository:        userVoucherRepo,
 		MerchantRepository:           merchantRepo,
+		StockReservationRepository:   stockReservationRepo,
+		VoucherRuleUsecase:           voucherRuleUsecase,
+		Cron:                         cronjob.GetCron(),
 	})
 	slpAccountUsecase := usecase.NewSlpAccountUsecase(usecase.SlpAccountUsecaseConfig{
 		UserRepository:        userRepo,
 		SlpAccountsRepository: slpAccountRepo,
 	})
 	mpVoucherUsecase := usecase.NewMarketplaceVoucherUsecase(usecase.MarketplaceVoucherUsecaseConfig{
-		MarketplaceVoucherRepo: mpVoucherRepo,
+		MarketplaceVoucherRepo:      mpVoucherRepo,
+		VoucherRedemptionRepository: voucherRedemptionRepo,
 	})
 	transactionStatusUsecase := usecase.NewTransactionStatusUsecase(usecase.TransactionStatusUsecaseConfig{
 		TransactionStatusRepo: transactionStatusRepo,
+		NotificationUsecase:   notificationUsecase,
+		EventStreamUsecase:    eventStreamUsecase,
 		Cron:                  cronjob.GetCron(),
 	})
 	transactionDeliveryStatusUsecase := usecase.NewTransactionDeliveryStatusUsecase(usecase.TransactionDeliveryStatusUsecaseConfig{
 		TransactionDeliveryStatusRepo: transactionDeliveryStatusRepo,
 	})
+	transactionTrackingUsecase := usecase.NewTransactionTrackingUsecase(usecase.TransactionTrackingUsecaseConfig{
+		TransactionTrackingRepository: transactionTrackingRepo,
+		WaybillRepository:             waybillRepo,
+		DeliveryRepository:            deliveryRepo,
+		NotificationUsecase:           notificationUsecase,
+		EventStreamUsecase:            eventStreamUsecase,
+		Cron:                          cronjob.GetCron(),
+	})
 	transactionUsecase := usecase.NewTransactionUsecase(usecase.TransactionUsecaseConfig{
 		CartItemRepository:                  cartItemRepo,
 		TransactionRepository:               transactionRepo,
@@ -235,12 +370,15 @@ func initRouter() *gin.Engine {
 		MerchantRepository:                  merchantRepo,
 		TransactionStatusUsecase:            transactionStatusUsecase,
 		TransactionDeliveryStatusUsecase:    transactionDeliveryStatusUsecase,
+		TransactionTrackingUsecase:          transactionTrackingUsecase,
 		TransactionDeliveryStatusRepository: transactionDeliveryStatusRepo,
 		TransactionStatusRepository:         transactionStatusRepo,
 		OrderItemUsecase:                    orderItemUsecase,
-		SealabspayRepository:                sealabspayRepo,
 		PaymentMethodRepository:             paymentMethodRepo,
-		WalletRepository:                    walletRepo,
+		VoucherRedemptionRepository:         voucherRedemptionRepo,
+		PaymentProviderRegistry:             paymentProviderRegistry,
+		NotificationUsecase:                 notificationUsecase,
+		EventStreamUsecase:                  eventStreamUsecase,
 	})
 	paymentMethodUsecase := usecase.NewPaymentMethodUsecase(usecase.PaymentMethodUsecaseConfig{
 		PaymentMethodRepository: paymentMethodRepo,
@@ -252,13 +390,25 @@ func initRouter() *gin.Engine {
 		GCSUploader:             gscUploader,
 		MerchantRepository:      merchantRepo,
 		WalletRepository:        walletRepo,
+		NotificationUsecase:     notificationUsecase,
+		EventStreamUsecase:      eventStreamUsecase,
 		Cron:                    cronjob.GetCron(),
 	})
+	chatUsecase := usecase.NewChatUsecase(usecase.ChatUsecaseConfig{
+		ChatRepository:        chatRepo,
+		UserRepository:        userRepo,
+		MerchantRepository:    merchantRepo,
+		ProductRepository:     productRepo,
+		TransactionRepository: transactionRepo,
+		MediaUsecase:          mediaUsecase,
+		EventStreamUsecase:    eventStreamUsecase,
+	})
 	refundRequestMessageUsecase := usecase.NewRefundRequestMessageUsecase(usecase.RefundRequestMessageUsecaseConfig{
 		RefundRequestRepository:        refundRequestRepo,
 		RefundRequestMessageRepository: refundRequestMessageRepo,
 		GcsUploader:                    gscUploader,
 		UserRepository:                 userRepo,
+		EventStreamUsecase:             eventStreamUsecase,
 	})
 	deliveryUsecase := usecase.NewDeliveryUsecase(usecase.DeliveryUsecaseConfig{
 		DeliveryRepository: deliveryRepo,
@@ -269,6 +419,7 @@ func initRouter() *gin.Engine {
 		WalletRepository:     walletRepo,
 		UserRepository:       userRepo,
 		SealabspayRepository: sealabspayRepo,
+		NotificationUsecase:  notificationUsecase,
 	})
 
 	promotionBannerUsecase := usecase.NewPromotionBannerUsecase(usecase.PromotionBannerUsecaseConfig{
@@ -289,16 +440,23 @@ func initRouter() *gin.Engine {
 		UserRepository:                     userRepo,
 		TransactionUsecase:                 transactionUsecase,
 		PaymentRecordRepository:            paymentRecordRepo,
+		PaymentProviderRegistry:            paymentProviderRegistry,
+		Cron:                               cronjob.GetCron(),
 	})
 
 	sealabspayUsecase := usecase.NewSealabspayUsecase(usecase.SealabspayUsecaseConfig{
-		PaymentRecordUsecase: paymentRecordUsecase,
+		PaymentRecordUsecase:         paymentRecordUsecase,
+		PaymentProviderRegistry:      paymentProviderRegistry,
+		SealabspayCallbackRepository: sealabspayCallbackRepo,
+		Cron:                         cronjob.GetCron(),
 	})
 
 	walletpayUsecase := usecase.NewWalletpayUsecase(usecase.WalletpayUsecaseConfig{
-		WalletRepository:     walletRepo,
-		UserRepository:       userRepo,
-		PaymentRecordUsecase: paymentRecordUsecase,
+		WalletRepository:                   walletRepo,
+		UserRepository:                     userRepo,
+		PaymentRecordRepository:            paymentRecordRepo,
+		TransactionPaymentRecordRepository: transactionPaymentRecordRepo,
+		PaymentRecordUsecase:               paymentRecordUsecase,
 	})
 
 	marketplaceAnalyticsUsecase := usecase.NewMarketplaceAnalyticsUsecase(usecase.MarketplaceAnalyticsUsecaseConfig{
@@ -310,6 +468,11 @@ func initRouter() *gin.Engine {
 		MerchantRepository:          merchantRepo,
 	})
 
+	ledgerUsecase := usecase.NewLedgerUsecase(usecase.LedgerUsecaseConfig{
+		LedgerRepository: ledgerRepo,
+		Cron:             cronjob.GetCron(),
+	})
+
 	r := NewRouter(RouterConfig{
 		ExampleUsecase:                   exampleUsecase,
 		UserUsecase:                      userUsecase,
@@ -341,6 +504,15 @@ func initRouter() *gin.Engine {
 		MerchantAnalyticsUsecase:         merchantAnalyticsUsecase,
 		PromotionBannerUsecase:           promotionBannerUsecase,
 		PromotionUsecase:                 promotionUsecase,
+		LedgerUsecase:                    ledgerUsecase,
+		ProductStockAlertUsecase:         productStockAlertUsecase,
+		BackInStockUsecase:               backInStockUsecase,
+		NotificationUsecase:              notificationUsecase,
+		EventStreamUsecase:               eventStreamUsecase,
+		ChatUsecase:                      chatUsecase,
+		VoucherRuleUsecase:               voucherRuleUsecase,
+		VoucherCodeUsecase:               voucherCodeUsecase,
+		UserVoucherUsecase:               userVoucherUsecase,
 	})
 	return r
 }
@@ -354,3 +526,16 @@ func Init() {
 		return
 	}
 }
+
+func ReconcileLedger() {
+	ledgerUsecase := usecase.NewLedgerUsecase(usecase.LedgerUsecaseConfig{
+		LedgerRepository: repository.NewLedgerRepository(repository.LedgerRepositoryConfig{
+			DB: db.Get(),
+		}),
+	})
+
+	_, err := ledgerUsecase.ReconcileBalances()
+	if err != nil {
+		log.Fatal().Msgf("error while reconciling ledger %v", err)
+	}
+}
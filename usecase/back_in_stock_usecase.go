@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/config"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cronjob"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"github.com/rs/zerolog/log"
+)
+
+type BackInStockUsecase interface {
+	Subscribe(username string, slug string, variantItemId uint) (*dto.BackInStockSubscriptionResDTO, error)
+	CronNotifyBackInStock()
+}
+
+type BackInStockUsecaseConfig struct {
+	BackInStockSubscriptionRepository repository.BackInStockSubscriptionRepository
+	UserRepository                    repository.UserRepository
+	ProductRepository                 repository.ProductRepository
+	ProductVariantRepository          repository.ProductVariantRepository
+	Cron                              *cronjob.CronJob
+}
+
+type backInStockUsecaseImpl struct {
+	backInStockSubscriptionRepository repository.BackInStockSubscriptionRepository
+	userRepository                    repository.UserRepository
+	productRepository                 repository.ProductRepository
+	productVariantRepository          repository.ProductVariantRepository
+}
+
+func NewBackInStockUsecase(c BackInStockUsecaseConfig) BackInStockUsecase {
+	backInStockUsecase := &backInStockUsecaseImpl{
+		backInStockSubscriptionRepository: c.BackInStockSubscriptionRepository,
+		userRepository:                    c.UserRepository,
+		productRepository:                 c.ProductRepository,
+		productVariantRepository:          c.ProductVariantRepository,
+	}
+
+	if c.Cron != nil {
+		_, err := c.Cron.AddJob("* * * * *", backInStockUsecase.CronNotifyBackInStock)
+		if err != nil {
+			log.Error().Msg("error scheduling back in stock notification")
+		} else {
+			log.Info().Msg("back in stock notification scheduled")
+		}
+	}
+
+	return backInStockUsecase
+}
+
+func (u *backInStockUsecaseImpl) Subscribe(username string, slug string, variantItemId uint) (*dto.BackInStockSubscriptionResDTO, error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := u.productRepository.GetProductBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	variantItem, err := u.productVariantRepository.GetVariantItemById(variantItemId)
+	if err != nil {
+		return nil, err
+	}
+	if variantItem.ID == 0 || variantItem.ProductId != product.ID {
+		return nil, domain.ErrBackInStockVariantNotFound
+	}
+
+	if variantItem.Stock > 0 {
+		return nil, domain.ErrBackInStockVariantInStock
+	}
+
+	subscription, err := u.backInStockSubscriptionRepository.Create(entity.BackInStockSubscription{
+		UserId:        user.ID,
+		ProductId:     product.ID,
+		VariantItemId: variantItem.ID,
+		VariantName:   variantName(variantItem.VariantSpecs),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.BackInStockSubscriptionResDTO{
+		ID:            subscription.ID,
+		ProductId:     subscription.ProductId,
+		VariantItemId: subscription.VariantItemId,
+		VariantName:   subscription.VariantName,
+		CreatedAt:     subscription.CreatedAt,
+	}, nil
+}
+
+func (u *backInStockUsecaseImpl) CronNotifyBackInStock() {
+	subscriptions, err := u.backInStockSubscriptionRepository.ClaimTriggered(dto.BACK_IN_STOCK_NOTIFY_BATCH_SIZE)
+	if err != nil {
+		log.Error().Msgf("CronNotifyBackInStock Error: %v", err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		err = u.sendBackInStockEmail(subscription)
+		if err != nil {
+			log.Error().Msgf("CronNotifyBackInStock subscription %d email: %v", subscription.SubscriptionId, err)
+		}
+	}
+}
+
+func (u *backInStockUsecaseImpl) sendBackInStockEmail(subscription dto.BackInStockNotificationDTO) error {
+	b, err := ioutil.ReadFile(dto.SMTP_BACK_IN_STOCK_HTML_PATH)
+	if err != nil {
+		return err
+	}
+
+	productName := subscription.ProductTitle
+	if subscription.VariantName != "" {
+		productName = fmt.Sprintf("%s (%s)", subscription.ProductTitle, subscription.VariantName)
+	}
+	url := config.Config.WebUrlUser + "/" + subscription.ProductSlug
+
+	body := strings.Replace(string(b), "{{PRODUCT_NAME}}", html.EscapeString(productName), 1)
+	body = strings.Replace(body, "{{URL}}", url, 1)
+
+	return util.SMTPSendMail(util.Mail{
+		SenderAddress: config.Config.SmtpConfig.EmailAddress,
+		SenderName:    dto.SMTP_BACK_IN_STOCK_SENDER_NAME,
+		ToAddress:     subscription.Email,
+		Subject:       dto.SMTP_BACK_IN_STOCK_SUBJECT,
+		Body:          body,
+	})
+}
+
+// variantName joins the variation names in creation order, the same way the
+// restock trigger matches recreated variant items.
+func variantName(variantSpecs []entity.VariantSpec) string {
+	specs := make([]entity.VariantSpec, len(variantSpecs))
+	copy(specs, variantSpecs)
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].ID < specs[j].ID
+	})
+
+	names := make([]string, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.VariationName
+	}
+
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,324 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+)
+
+type ChatUsecase interface {
+	StartConversation(username string, req dto.StartChatConversationReqDTO) (*dto.ChatConversationDTO, error)
+	GetConversationList(username string, req dto.ChatConversationListReqParamDTO) (*dto.ChatConversationListResDTO, error)
+	GetUnreadChatCount(username string) (*dto.ChatUnreadCountResDTO, error)
+
+	GetMessageList(username string, conversationId uint, req dto.PaginationRequest) (*dto.ChatMessageListResDTO, error)
+	SendMessage(username string, conversationId uint, req dto.ChatMessageFormReqDTO) (*dto.ChatMessageDTO, error)
+	MarkConversationRead(username string, conversationId uint) (*dto.ChatUnreadCountResDTO, error)
+}
+
+type ChatUsecaseConfig struct {
+	ChatRepository        repository.ChatRepository
+	UserRepository        repository.UserRepository
+	MerchantRepository    repository.MerchantRepository
+	ProductRepository     repository.ProductRepository
+	TransactionRepository repository.TransactionRepository
+	MediaUsecase          MediaUsecase
+	EventStreamUsecase    EventStreamUsecase
+}
+
+type chatUsecaseImpl struct {
+	chatRepository        repository.ChatRepository
+	userRepository        repository.UserRepository
+	merchantRepository    repository.MerchantRepository
+	productRepository     repository.ProductRepository
+	transactionRepository repository.TransactionRepository
+	mediaUsecase          MediaUsecase
+	eventStreamUsecase    EventStreamUsecase
+}
+
+func NewChatUsecase(c ChatUsecaseConfig) ChatUsecase {
+	return &chatUsecaseImpl{
+		chatRepository:        c.ChatRepository,
+		userRepository:        c.UserRepository,
+		merchantRepository:    c.MerchantRepository,
+		productRepository:     c.ProductRepository,
+		transactionRepository: c.TransactionRepository,
+		mediaUsecase:          c.MediaUsecase,
+		eventStreamUsecase:    c.EventStreamUsecase,
+	}
+}
+
+func (u *chatUsecaseImpl) StartConversation(username string, req dto.StartChatConversationReqDTO) (*dto.ChatConversationDTO, error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	merchant, err := u.merchantRepository.GetByDomain(req.MerchantDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	if merchant.UserId == user.ID {
+		return nil, domain.ErrChatWithOwnMerchant
+	}
+
+	conversation := entity.ChatConversation{
+		MerchantDomain: merchant.Domain,
+		BuyerId:        user.ID,
+	}
+
+	if req.ProductId != nil {
+		product, err := u.productRepository.GetProductByProductId(*req.ProductId)
+		if err != nil {
+			return nil, err
+		}
+		if product.MerchantDomain != merchant.Domain {
+			return nil, domain.ErrChatProductNotInMerchant
+		}
+		conversation.ProductId = &product.ID
+	}
+
+	if req.InvoiceCode != nil {
+		transaction, err := u.transactionRepository.GetTransactionDetailByInvoiceCode(user.ID, *req.InvoiceCode)
+		if err != nil {
+			return nil, err
+		}
+		if transaction.MerchantDomain != merchant.Domain {
+			return nil, domain.ErrChatTransactionNotInMerchant
+		}
+		conversation.TransactionId = &transaction.ID
+	}
+
+	createdConversation, err := u.chatRepository.GetOrCreateConversation(conversation, merchant.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ChatConversationDTO{
+		ID:             createdConversation.ID,
+		Role:           dto.CHAT_ROLE_BUYER,
+		MerchantDomain: merchant.Domain,
+		Counterpart: dto.ChatCounterpartDTO{
+			Name:     merchant.Name,
+			ImageUrl: merchant.ImageUrl,
+		},
+		ProductId:     createdConversation.ProductId,
+		InvoiceCode:   req.InvoiceCode,
+		LastMessageAt: createdConversation.LastMessageAt,
+		CreatedAt:     createdConversation.CreatedAt,
+	}, nil
+}
+
+func (u *chatUsecaseImpl) GetConversationList(username string, req dto.ChatConversationListReqParamDTO) (*dto.ChatConversationListResDTO, error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	conversations, totalData, err := u.chatRepository.GetConversationList(user.ID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	conversationIds := make([]uint, 0, len(conversations))
+	for _, conversation := range conversations {
+		conversationIds = append(conversationIds, conversation.ID)
+	}
+
+	lastMessages, err := u.chatRepository.GetLastMessages(conversationIds)
+	if err != nil {
+		return nil, err
+	}
+
+	unreadCounts, err := u.chatRepository.GetUnreadCounts(user.ID, conversationIds)
+	if err != nil {
+		return nil, err
+	}
+
+	totalUnread, err := u.chatRepository.CountUnread(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	conversationDTOs := make([]dto.ChatConversationDTO, 0, len(conversations))
+	for _, conversation := range conversations {
+		conversationDTO := dto.ChatConversationDTO{
+			ID:             conversation.ID,
+			Role:           dto.CHAT_ROLE_BUYER,
+			MerchantDomain: conversation.MerchantDomain,
+			Counterpart: dto.ChatCounterpartDTO{
+				Name:     conversation.Merchant.Name,
+				ImageUrl: conversation.Merchant.ImageUrl,
+			},
+			ProductId:     conversation.ProductId,
+			UnreadCount:   unreadCounts[conversation.ID],
+			LastMessageAt: conversation.LastMessageAt,
+			CreatedAt:     conversation.CreatedAt,
+		}
+
+		if conversation.BuyerId != user.ID {
+			conversationDTO.Role = dto.CHAT_ROLE_MERCHANT
+			conversationDTO.Counterpart = dto.ChatCounterpartDTO{
+				Name: conversation.Buyer.Username,
+			}
+			if conversation.Buyer.UserDetail.ProfilePicture != nil {
+				conversationDTO.Counterpart.ImageUrl = *conversation.Buyer.UserDetail.ProfilePicture
+			}
+		}
+		if conversation.Product != nil {
+			conversationDTO.ProductTitle = &conversation.Product.Title
+		}
+		if conversation.Transaction != nil {
+			conversationDTO.InvoiceCode = &conversation.Transaction.InvoiceCode
+		}
+		if lastMessage, ok := lastMessages[conversation.ID]; ok {
+			lastMessageDTO := u.buildChatMessageDTO(lastMessage)
+			conversationDTO.LastMessage = &lastMessageDTO
+		}
+
+		conversationDTOs = append(conversationDTOs, conversationDTO)
+	}
+
+	return &dto.ChatConversationListResDTO{
+		PaginationResponse: dto.PaginationResponse{
+			TotalData:   totalData,
+			TotalPage:   (totalData + int64(req.Limit) - 1) / int64(req.Limit),
+			CurrentPage: req.Page,
+		},
+		UnreadCount:   totalUnread,
+		Conversations: conversationDTOs,
+	}, nil
+}
+
+func (u *chatUsecaseImpl) GetUnreadChatCount(username string) (*dto.ChatUnreadCountResDTO, error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	unreadCount, err := u.chatRepository.CountUnread(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ChatUnreadCountResDTO{
+		UnreadCount: unreadCount,
+	}, nil
+}
+
+func (u *chatUsecaseImpl) GetMessageList(username string, conversationId uint, req dto.PaginationRequest) (*dto.ChatMessageListResDTO, error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, err = u.chatRepository.GetConversationByUserId(conversationId, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, totalData, err := u.chatRepository.GetMessages(conversationId, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// opening the latest page means the user has seen the conversation
+	if req.Page == 1 {
+		err = u.chatRepository.MarkRead(conversationId, user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	messageDTOs := make([]dto.ChatMessageDTO, 0, len(messages))
+	for _, message := range messages {
+		messageDTOs = append(messageDTOs, u.buildChatMessageDTO(message))
+	}
+
+	return &dto.ChatMessageListResDTO{
+		PaginationResponse: dto.PaginationResponse{
+			TotalData:   totalData,
+			TotalPage:   (totalData + int64(req.Limit) - 1) / int64(req.Limit),
+			CurrentPage: req.Page,
+		},
+		Messages: messageDTOs,
+	}, nil
+}
+
+func (u *chatUsecaseImpl) SendMessage(username string, conversationId uint, req dto.ChatMessageFormReqDTO) (*dto.ChatMessageDTO, error) {
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" && req.Image == nil {
+		return nil, domain.ErrChatMessageEmpty
+	}
+
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation, participant, err := u.chatRepository.GetConversationByUserId(conversationId, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	message := entity.ChatMessage{
+		ConversationId: conversation.ID,
+		SenderId:       user.ID,
+		SenderRole:     participant.Role,
+		Message:        req.Message,
+	}
+
+	if req.Image != nil {
+		imageUrl, err := u.mediaUsecase.UploadFileForBinding(*req.Image, fmt.Sprintf("chat-%d-%d-%d", conversation.ID, user.ID, time.Now().UnixNano()))
+		if err != nil {
+			return nil, err
+		}
+		message.ImageUrl = &imageUrl
+	}
+
+	createdMessage, err := u.chatRepository.CreateMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	messageDTO := u.buildChatMessageDTO(*createdMessage)
+	u.eventStreamUsecase.PublishChatMessage(*conversation, messageDTO)
+
+	return &messageDTO, nil
+}
+
+func (u *chatUsecaseImpl) MarkConversationRead(username string, conversationId uint) (*dto.ChatUnreadCountResDTO, error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, err = u.chatRepository.GetConversationByUserId(conversationId, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.chatRepository.MarkRead(conversationId, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.GetUnreadChatCount(username)
+}
+
+func (u *chatUsecaseImpl) buildChatMessageDTO(message entity.ChatMessage) dto.ChatMessageDTO {
+	return dto.ChatMessageDTO{
+		ID:             message.ID,
+		ConversationId: message.ConversationId,
+		SenderRole:     message.SenderRole,
+		Message:        message.Message,
+		ImageUrl:       message.ImageUrl,
+		CreatedAt:      message.CreatedAt,
+	}
+}
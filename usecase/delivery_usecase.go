@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
@@ -11,6 +12,10 @@ type DeliveryUsecase interface {
 	GetDeliveryOptionByMerchantDomain(domain string) (*dto.DeliveryGetMerchantOptionResDTO, error)
 	GetMerchantDeliveryOption(username string) ([]dto.DeliveryOptionUserMerchantResDTO, error)
 	UpdateMerchantDeliveryOption(username string, input []dto.DeliveryUpdateMerchantOptionReqDTO) ([]dto.DeliveryUpdateMerchantOptionResDTO, error)
+
+	GetMerchantShippingRules(username string) ([]dto.MerchantShippingRuleResDTO, error)
+	CreateMerchantShippingRule(username string, input dto.MerchantShippingRuleReqDTO) (*dto.MerchantShippingRuleResDTO, error)
+	DeleteMerchantShippingRule(username string, ruleId uint) error
 }
 
 type DeliveryUsecaseConfig struct {
@@ -185,3 +190,123 @@ func (u *deliveryUsecaseImpl) UpdateMerchantDeliveryOption(username string, inpu
 
 	return resBody, nil
 }
+
+func (u *deliveryUsecaseImpl) GetMerchantShippingRules(username string) ([]dto.MerchantShippingRuleResDTO, error) {
+	merchant, err := u.merchantRepository.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	merchantDeliveryOptions, err := u.deliveryRepository.GetMerchantDeliveryOptionsByMerchantID(merchant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.MerchantShippingRuleResDTO, 0)
+	for _, option := range merchantDeliveryOptions {
+		for _, rule := range option.ShippingRules {
+			res = append(res, dto.MerchantShippingRuleResDTO{
+				ID:          rule.ID,
+				CourierCode: option.DeliveryOption.CourierCode,
+				Type:        rule.Type,
+				MinSubtotal: rule.MinSubtotal,
+				ProvinceId:  rule.ProvinceId,
+				Amount:      rule.Amount,
+			})
+		}
+	}
+
+	return res, nil
+}
+
+func (u *deliveryUsecaseImpl) CreateMerchantShippingRule(username string, input dto.MerchantShippingRuleReqDTO) (*dto.MerchantShippingRuleResDTO, error) {
+	if input.Type == dto.SHIPPING_RULE_TYPE_FLAT_RATE && input.ProvinceId == nil {
+		return nil, domain.ErrMerchantShippingRuleProvinceRequired
+	}
+	if input.Type != dto.SHIPPING_RULE_TYPE_FLAT_RATE {
+		input.ProvinceId = nil
+	}
+	if input.Type != dto.SHIPPING_RULE_TYPE_FREE_SHIPPING {
+		input.MinSubtotal = 0
+	}
+
+	merchant, err := u.merchantRepository.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	merchantDeliveryOptions, err := u.deliveryRepository.GetMerchantDeliveryOptionsByMerchantID(merchant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var merchantDeliveryOptionId uint
+	for _, option := range merchantDeliveryOptions {
+		if option.DeliveryOption.CourierCode == input.CourierCode {
+			merchantDeliveryOptionId = option.ID
+		}
+	}
+	if merchantDeliveryOptionId == 0 {
+		return nil, domain.ErrMerchantShippingRuleCourierNotEnabled
+	}
+
+	rule, err := u.deliveryRepository.CreateMerchantShippingRule(entity.MerchantShippingRule{
+		MerchantDeliveryOptionId: merchantDeliveryOptionId,
+		Type:                     input.Type,
+		MinSubtotal:              input.MinSubtotal,
+		ProvinceId:               input.ProvinceId,
+		Amount:                   input.Amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.MerchantShippingRuleResDTO{
+		ID:          rule.ID,
+		CourierCode: input.CourierCode,
+		Type:        rule.Type,
+		MinSubtotal: rule.MinSubtotal,
+		ProvinceId:  rule.ProvinceId,
+		Amount:      rule.Amount,
+	}, nil
+}
+
+func (u *deliveryUsecaseImpl) DeleteMerchantShippingRule(username string, ruleId uint) error {
+	merchant, err := u.merchantRepository.GetByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	return u.deliveryRepository.DeleteMerchantShippingRule(merchant.ID, ruleId)
+}
+
+// applyMerchantShippingRules adjusts the courier cost with the merchant rules:
+// a flat rate for the destination province replaces the cost, surcharges are
+// added on top and free shipping above the subtotal overrides everything.
+func applyMerchantShippingRules(baseCost float64, rules []entity.MerchantShippingRule, subtotal float64, destinationProvinceId uint) (float64, []dto.AppliedShippingRuleDTO) {
+	cost := baseCost
+	appliedRules := make([]dto.AppliedShippingRuleDTO, 0)
+
+	for _, rule := range rules {
+		if rule.Type == dto.SHIPPING_RULE_TYPE_FLAT_RATE && rule.ProvinceId != nil && *rule.ProvinceId == destinationProvinceId {
+			cost = rule.Amount
+			appliedRules = append(appliedRules, dto.AppliedShippingRuleDTO{Type: rule.Type, ProvinceId: rule.ProvinceId, Amount: rule.Amount})
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Type == dto.SHIPPING_RULE_TYPE_COURIER_SURCHARGE {
+			cost += rule.Amount
+			appliedRules = append(appliedRules, dto.AppliedShippingRuleDTO{Type: rule.Type, Amount: rule.Amount})
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Type == dto.SHIPPING_RULE_TYPE_FREE_SHIPPING && subtotal >= rule.MinSubtotal {
+			appliedRules = append(appliedRules, dto.AppliedShippingRuleDTO{Type: rule.Type, Amount: cost})
+			return 0, appliedRules
+		}
+	}
+
+	return cost, appliedRules
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"github.com/rs/zerolog/log"
+)
+
+type EventStreamUsecase interface {
+	Subscribe(ctx context.Context, username string, scope string) (<-chan dto.StreamEventDTO, func(), error)
+
+	PublishTransactionStatus(transactions []entity.Transaction, status int)
+	PublishPaymentFailed(transactions []entity.Transaction, paymentId string)
+	PublishDeliveryStatus(transaction entity.Transaction, status int, receiptNumber *string)
+	PublishRefundMessage(refundRequest entity.RefundRequest, message dto.RefundRequestMsgResDTO)
+	PublishChatMessage(conversation entity.ChatConversation, message dto.ChatMessageDTO)
+}
+
+type EventStreamUsecaseConfig struct {
+	EventStreamRepository repository.EventStreamRepository
+	UserRepository        repository.UserRepository
+	MerchantRepository    repository.MerchantRepository
+}
+
+type eventStreamUsecaseImpl struct {
+	eventStreamRepository repository.EventStreamRepository
+	userRepository        repository.UserRepository
+	merchantRepository    repository.MerchantRepository
+}
+
+func NewEventStreamUsecase(c EventStreamUsecaseConfig) EventStreamUsecase {
+	return &eventStreamUsecaseImpl{
+		eventStreamRepository: c.EventStreamRepository,
+		userRepository:        c.UserRepository,
+		merchantRepository:    c.MerchantRepository,
+	}
+}
+
+func (u *eventStreamUsecaseImpl) Subscribe(ctx context.Context, username string, scope string) (<-chan dto.StreamEventDTO, func(), error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	channels := []string{fmt.Sprintf(dto.EVENT_STREAM_USER_CHANNEL, user.ID)}
+	if util.ScopeShouldContain([]string{dto.ROLE_ADMIN}, scope) {
+		channels = append(channels, dto.EVENT_STREAM_ADMIN_CHANNEL)
+	}
+
+	return u.eventStreamRepository.Subscribe(ctx, channels)
+}
+
+func (u *eventStreamUsecaseImpl) PublishTransactionStatus(transactions []entity.Transaction, status int) {
+	for _, transaction := range transactions {
+		u.publish(u.transactionChannels(transaction, false), dto.EVENT_TYPE_TRANSACTION_STATUS, dto.TransactionStatusEventDTO{
+			InvoiceCode: transaction.InvoiceCode,
+			Status:      status,
+			UpdatedAt:   time.Now(),
+		})
+	}
+}
+
+// PublishPaymentFailed only tells the buyer, the merchant never saw the order.
+func (u *eventStreamUsecaseImpl) PublishPaymentFailed(transactions []entity.Transaction, paymentId string) {
+	if len(transactions) == 0 {
+		return
+	}
+
+	invoiceCodes := make([]string, 0, len(transactions))
+	for _, transaction := range transactions {
+		invoiceCodes = append(invoiceCodes, transaction.InvoiceCode)
+	}
+
+	channels := []string{fmt.Sprintf(dto.EVENT_STREAM_USER_CHANNEL, transactions[0].UserId)}
+	u.publish(channels, dto.EVENT_TYPE_PAYMENT_FAILED, dto.PaymentFailedEventDTO{
+		PaymentId:    paymentId,
+		InvoiceCodes: invoiceCodes,
+		FailedAt:     time.Now(),
+	})
+}
+
+func (u *eventStreamUsecaseImpl) PublishDeliveryStatus(transaction entity.Transaction, status int, receiptNumber *string) {
+	u.publish(u.transactionChannels(transaction, false), dto.EVENT_TYPE_DELIVERY_STATUS, dto.DeliveryStatusEventDTO{
+		InvoiceCode:   transaction.InvoiceCode,
+		Status:        status,
+		ReceiptNumber: receiptNumber,
+		UpdatedAt:     time.Now(),
+	})
+}
+
+func (u *eventStreamUsecaseImpl) PublishRefundMessage(refundRequest entity.RefundRequest, message dto.RefundRequestMsgResDTO) {
+	u.publish(u.transactionChannels(refundRequest.Transaction, true), dto.EVENT_TYPE_REFUND_MESSAGE, dto.RefundMessageEventDTO{
+		RefundRequestId: refundRequest.ID,
+		InvoiceCode:     refundRequest.Transaction.InvoiceCode,
+		Message:         message,
+	})
+}
+
+func (u *eventStreamUsecaseImpl) PublishChatMessage(conversation entity.ChatConversation, message dto.ChatMessageDTO) {
+	var channels []string
+	for _, participant := range conversation.Participants {
+		channels = append(channels, fmt.Sprintf(dto.EVENT_STREAM_USER_CHANNEL, participant.UserId))
+	}
+
+	u.publish(channels, dto.EVENT_TYPE_CHAT_MESSAGE, message)
+}
+
+// publish is best effort, a lost event only delays the client until its next refresh.
+func (u *eventStreamUsecaseImpl) publish(channels []string, eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Error().Msgf("error encoding %s event: %v", eventType, err)
+		return
+	}
+
+	err = u.eventStreamRepository.Publish(channels, dto.StreamEventDTO{
+		Event: eventType,
+		Data:  payload,
+	})
+	if err != nil {
+		log.Error().Msgf("error publishing %s event: %v", eventType, err)
+	}
+}
+
+func (u *eventStreamUsecaseImpl) transactionChannels(transaction entity.Transaction, includeAdmin bool) []string {
+	channels := []string{fmt.Sprintf(dto.EVENT_STREAM_USER_CHANNEL, transaction.UserId)}
+
+	merchantUserId := transaction.Merchant.UserId
+	if merchantUserId == 0 {
+		merchant, err := u.merchantRepository.GetByDomain(transaction.MerchantDomain)
+		if err != nil {
+			log.Error().Msgf("error getting merchant %s for stream event: %v", transaction.MerchantDomain, err)
+		} else {
+			merchantUserId = merchant.UserId
+		}
+	}
+	if merchantUserId != 0 {
+		channels = append(channels, fmt.Sprintf(dto.EVENT_STREAM_USER_CHANNEL, merchantUserId))
+	}
+
+	if includeAdmin {
+		channels = append(channels, dto.EVENT_STREAM_ADMIN_CHANNEL)
+	}
+
+	return channels
+}
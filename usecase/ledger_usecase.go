@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"math"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cronjob"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"github.com/rs/zerolog/log"
+)
+
+type LedgerUsecase interface {
+	ReconcileBalances() (*dto.LedgerReconciliationResDTO, error)
+	CronReconcileBalances()
+}
+
+type LedgerUsecaseConfig struct {
+	LedgerRepository repository.LedgerRepository
+	Cron             *cronjob.CronJob
+}
+
+type ledgerUsecaseImpl struct {
+	ledgerRepository repository.LedgerRepository
+}
+
+func NewLedgerUsecase(c LedgerUsecaseConfig) LedgerUsecase {
+	ledgerUsecase := &ledgerUsecaseImpl{
+		ledgerRepository: c.LedgerRepository,
+	}
+
+	if c.Cron != nil {
+		_, err := c.Cron.AddJob("0 1 * * *", ledgerUsecase.CronReconcileBalances)
+		if err != nil {
+			log.Error().Msg("error scheduling ledger reconciliation")
+		} else {
+			log.Info().Msg("ledger reconciliation scheduled")
+		}
+	}
+
+	return ledgerUsecase
+}
+
+func (u *ledgerUsecaseImpl) ReconcileBalances() (*dto.LedgerReconciliationResDTO, error) {
+	balances, err := u.ledgerRepository.GetAccountBalances()
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.LedgerReconciliationResDTO{
+		CheckedAccounts: len(balances),
+		Drifts:          make([]dto.LedgerAccountDriftDTO, 0),
+	}
+	for _, balance := range balances {
+		drift := balance.ActualBalance - balance.LedgerBalance
+		if math.Abs(drift) < dto.LEDGER_DRIFT_TOLERANCE {
+			continue
+		}
+
+		res.Drifts = append(res.Drifts, dto.LedgerAccountDriftDTO{
+			OwnerType:     balance.OwnerType,
+			OwnerId:       balance.OwnerId,
+			ActualBalance: balance.ActualBalance,
+			LedgerBalance: balance.LedgerBalance,
+			Drift:         drift,
+		})
+	}
+	res.DriftedAccounts = len(res.Drifts)
+	logLedgerReconciliation(res)
+
+	return &res, nil
+}
+
+func (u *ledgerUsecaseImpl) CronReconcileBalances() {
+	_, err := u.ReconcileBalances()
+	if err != nil {
+		log.Error().Msgf("CronReconcileBalances Error: %v", err)
+	}
+}
+
+// logLedgerReconciliation reports every drift the same way whether the
+// reconciliation ran from the cron, the admin endpoint or the command line.
+func logLedgerReconciliation(res dto.LedgerReconciliationResDTO) {
+	for _, drift := range res.Drifts {
+		log.Warn().Msgf("ledger drift on %s %d: actual %.2f ledger %.2f drift %.2f", drift.OwnerType, drift.OwnerId, drift.ActualBalance, drift.LedgerBalance, drift.Drift)
+	}
+	log.Info().Msgf("ledger reconciliation checked %d accounts, %d drifted", res.CheckedAccounts, res.DriftedAccounts)
+}
@@ -23,16 +23,19 @@ type MarketplaceVoucherUsecase interface {
 }
 
 type MarketplaceVoucherUsecaseConfig struct {
-	MarketplaceVoucherRepo repository.MarketplaceVoucherRepository
+	MarketplaceVoucherRepo      repository.MarketplaceVoucherRepository
+	VoucherRedemptionRepository repository.VoucherRedemptionRepository
 }
 
 type marketplaceVoucherUsecaseImpl struct {
-	mpVoucherRepo repository.MarketplaceVoucherRepository
+	mpVoucherRepo               repository.MarketplaceVoucherRepository
+	voucherRedemptionRepository repository.VoucherRedemptionRepository
 }
 
 func NewMarketplaceVoucherUsecase(c MarketplaceVoucherUsecaseConfig) MarketplaceVoucherUsecase {
 	return &marketplaceVoucherUsecaseImpl{
-		mpVoucherRepo: c.MarketplaceVoucherRepo,
+		mpVoucherRepo:               c.MarketplaceVoucherRepo,
+		voucherRedemptionRepository: c.VoucherRedemptionRepository,
 	}
 }
 
@@ -50,6 +53,7 @@ func (u *marketplaceVoucherUsecaseImpl) GetMarketplaceVoucherList() ([]dto.Marke
 	for _, mpVoucher := range mpVouchers {
 		mpVoucherResDTOs = append(mpVoucherResDTOs, dto.MarketplaceVoucherResDTO{
 			ID:                 mpVoucher.ID,
+			Type:               mpVoucher.Type,
 			DiscountPercentage: mpVoucher.DiscountPercentage,
 			ExpiredAt:          mpVoucher.ExpiredAt,
 			Code:               mpVoucher.Code,
@@ -70,6 +74,7 @@ func (u *marketplaceVoucherUsecaseImpl) GetMarketplaceVoucherByCode(voucherCode
 
 	mpVoucherResDTO := dto.MarketplaceAdminVoucherResDTO{
 		ID:                 mpVoucher.ID,
+		Type:               mpVoucher.Type,
 		DiscountPercentage: mpVoucher.DiscountPercentage,
 		Code:               mpVoucher.Code,
 		MaxDiscountNominal: mpVoucher.MaxDiscountNominal,
@@ -104,6 +109,7 @@ func (u *marketplaceVoucherUsecaseImpl) GetMarketplaceAdminVoucherList(req dto.M
 	for _, mpVoucher := range mpVouchers {
 		mpVoucherResDTOs = append(mpVoucherResDTOs, dto.MarketplaceAdminVoucherResDTO{
 			ID:                 mpVoucher.ID,
+			Type:               mpVoucher.Type,
 			Code:               mpVoucher.Code,
 			DiscountPercentage: mpVoucher.DiscountPercentage,
 			MaxDiscountNominal: mpVoucher.MaxDiscountNominal,
@@ -126,6 +132,10 @@ func (u *marketplaceVoucherUsecaseImpl) GetMarketplaceAdminVoucherList(req dto.M
 }
 
 func (u *marketplaceVoucherUsecaseImpl) CreateMarketplaceVoucher(req dto.UpsertMarketplaceVoucherReqDTO) (*dto.MarketplaceAdminVoucherResDTO, error) {
+	if req.Type == "" {
+		req.Type = dto.MARKETPLACE_VOUCHER_TYPE_DISCOUNT
+	}
+
 	err := util.ValidateVoucherCode(req.Code, MARKETPLACE_PREFIX)
 	if err != nil {
 		return nil, err
@@ -141,6 +151,7 @@ func (u *marketplaceVoucherUsecaseImpl) CreateMarketplaceVoucher(req dto.UpsertM
 
 	voucher := entity.MarketplaceVoucher{
 		Code:               req.Code,
+		Type:               req.Type,
 		DiscountPercentage: req.DiscountPercentage,
 		StartDate:          req.StartDate,
 		ExpiredAt:          req.EndDate,
@@ -157,6 +168,7 @@ func (u *marketplaceVoucherUsecaseImpl) CreateMarketplaceVoucher(req dto.UpsertM
 
 	mpVoucherResDTO := dto.MarketplaceAdminVoucherResDTO{
 		ID:                 mpVoucher.ID,
+		Type:               mpVoucher.Type,
 		Code:               mpVoucher.Code,
 		DiscountPercentage: mpVoucher.DiscountPercentage,
 		MaxDiscountNominal: mpVoucher.MaxDiscountNominal,
@@ -189,6 +201,10 @@ func (u *marketplaceVoucherUsecaseImpl) UpdateMarketplaceVoucher(voucherCode str
 		return nil, domain.ErrUpdateOngoingVoucher
 	}
 
+	// an edit without a type keeps the current one, e.g. a shipping voucher
+	if req.Type != "" {
+		voucher.Type = req.Type
+	}
 	voucher.DiscountPercentage = req.DiscountPercentage
 	voucher.StartDate = req.StartDate
 	voucher.Code = req.Code
@@ -205,9 +221,11 @@ func (u *marketplaceVoucherUsecaseImpl) UpdateMarketplaceVoucher(voucherCode str
 	if err != nil {
 		return nil, err
 	}
+	u.voucherRedemptionRepository.InvalidateMarketplaceVoucherQuota(mpVoucher.ID)
 
 	mpVoucherResDTO := dto.MarketplaceAdminVoucherResDTO{
 		ID:                 mpVoucher.ID,
+		Type:               mpVoucher.Type,
 		Code:               mpVoucher.Code,
 		DiscountPercentage: mpVoucher.DiscountPercentage,
 		MaxDiscountNominal: mpVoucher.MaxDiscountNominal,
@@ -238,6 +256,7 @@ func (u *marketplaceVoucherUsecaseImpl) DeleteMarketplaceVoucher(voucherCode str
 
 	mpVoucherResDTO := dto.MarketplaceAdminVoucherResDTO{
 		ID:                 mpVoucher.ID,
+		Type:               mpVoucher.Type,
 		Code:               mpVoucher.Code,
 		DiscountPercentage: mpVoucher.DiscountPercentage,
 		MaxDiscountNominal: mpVoucher.MaxDiscountNominal,
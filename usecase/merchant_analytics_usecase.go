@@ -12,6 +12,7 @@ import (
 
 type MerchantAnalyticsUsecase interface {
 	GetMerchantDashboardMerchantResponsivenessStatistics(username string, input dto.MerchantAnalyticsMerchantResponsivenessReqBody) ([]dto.MerchantAnalyticsMerchantResponsivenessResBody, error)
+	GetMerchantDashboardResponsivenessPercentiles(username string, input dto.MerchantAnalyticsResponsivenessPercentileReqBody) ([]dto.MerchantAnalyticsResponsivenessPercentileResBody, error)
 	GetMerchantDashboardSalesStatistics(username string, input dto.MerchantAnalyticsSalesReqBody) ([]dto.MerchantAnalyticsSalesResBody, error)
 	GetMerchantDashboardCustomerSatisfactionStatistics(username string, input dto.MerchantAnalyticsCustomerSatisfactionReqBody) ([]dto.MerchantAnalyticsCustomerSatisfactionResBody, error)
 	UpdateMerchantDashboard(input *dto.MerchantAnalyticsUpdateReqBody) error
@@ -36,6 +37,13 @@ func NewMerchantAnalyticsUsecase(c MerchantAnalyticsUsecaseConfig) MerchantAnaly
 		} else {
 			log.Info().Msg("merchant analytics daily update executed")
 		}
+
+		errExe = c.MerchantAnalyticsRepository.UpdateMerchantDailyResponsiveness(time.Now().Format(dateFormat))
+		if errExe != nil {
+			log.Error().Msg("error executing merchant responsiveness daily update")
+		} else {
+			log.Info().Msg("merchant responsiveness daily update executed")
+		}
 	})
 	if err != nil {
 		log.Error().Msg("error scheduling merchant analytics daily update")
@@ -77,11 +85,47 @@ func (u *merchantAnalyticsUsecaseImpl) GetMerchantDashboardMerchantResponsivenes
 			Date:  m.DatePartition.Format(dateFormat),
 			Value: fmt.Sprintf("%.2f", m.OSD),
 		})
+		activeUserStatistics = append(activeUserStatistics, dto.MerchantAnalyticsMerchantResponsivenessResBody{
+			Type:  "CRT",
+			Date:  m.DatePartition.Format(dateFormat),
+			Value: fmt.Sprintf("%.2f", m.CRT),
+		})
 	}
 
 	return activeUserStatistics, nil
 }
 
+func (u *merchantAnalyticsUsecaseImpl) GetMerchantDashboardResponsivenessPercentiles(username string, input dto.MerchantAnalyticsResponsivenessPercentileReqBody) ([]dto.MerchantAnalyticsResponsivenessPercentileResBody, error) {
+	merchant, err := u.merchantRepository.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, endDate, err := parseInputDate(dto.DashboardReqBody(input))
+	if err != nil {
+		return nil, err
+	}
+
+	mResponsiveness, err := u.merchantAnalyticsRepository.GetMerchantDailyResponsiveness(merchant.Domain, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	responsivenessPercentiles := make([]dto.MerchantAnalyticsResponsivenessPercentileResBody, 0, len(mResponsiveness))
+	for _, m := range mResponsiveness {
+		responsivenessPercentiles = append(responsivenessPercentiles, dto.MerchantAnalyticsResponsivenessPercentileResBody{
+			Metric:      m.Metric,
+			Date:        m.DatePartition.Format(dateFormat),
+			SampleCount: m.SampleCount,
+			P50:         m.P50,
+			P90:         m.P90,
+			P95:         m.P95,
+		})
+	}
+
+	return responsivenessPercentiles, nil
+}
+
 func (u *merchantAnalyticsUsecaseImpl) GetMerchantDashboardSalesStatistics(username string, input dto.MerchantAnalyticsSalesReqBody) ([]dto.MerchantAnalyticsSalesResBody, error) {
 	merchant, err := u.merchantRepository.GetByUsername(username)
 	if err != nil {
@@ -140,5 +184,9 @@ func (u *merchantAnalyticsUsecaseImpl) GetMerchantDashboardCustomerSatisfactionS
 
 func (u *merchantAnalyticsUsecaseImpl) UpdateMerchantDashboard(input *dto.MerchantAnalyticsUpdateReqBody) error {
 	err := u.merchantAnalyticsRepository.UpdateMerchantDailyAnalytics(input.DatePartition)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return u.merchantAnalyticsRepository.UpdateMerchantDailyResponsiveness(input.DatePartition)
 }
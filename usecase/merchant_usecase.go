@@ -41,7 +41,9 @@ type MerchantUsecaseConfig struct {
 	UserRepository                          repository.UserRepository
 	MerchantHoldingAccountHistoryRepository repository.MerchantHoldingAccountHistoryRepository
 	MerchantHoldingAccountRepository        repository.MerchantHoldingAccountRepository
+	VoucherRedemptionRepository             repository.VoucherRedemptionRepository
 	GcsUploader                             util.GCSUploader
+	NotificationUsecase                     NotificationUsecase
 }
 
 type merchantUsecaseImpl struct {
@@ -50,7 +52,9 @@ type merchantUsecaseImpl struct {
 	userRepository                          repository.UserRepository
 	merchantHoldingAccountHistoryRepository repository.MerchantHoldingAccountHistoryRepository
 	merchantHoldingAccountRepository        repository.MerchantHoldingAccountRepository
+	voucherRedemptionRepository             repository.VoucherRedemptionRepository
 	gcsUploader                             util.GCSUploader
+	notificationUsecase                     NotificationUsecase
 }
 
 func NewMerchantUsecase(c MerchantUsecaseConfig) MerchantUsecase {
@@ -60,7 +64,9 @@ func NewMerchantUsecase(c MerchantUsecaseConfig) MerchantUsecase {
 		userRepository:                          c.UserRepository,
 		merchantHoldingAccountHistoryRepository: c.MerchantHoldingAccountHistoryRepository,
 		merchantHoldingAccountRepository:        c.MerchantHoldingAccountRepository,
+		voucherRedemptionRepository:             c.VoucherRedemptionRepository,
 		gcsUploader:                             c.GcsUploader,
+		notificationUsecase:                     c.NotificationUsecase,
 	}
 }
 
@@ -364,6 +370,7 @@ func (u *merchantUsecaseImpl) UpdateMerchantVoucher(username string, voucherCode
 	if err != nil {
 		return nil, err
 	}
+	u.voucherRedemptionRepository.InvalidateMerchantVoucherQuota(voucherRes.ID)
 
 	voucherDTO := dto.UpsertMerchantVoucherResDTO{
 		ID:              voucherRes.ID,
@@ -617,6 +624,8 @@ func (u *merchantUsecaseImpl) WithdrawToWallet(username string, req dto.Merchant
 		return nil, err
 	}
 
+	u.notificationUsecase.NotifyAmount(user.ID, dto.NOTIFICATION_TYPE_MERCHANT_WITHDRAW, float64(req.Amount))
+
 	resDTO := dto.MerchantWithdrawResDTO{
 		ID:     res.ID,
 		Amount: res.Amount,
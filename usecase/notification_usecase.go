@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"fmt"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"github.com/rs/zerolog/log"
+)
+
+type NotificationUsecase interface {
+	GetNotificationList(username string, req dto.NotificationListReqParamDTO) (*dto.NotificationListResDTO, error)
+	GetUnreadNotificationCount(username string) (*dto.NotificationUnreadCountResDTO, error)
+	MarkNotificationRead(username string, notificationId uint) (*dto.NotificationDTO, error)
+	MarkAllNotificationsRead(username string) (*dto.NotificationUnreadCountResDTO, error)
+
+	NotifyTransactions(transactions []entity.Transaction, notificationType string)
+	NotifyAmount(userId uint, notificationType string, amount float64)
+}
+
+type NotificationUsecaseConfig struct {
+	NotificationRepository repository.NotificationRepository
+	UserRepository         repository.UserRepository
+}
+
+type notificationUsecaseImpl struct {
+	notificationRepository repository.NotificationRepository
+	userRepository         repository.UserRepository
+}
+
+func NewNotificationUsecase(c NotificationUsecaseConfig) NotificationUsecase {
+	return &notificationUsecaseImpl{
+		notificationRepository: c.NotificationRepository,
+		userRepository:         c.UserRepository,
+	}
+}
+
+func (u *notificationUsecaseImpl) GetNotificationList(username string, req dto.NotificationListReqParamDTO) (*dto.NotificationListResDTO, error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, totalData, err := u.notificationRepository.GetList(user.ID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	unreadCount, err := u.notificationRepository.CountUnread(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	notificationDTOs := make([]dto.NotificationDTO, 0, len(notifications))
+	for _, notification := range notifications {
+		notificationDTOs = append(notificationDTOs, u.buildNotificationDTO(notification))
+	}
+
+	return &dto.NotificationListResDTO{
+		PaginationResponse: dto.PaginationResponse{
+			TotalData:   totalData,
+			TotalPage:   (totalData + int64(req.Limit) - 1) / int64(req.Limit),
+			CurrentPage: req.Page,
+		},
+		UnreadCount:   unreadCount,
+		Notifications: notificationDTOs,
+	}, nil
+}
+
+func (u *notificationUsecaseImpl) GetUnreadNotificationCount(username string) (*dto.NotificationUnreadCountResDTO, error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	unreadCount, err := u.notificationRepository.CountUnread(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.NotificationUnreadCountResDTO{
+		UnreadCount: unreadCount,
+	}, nil
+}
+
+func (u *notificationUsecaseImpl) MarkNotificationRead(username string, notificationId uint) (*dto.NotificationDTO, error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	notification, err := u.notificationRepository.MarkRead(user.ID, notificationId)
+	if err != nil {
+		return nil, err
+	}
+
+	notificationDTO := u.buildNotificationDTO(*notification)
+	return &notificationDTO, nil
+}
+
+func (u *notificationUsecaseImpl) MarkAllNotificationsRead(username string) (*dto.NotificationUnreadCountResDTO, error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.notificationRepository.MarkAllRead(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.NotificationUnreadCountResDTO{
+		UnreadCount: 0,
+	}, nil
+}
+
+// NotifyTransactions is best effort, a failed notification never fails the flow that emits it.
+func (u *notificationUsecaseImpl) NotifyTransactions(transactions []entity.Transaction, notificationType string) {
+	err := u.notificationRepository.CreateTransactionNotifications(transactions, notificationType)
+	if err != nil {
+		log.Error().Msgf("error creating %s notification: %v", notificationType, err)
+	}
+}
+
+func (u *notificationUsecaseImpl) NotifyAmount(userId uint, notificationType string, amount float64) {
+	template, ok := dto.NotificationTemplates[notificationType]
+	if !ok {
+		log.Error().Msgf("unknown notification type %s", notificationType)
+		return
+	}
+
+	link := "/wallet"
+	if template.IsForMerchant {
+		link = "/merchant/funds"
+	}
+
+	err := u.notificationRepository.Create([]entity.Notification{{
+		UserId:  userId,
+		Type:    notificationType,
+		Title:   template.Title,
+		Message: fmt.Sprintf(template.Message, amount),
+		Link:    link,
+	}})
+	if err != nil {
+		log.Error().Msgf("error creating %s notification: %v", notificationType, err)
+	}
+}
+
+func (u *notificationUsecaseImpl) buildNotificationDTO(notification entity.Notification) dto.NotificationDTO {
+	return dto.NotificationDTO{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Title:     notification.Title,
+		Message:   notification.Message,
+		Link:      notification.Link,
+		IsRead:    notification.ReadAt != nil,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
@@ -1,19 +1,25 @@
 package usecase
 
 import (
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cronjob"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 )
 
 type OrderItemUsecase interface {
 	GetOrderCheckoutSummary(username string, input dto.PostOrderSummaryReqDTO) (*dto.PostOrderSummaryResDTO, error)
 	MakeOrderCheckout(username string, input []dto.MakeOrderCheckoutProductDTO) (*dto.PostOrderSummaryResDTO, error)
+	CronReleaseExpiredStockReservations()
 }
 
 type OrderItemUsecaseConfig struct {
@@ -25,8 +31,13 @@ type OrderItemUsecaseConfig struct {
 	DeliveryRepository           repository.DeliveryRepository
 	AddressRepository            repository.AddressRepository
 	MarketplaceVoucherRepository repository.MarketplaceVoucherRepository
+	VoucherCodeRepository        repository.VoucherCodeRepository
+	UserVoucherRepository        repository.UserVoucherRepository
 	MerchantRepository           repository.MerchantRepository
 	UserOrderRepository          repository.UserOrderRepository
+	StockReservationRepository   repository.StockReservationRepository
+	VoucherRuleUsecase           VoucherRuleUsecase
+	Cron                         *cronjob.CronJob
 }
 
 type orderItemUsecaseImpl struct {
@@ -38,12 +49,16 @@ type orderItemUsecaseImpl struct {
 	deliveryRepository           repository.DeliveryRepository
 	addressRepository            repository.AddressRepository
 	marketplaceVoucherRepository repository.MarketplaceVoucherRepository
+	voucherCodeRepository        repository.VoucherCodeRepository
+	userVoucherRepository        repository.UserVoucherRepository
 	merchantRepository           repository.MerchantRepository
 	userOrderRepository          repository.UserOrderRepository
+	stockReservationRepository   repository.StockReservationRepository
+	voucherRuleUsecase           VoucherRuleUsecase
 }
 
 func NewOrderItemUsecase(c OrderItemUsecaseConfig) OrderItemUsecase {
-	return &orderItemUsecaseImpl{
+	orderItemUsecase := &orderItemUsecaseImpl{
 		orderItemRepository:          c.OrderItemRepository,
 		cartItemRepository:           c.CartItemRepository,
 		userRepository:               c.UserRepository,
@@ -52,9 +67,24 @@ func NewOrderItemUsecase(c OrderItemUsecaseConfig) OrderItemUsecase {
 		productVariantRepository:     c.ProductVariantRepository,
 		addressRepository:            c.AddressRepository,
 		marketplaceVoucherRepository: c.MarketplaceVoucherRepository,
+		voucherCodeRepository:        c.VoucherCodeRepository,
+		userVoucherRepository:        c.UserVoucherRepository,
 		merchantRepository:           c.MerchantRepository,
 		userOrderRepository:          c.UserOrderRepository,
+		stockReservationRepository:   c.StockReservationRepository,
+		voucherRuleUsecase:           c.VoucherRuleUsecase,
 	}
+
+	if c.Cron != nil {
+		_, err := c.Cron.AddJob("* * * * *", orderItemUsecase.CronReleaseExpiredStockReservations)
+		if err != nil {
+			log.Error().Msg("error scheduling stock reservation release")
+		} else {
+			log.Info().Msg("stock reservation release scheduled")
+		}
+	}
+
+	return orderItemUsecase
 }
 
 func (u *orderItemUsecaseImpl) MakeOrderCheckout(username string, orderItemsInput []dto.MakeOrderCheckoutProductDTO) (*dto.PostOrderSummaryResDTO, error) {
@@ -88,7 +118,7 @@ func (u *orderItemUsecaseImpl) MakeOrderCheckout(username string, orderItemsInpu
 		OrderItems: orderItems,
 	}
 
-	createdOrder, err := u.userOrderRepository.CreateOrder(order)
+	createdOrder, err := u.userOrderRepository.CreateOrder(order, time.Now().Add(dto.STOCK_RESERVATION_TTL))
 	if err != nil {
 		return nil, err
 	}
@@ -119,6 +149,27 @@ func (u *orderItemUsecaseImpl) GetOrderCheckoutSummary(username string, input dt
 		return nil, domain.ErrNoOrderItem
 	}
 
+	var variantItemIds []uint
+	for _, orderItem := range orderItems {
+		variantItemIds = append(variantItemIds, orderItem.VariantItemId)
+	}
+	otherReservedStock, err := u.stockReservationRepository.GetReservedStock(variantItemIds, userOrder.OrderCode)
+	if err != nil {
+		return nil, err
+	}
+	reservations, err := u.stockReservationRepository.GetActiveReservationsByOrderCode(userOrder.OrderCode)
+	if err != nil {
+		return nil, err
+	}
+	var orderReservedStock = make(map[uint]uint)
+	var reservationExpireAt *time.Time
+	for i, reservation := range reservations {
+		orderReservedStock[reservation.VariantItemId] += reservation.Quantity
+		if reservationExpireAt == nil || reservation.ExpiresAt.Before(*reservationExpireAt) {
+			reservationExpireAt = &reservations[i].ExpiresAt
+		}
+	}
+
 	var isOrderValid = true
 	var orderMerchantMap = make(map[uint][]dto.OrderItemDTO)
 	var cartMerchantKeys []uint
@@ -126,7 +177,7 @@ func (u *orderItemUsecaseImpl) GetOrderCheckoutSummary(username string, input dt
 	var merchantWeightMap = make(map[uint]int)
 	var trxTotal float64
 	for _, orderItem := range orderItems {
-		newOrderPH := u.fillOrderItemDTO(orderItem)
+		newOrderPH := u.fillOrderItemDTO(orderItem, otherReservedStock[orderItem.VariantItemId], orderReservedStock[orderItem.VariantItemId])
 		isOrderValid = isOrderValid && newOrderPH.IsValid
 
 		if orderItem.Product.Merchant.UserId == user.ID {
@@ -154,34 +205,84 @@ func (u *orderItemUsecaseImpl) GetOrderCheckoutSummary(username string, input dt
 		}
 	}
 
-	var isVoucherInvalid = false
 	var trxDelivery float64
 	var trxSellerDiscount float64
 	var order []dto.OrderItemPerMerchantDTO
+	userCity, err := u.addressRepository.GetCityById(address.CityId)
+	if err != nil {
+		return nil, err
+	}
+	var merchantCityMap = make(map[uint]*entity.City)
 	for _, key := range cartMerchantKeys {
-		userCity, err := u.addressRepository.GetCityById(address.CityId)
+		merchantCity, err := u.addressRepository.GetCityById(orderMerchantMap[key][0].MerchantCityId)
 		if err != nil {
 			return nil, err
 		}
-		merchantCity, err := u.addressRepository.GetCityById(orderMerchantMap[key][0].MerchantCityId)
+		merchantCityMap[key] = merchantCity
+	}
+	merchantDeliveryMap, err := u.getMerchantsDeliveryInfo(cartMerchantKeys, mapMerchantDeliveryOption, merchantCityMap, *userCity, merchantWeightMap)
+	if err != nil {
+		return nil, err
+	}
+	claimedVouchers, err := u.userVoucherRepository.GetUserVouchers(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var mpVoucherCandidates []entity.MarketplaceVoucher
+	var merchantVoucherCandidates map[string][]entity.MerchantVoucher
+	if input.AutoApplyVoucher {
+		var merchantDomains []string
+		for _, key := range cartMerchantKeys {
+			merchantDomains = append(merchantDomains, orderMerchantMap[key][0].MerchantDomain)
+		}
+		mpVoucherCandidates, merchantVoucherCandidates, err = u.getAutoApplyVoucherCandidates(claimedVouchers, merchantDomains)
 		if err != nil {
 			return nil, err
 		}
+	}
+
+	for _, key := range cartMerchantKeys {
+		merchantCity := merchantCityMap[key]
 
 		var sellerDiscount float64 = 0
 		var merchantVoucherId *uint = nil
-		if len(mapMerchantVoucher) > 0 {
+		var merchantCodeId *uint = nil
+		var isVoucherInvalid = false
+		var voucherReason string
+		var voucherCandidates []dto.VoucherCandidateDTO
+		merchantRuleCheck := dto.VoucherRuleCheckDTO{
+			UserId:            user.ID,
+			UserCreatedAt:     user.CreatedAt,
+			PaymentMethodCode: input.PaymentMethodCode,
+			IsAutoApply:       input.AutoApplyVoucher,
+			Items:             orderMerchantMap[key],
+		}
+		if input.AutoApplyVoucher {
+			var sellerVoucher *entity.MerchantVoucher
+			sellerVoucher, voucherCandidates, err = u.pickMerchantVoucher(merchantVoucherCandidates[orderMerchantMap[key][0].MerchantDomain], merchantTotalMap[key], merchantRuleCheck)
+			if err != nil {
+				return nil, err
+			}
+			if sellerVoucher != nil {
+				merchantVoucherId = &sellerVoucher.ID
+				sellerDiscount = getMerchantVoucherDiscount(*sellerVoucher, merchantTotalMap[key])
+			}
+		} else if len(mapMerchantVoucher) > 0 {
 			if val, ok := mapMerchantVoucher[key]; ok && val != "" {
-				sellerVoucher, _ := u.merchantRepository.GetMerchantVoucherByCode(orderMerchantMap[key][0].MerchantDomain, val)
+				var sellerVoucher *entity.MerchantVoucher
+				sellerVoucher, merchantCodeId, voucherReason = u.getMerchantVoucherByCode(orderMerchantMap[key][0].MerchantDomain, val)
 				if sellerVoucher != nil {
-					merchantVoucherId = &sellerVoucher.ID
-					if sellerVoucher.MinOrderNominal <= merchantTotalMap[key] {
-						sellerDiscount = sellerVoucher.DiscountNominal
-						if sellerVoucher.DiscountNominal >= merchantTotalMap[key] {
-							sellerDiscount = merchantTotalMap[key]
-						}
+					voucherReason, err = u.voucherRuleUsecase.CheckMerchantVoucher(*sellerVoucher, merchantTotalMap[key], merchantRuleCheck)
+					if err != nil {
+						return nil, err
+					}
+
+					if voucherReason == "" {
+						merchantVoucherId = &sellerVoucher.ID
+						sellerDiscount = getMerchantVoucherDiscount(*sellerVoucher, merchantTotalMap[key])
 					} else {
 						isVoucherInvalid = true
+						merchantCodeId = nil
 					}
 				}
 				if sellerVoucher == nil {
@@ -201,33 +302,30 @@ func (u *orderItemUsecaseImpl) GetOrderCheckoutSummary(username string, input dt
 			SubTotal:          merchantTotalMap[key],
 			Discount:          sellerDiscount,
 			IsVoucherInvalid:  isVoucherInvalid,
+			VoucherReason:     voucherReason,
+			VoucherCandidates: voucherCandidates,
 			MerchantVoucherId: merchantVoucherId,
+			MerchantCodeId:    merchantCodeId,
 		}
 		trxSellerDiscount += sellerDiscount
 
-		if mapMerchantDeliveryOption[key] != "" {
-			deliveryInput := dto.RajaOngkirDeliveryInfoReqDTO{
-				Origin:      int(merchantCity.RoId),
-				Destination: int(userCity.RoId),
-				Weight:      merchantWeightMap[key],
-				Courier:     mapMerchantDeliveryOption[key],
-			}
-
-			deliveryOption, err := u.deliveryRepository.GetDeliveryOptionByMerchantID(key, mapMerchantDeliveryOption[key])
+		// auto-apply already weighed every claimed voucher
+		if !input.AutoApplyVoucher {
+			orderItem.SuggestedVoucher, err = u.suggestMerchantVoucher(claimedVouchers, orderItem.Merchant.MerchantDomain, merchantTotalMap[key], sellerDiscount, merchantRuleCheck)
 			if err != nil {
 				return nil, err
 			}
+		}
 
-			deliveryInfo, err := u.deliveryRepository.GetDeliveryInfo(deliveryInput)
-			if err != nil {
-				return nil, err
-			}
+		if mapMerchantDeliveryOption[key] != "" {
+			deliveryOption := merchantDeliveryMap[key].deliveryOption
+			deliveryInfo := merchantDeliveryMap[key].deliveryInfo
 
 			var isSelectedDelivery = false
 			for _, v := range deliveryInfo.Rajaongkir.Results[0].Costs {
 				if util.IsSliceContainString(strings.Split(deliveryOption.ServiceCode, ","), v.Service) {
 					isSelectedDelivery = true
-					deliveryCost := v.Cost[0].Value
+					deliveryCost, appliedRules := applyMerchantShippingRules(v.Cost[0].Value, merchantDeliveryMap[key].shippingRules, merchantTotalMap[key], userCity.ProvinceID)
 
 					orderItem.DeliveryService = dto.DeliveryServiceDTO{
 						DeliveryOption: mapMerchantDeliveryOption[key],
@@ -238,6 +336,8 @@ func (u *orderItemUsecaseImpl) GetOrderCheckoutSummary(username string, input dt
 						UserCity:       userCity.Name,
 						Etd:            v.Cost[0].Etd,
 						Note:           v.Cost[0].Note,
+						BaseCost:       v.Cost[0].Value,
+						AppliedRules:   appliedRules,
 					}
 					orderItem.DeliveryCost = deliveryCost
 					orderItem.Total = merchantTotalMap[key] + deliveryCost - sellerDiscount
@@ -253,22 +353,60 @@ func (u *orderItemUsecaseImpl) GetOrderCheckoutSummary(username string, input dt
 		order = append(order, orderItem)
 	}
 
+	var allItems []dto.OrderItemDTO
+	for _, key := range cartMerchantKeys {
+		allItems = append(allItems, orderMerchantMap[key]...)
+	}
+	mpRuleCheck := dto.VoucherRuleCheckDTO{
+		UserId:            user.ID,
+		UserCreatedAt:     user.CreatedAt,
+		PaymentMethodCode: input.PaymentMethodCode,
+		IsAutoApply:       input.AutoApplyVoucher,
+		Items:             allItems,
+	}
+
 	var isMpVoucherInvalid bool
+	var mpVoucherReason string
 	var marketplaceDiscount float64 = 0
+	var marketplaceShippingDiscount float64 = 0
 	var marketplaceVoucherId *uint = nil
-	if input.VoucherMarketplace != "" {
-		mpVoucher, err := u.marketplaceVoucherRepository.GetMarketplaceVoucherByCode(input.VoucherMarketplace)
-		isMpVoucherInvalid = true
-		if mpVoucher != nil && err == nil {
-			if mpVoucher.MinOrderNominal <= trxTotal {
-				marketplaceVoucherId = &mpVoucher.ID
-				marketplaceDiscount = float64(mpVoucher.DiscountPercentage) / 100 * (trxTotal - trxSellerDiscount)
-				if marketplaceDiscount > float64(mpVoucher.MaxDiscountNominal) {
-					marketplaceDiscount = float64(mpVoucher.MaxDiscountNominal)
-				}
-				isMpVoucherInvalid = false
+	var marketplaceCodeId *uint = nil
+	var mpVoucher *entity.MarketplaceVoucher
+	var mpCodeId *uint
+	var voucherCandidates []dto.VoucherCandidateDTO
+	if input.AutoApplyVoucher {
+		// merchant vouchers are picked first, a marketplace voucher never takes off
+		// more than what they leave so applying it last keeps the best combination
+		mpVoucher, voucherCandidates, err = u.pickMarketplaceVoucher(mpVoucherCandidates, trxTotal, trxTotal-trxSellerDiscount, trxDelivery, mpRuleCheck)
+		if err != nil {
+			return nil, err
+		}
+	} else if input.VoucherMarketplace != "" {
+		mpVoucher, mpCodeId, mpVoucherReason = u.getMarketplaceVoucherByCode(input.VoucherMarketplace)
+		if mpVoucher != nil {
+			mpVoucherReason, err = u.voucherRuleUsecase.CheckMarketplaceVoucher(*mpVoucher, trxTotal, mpRuleCheck)
+			if err != nil {
+				return nil, err
 			}
 		}
+		isMpVoucherInvalid = mpVoucherReason != ""
+	}
+	if mpVoucher != nil && !isMpVoucherInvalid {
+		marketplaceVoucherId = &mpVoucher.ID
+		marketplaceCodeId = mpCodeId
+		if mpVoucher.Type == dto.MARKETPLACE_VOUCHER_TYPE_SHIPPING {
+			marketplaceShippingDiscount = u.applyMarketplaceShippingVoucher(*mpVoucher, trxDelivery, order)
+		} else {
+			marketplaceDiscount = getMarketplaceVoucherDiscount(*mpVoucher, trxTotal-trxSellerDiscount, trxDelivery)
+		}
+	}
+
+	var suggestedVoucher *dto.SuggestedVoucherDTO
+	if !input.AutoApplyVoucher {
+		suggestedVoucher, err = u.suggestMarketplaceVoucher(claimedVouchers, trxTotal, trxTotal-trxSellerDiscount, trxDelivery, marketplaceDiscount+marketplaceShippingDiscount, mpRuleCheck)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	resBody := dto.PostOrderSummaryResDTO{
@@ -278,17 +416,356 @@ func (u *orderItemUsecaseImpl) GetOrderCheckoutSummary(username string, input dt
 		DeliveryCost:        trxDelivery,
 		DiscountMerchant:    trxSellerDiscount,
 		DiscountMarketplace: marketplaceDiscount,
-		Total:               trxTotal + trxDelivery - trxSellerDiscount - marketplaceDiscount,
+		DiscountShipping:    marketplaceShippingDiscount,
+		Total:               trxTotal + trxDelivery - trxSellerDiscount - marketplaceDiscount - marketplaceShippingDiscount,
 		IsVouchervalid:      !isMpVoucherInvalid,
+		VoucherReason:       mpVoucherReason,
+		SuggestedVoucher:    suggestedVoucher,
+		VoucherCandidates:   voucherCandidates,
 		IsOrderEligible:     !userOrder.DeletedAt.Valid,
 		IsOrderValid:        isOrderValid,
+		ReservationExpireAt: reservationExpireAt,
 
 		MarketplaceVoucherId: marketplaceVoucherId,
+		MarketplaceCodeId:    marketplaceCodeId,
 		Address:              *address,
 	}
 	return &resBody, nil
 }
 
+// getMarketplaceVoucherByCode resolves both a voucher's own code and its single-use
+// codes, returning the single-use code id when one was given.
+func (u *orderItemUsecaseImpl) getMarketplaceVoucherByCode(code string) (*entity.MarketplaceVoucher, *uint, string) {
+	mpVoucher, err := u.marketplaceVoucherRepository.GetMarketplaceVoucherByCode(code)
+	if err == nil {
+		return mpVoucher, nil, ""
+	}
+
+	voucherCode, err := u.voucherCodeRepository.GetVoucherCodeByCode(code)
+	if err != nil || voucherCode.MarketplaceVoucherId == nil {
+		return nil, nil, dto.VOUCHER_REJECT_REASON_NOT_FOUND
+	}
+	if voucherCode.RedeemedAt != nil {
+		return nil, nil, dto.VOUCHER_REJECT_REASON_CODE_REDEEMED
+	}
+
+	mpVoucher, err = u.marketplaceVoucherRepository.GetMarketplaceVoucherByID(*voucherCode.MarketplaceVoucherId)
+	if err != nil {
+		return nil, nil, dto.VOUCHER_REJECT_REASON_NOT_FOUND
+	}
+
+	return mpVoucher, &voucherCode.ID, ""
+}
+
+func (u *orderItemUsecaseImpl) getMerchantVoucherByCode(merchantDomain string, code string) (*entity.MerchantVoucher, *uint, string) {
+	sellerVoucher, err := u.merchantRepository.GetMerchantVoucherByCode(merchantDomain, code)
+	if err == nil {
+		return sellerVoucher, nil, ""
+	}
+
+	voucherCode, err := u.voucherCodeRepository.GetVoucherCodeByCode(code)
+	if err != nil || voucherCode.MerchantVoucherId == nil {
+		return nil, nil, dto.VOUCHER_REJECT_REASON_NOT_FOUND
+	}
+	if voucherCode.RedeemedAt != nil {
+		return nil, nil, dto.VOUCHER_REJECT_REASON_CODE_REDEEMED
+	}
+
+	sellerVoucher, err = u.merchantRepository.GetMerchantVoucher(merchantDomain, *voucherCode.MerchantVoucherId)
+	if err != nil {
+		return nil, nil, dto.VOUCHER_REJECT_REASON_NOT_FOUND
+	}
+
+	return sellerVoucher, &voucherCode.ID, ""
+}
+
+// applyMarketplaceShippingVoucher subsidises the delivery cost up to the voucher cap,
+// allocating the subsidy to each merchant order until it is used up.
+func (u *orderItemUsecaseImpl) applyMarketplaceShippingVoucher(mpVoucher entity.MarketplaceVoucher, trxDelivery float64, order []dto.OrderItemPerMerchantDTO) float64 {
+	shippingDiscount := getMarketplaceVoucherDiscount(mpVoucher, 0, trxDelivery)
+
+	remaining := shippingDiscount
+	for i := range order {
+		merchantShippingDiscount := order[i].DeliveryCost
+		if merchantShippingDiscount > remaining {
+			merchantShippingDiscount = remaining
+		}
+		order[i].ShippingDiscount = merchantShippingDiscount
+		order[i].Total -= merchantShippingDiscount
+		remaining -= merchantShippingDiscount
+	}
+
+	return shippingDiscount
+}
+
+// getMarketplaceVoucherDiscount returns what the voucher takes off, shipping vouchers
+// discount the delivery cost instead of the order.
+func getMarketplaceVoucherDiscount(mpVoucher entity.MarketplaceVoucher, subTotal float64, deliveryCost float64) float64 {
+	base := subTotal
+	if mpVoucher.Type == dto.MARKETPLACE_VOUCHER_TYPE_SHIPPING {
+		base = deliveryCost
+	}
+
+	discount := float64(mpVoucher.DiscountPercentage) / 100 * base
+	if discount > mpVoucher.MaxDiscountNominal {
+		discount = mpVoucher.MaxDiscountNominal
+	}
+	return discount
+}
+
+func getMerchantVoucherDiscount(sellerVoucher entity.MerchantVoucher, subTotal float64) float64 {
+	if sellerVoucher.DiscountNominal >= subTotal {
+		return subTotal
+	}
+	return sellerVoucher.DiscountNominal
+}
+
+// suggestMarketplaceVoucher picks the claimed marketplace voucher saving the most,
+// as long as it beats the discount already applied.
+func (u *orderItemUsecaseImpl) suggestMarketplaceVoucher(claimedVouchers []entity.UserVoucher, trxTotal float64, discountBase float64, trxDelivery float64, appliedDiscount float64, input dto.VoucherRuleCheckDTO) (*dto.SuggestedVoucherDTO, error) {
+	var suggestion *dto.SuggestedVoucherDTO
+	for _, claimed := range claimedVouchers {
+		if claimed.MarketplaceVoucher == nil {
+			continue
+		}
+
+		discount := getMarketplaceVoucherDiscount(*claimed.MarketplaceVoucher, discountBase, trxDelivery)
+		if discount <= appliedDiscount || (suggestion != nil && discount <= suggestion.Discount) {
+			continue
+		}
+
+		reason, err := u.voucherRuleUsecase.CheckMarketplaceVoucher(*claimed.MarketplaceVoucher, trxTotal, input)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			suggestion = &dto.SuggestedVoucherDTO{Code: claimed.MarketplaceVoucher.Code, Discount: discount}
+		}
+	}
+
+	return suggestion, nil
+}
+
+func (u *orderItemUsecaseImpl) suggestMerchantVoucher(claimedVouchers []entity.UserVoucher, merchantDomain string, subTotal float64, appliedDiscount float64, input dto.VoucherRuleCheckDTO) (*dto.SuggestedVoucherDTO, error) {
+	var suggestion *dto.SuggestedVoucherDTO
+	for _, claimed := range claimedVouchers {
+		if claimed.MerchantVoucher == nil || claimed.MerchantVoucher.MerchantDomain != merchantDomain {
+			continue
+		}
+
+		discount := getMerchantVoucherDiscount(*claimed.MerchantVoucher, subTotal)
+		if discount <= appliedDiscount || (suggestion != nil && discount <= suggestion.Discount) {
+			continue
+		}
+
+		reason, err := u.voucherRuleUsecase.CheckMerchantVoucher(*claimed.MerchantVoucher, subTotal, input)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			suggestion = &dto.SuggestedVoucherDTO{Code: claimed.MerchantVoucher.Code, Discount: discount}
+		}
+	}
+
+	return suggestion, nil
+}
+
+// getAutoApplyVoucherCandidates gathers the listed vouchers of the marketplace and the
+// ordered merchants along with the ones the user claimed.
+func (u *orderItemUsecaseImpl) getAutoApplyVoucherCandidates(claimedVouchers []entity.UserVoucher, merchantDomains []string) ([]entity.MarketplaceVoucher, map[string][]entity.MerchantVoucher, error) {
+	mpVouchers, err := u.marketplaceVoucherRepository.GetMarketplaceVoucherList()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var merchantVouchers = make(map[string][]entity.MerchantVoucher)
+	for _, merchantDomain := range merchantDomains {
+		merchantVouchers[merchantDomain], err = u.merchantRepository.GetMerchantVoucherList(merchantDomain)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var isListed = make(map[uint]bool)
+	for _, mpVoucher := range mpVouchers {
+		isListed[mpVoucher.ID] = true
+	}
+	var isMerchantListed = make(map[uint]bool)
+	for _, sellerVouchers := range merchantVouchers {
+		for _, sellerVoucher := range sellerVouchers {
+			isMerchantListed[sellerVoucher.ID] = true
+		}
+	}
+
+	for _, claimed := range claimedVouchers {
+		if claimed.MarketplaceVoucher != nil && !isListed[claimed.MarketplaceVoucher.ID] {
+			mpVouchers = append(mpVouchers, *claimed.MarketplaceVoucher)
+			isListed[claimed.MarketplaceVoucher.ID] = true
+		}
+		if claimed.MerchantVoucher != nil && !isMerchantListed[claimed.MerchantVoucher.ID] {
+			merchantDomain := claimed.MerchantVoucher.MerchantDomain
+			if _, ok := merchantVouchers[merchantDomain]; ok {
+				merchantVouchers[merchantDomain] = append(merchantVouchers[merchantDomain], *claimed.MerchantVoucher)
+				isMerchantListed[claimed.MerchantVoucher.ID] = true
+			}
+		}
+	}
+
+	return mpVouchers, merchantVouchers, nil
+}
+
+// pickMarketplaceVoucher applies the candidate saving the most, a voucher taking
+// nothing off is left unused so it does not spend its quota.
+func (u *orderItemUsecaseImpl) pickMarketplaceVoucher(candidates []entity.MarketplaceVoucher, trxTotal float64, discountBase float64, trxDelivery float64, input dto.VoucherRuleCheckDTO) (*entity.MarketplaceVoucher, []dto.VoucherCandidateDTO, error) {
+	var picked *entity.MarketplaceVoucher
+	var pickedDiscount float64
+	var res = make([]dto.VoucherCandidateDTO, 0, len(candidates))
+	for i, candidate := range candidates {
+		reason, err := u.voucherRuleUsecase.CheckMarketplaceVoucher(candidate, trxTotal, input)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var discount float64
+		if reason == "" {
+			discount = getMarketplaceVoucherDiscount(candidate, discountBase, trxDelivery)
+			if discount > pickedDiscount {
+				picked = &candidates[i]
+				pickedDiscount = discount
+			}
+		}
+		res = append(res, dto.VoucherCandidateDTO{
+			Code:         candidate.Code,
+			Discount:     discount,
+			IsApplicable: reason == "",
+			Reason:       reason,
+		})
+	}
+
+	if picked != nil {
+		markVoucherCandidateApplied(res, picked.Code)
+	}
+	sortVoucherCandidates(res)
+	return picked, res, nil
+}
+
+func (u *orderItemUsecaseImpl) pickMerchantVoucher(candidates []entity.MerchantVoucher, subTotal float64, input dto.VoucherRuleCheckDTO) (*entity.MerchantVoucher, []dto.VoucherCandidateDTO, error) {
+	var picked *entity.MerchantVoucher
+	var pickedDiscount float64
+	var res = make([]dto.VoucherCandidateDTO, 0, len(candidates))
+	for i, candidate := range candidates {
+		reason, err := u.voucherRuleUsecase.CheckMerchantVoucher(candidate, subTotal, input)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var discount float64
+		if reason == "" {
+			discount = getMerchantVoucherDiscount(candidate, subTotal)
+			if discount > pickedDiscount {
+				picked = &candidates[i]
+				pickedDiscount = discount
+			}
+		}
+		res = append(res, dto.VoucherCandidateDTO{
+			Code:         candidate.Code,
+			Discount:     discount,
+			IsApplicable: reason == "",
+			Reason:       reason,
+		})
+	}
+
+	if picked != nil {
+		markVoucherCandidateApplied(res, picked.Code)
+	}
+	sortVoucherCandidates(res)
+	return picked, res, nil
+}
+
+func markVoucherCandidateApplied(candidates []dto.VoucherCandidateDTO, code string) {
+	for i := range candidates {
+		if candidates[i].Code == code {
+			candidates[i].IsApplied = true
+			return
+		}
+	}
+}
+
+// sortVoucherCandidates lists the applied voucher first, then the alternatives by
+// saving and the rejected ones last.
+func sortVoucherCandidates(candidates []dto.VoucherCandidateDTO) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].IsApplied != candidates[j].IsApplied {
+			return candidates[i].IsApplied
+		}
+		if candidates[i].IsApplicable != candidates[j].IsApplicable {
+			return candidates[i].IsApplicable
+		}
+		return candidates[i].Discount > candidates[j].Discount
+	})
+}
+
+type merchantDeliveryInfo struct {
+	deliveryOption *entity.DeliveryOption
+	deliveryInfo   *dto.RajaOngkirDeliveryInfoResDTO
+	shippingRules  []entity.MerchantShippingRule
+	err            error
+}
+
+// getMerchantsDeliveryInfo looks up the selected courier cost of every merchant concurrently.
+func (u *orderItemUsecaseImpl) getMerchantsDeliveryInfo(merchantIds []uint, mapMerchantDeliveryOption map[uint]string, merchantCityMap map[uint]*entity.City, userCity entity.City, merchantWeightMap map[uint]int) (map[uint]merchantDeliveryInfo, error) {
+	results := make([]merchantDeliveryInfo, len(merchantIds))
+
+	var wg sync.WaitGroup
+	for i, merchantId := range merchantIds {
+		courier := mapMerchantDeliveryOption[merchantId]
+		if courier == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, merchantId uint, courier string) {
+			defer wg.Done()
+
+			deliveryOption, err := u.deliveryRepository.GetDeliveryOptionByMerchantID(merchantId, courier)
+			if err != nil {
+				results[i].err = err
+				return
+			}
+
+			shippingRules, err := u.deliveryRepository.GetMerchantShippingRules(merchantId, courier)
+			if err != nil {
+				results[i].err = err
+				return
+			}
+
+			deliveryInfo, err := u.deliveryRepository.GetDeliveryInfo(dto.RajaOngkirDeliveryInfoReqDTO{
+				Origin:      int(merchantCityMap[merchantId].RoId),
+				Destination: int(userCity.RoId),
+				Weight:      merchantWeightMap[merchantId],
+				Courier:     courier,
+			})
+			results[i] = merchantDeliveryInfo{
+				deliveryOption: deliveryOption,
+				deliveryInfo:   deliveryInfo,
+				shippingRules:  shippingRules,
+				err:            err,
+			}
+		}(i, merchantId, courier)
+	}
+	wg.Wait()
+
+	merchantDeliveryMap := make(map[uint]merchantDeliveryInfo)
+	for i, merchantId := range merchantIds {
+		if results[i].err != nil {
+			return nil, results[i].err
+		}
+		merchantDeliveryMap[merchantId] = results[i]
+	}
+
+	return merchantDeliveryMap, nil
+}
+
 func (u *orderItemUsecaseImpl) checkOrderItemAvailability(userId uint, orderItem dto.MakeOrderCheckoutProductDTO) (*dto.MakeOrderCheckoutProductDTO, error) {
 	if orderItem.Quantity <= 0 {
 		return nil, domain.ErrOrderQuantityNotValid
@@ -330,6 +807,15 @@ func (u *orderItemUsecaseImpl) checkOrderItemAvailability(userId uint, orderItem
 		return nil, domain.ErrOrderProductNotAvailable
 	}
 
+	// the user's own earlier holds are released once this checkout is created
+	reservedStock, err := u.stockReservationRepository.GetReservedStockByOtherUsers([]uint{*orderItem.VariantItemId}, userId)
+	if err != nil {
+		return nil, err
+	}
+	if stock < reservedStock[*orderItem.VariantItemId]+uint(orderItem.Quantity) {
+		return nil, domain.ErrStockReservationInsufficient
+	}
+
 	return &orderItem, nil
 }
 
@@ -351,7 +837,7 @@ func (u *orderItemUsecaseImpl) getUserAddress(addressId uint, user entity.User)
 	return address, nil
 }
 
-func (u *orderItemUsecaseImpl) fillOrderItemDTO(orderItem entity.OrderItem) dto.OrderItemDTO {
+func (u *orderItemUsecaseImpl) fillOrderItemDTO(orderItem entity.OrderItem, otherReservedStock uint, orderReservedStock uint) dto.OrderItemDTO {
 	discountPrice := orderItem.VariantItem.Price
 	if orderItem.Product.ProductPromotion != nil && orderItem.Product.ProductPromotion.Promotion.MaxDiscountedQty >= int(orderItem.Quantity) {
 		if orderItem.Product.ProductPromotion.Promotion.PromotionTypeId == dto.NOMINAL_PROMOTION_ID {
@@ -364,6 +850,12 @@ func (u *orderItemUsecaseImpl) fillOrderItemDTO(orderItem entity.OrderItem) dto.
 		discountPrice = 100
 	}
 
+	// stock held by other orders can't be bought, even if this order's reservation expired
+	availableStock := uint(0)
+	if orderItem.VariantItem.Stock > otherReservedStock {
+		availableStock = orderItem.VariantItem.Stock - otherReservedStock
+	}
+
 	newOrderPH := dto.OrderItemDTO{
 		CartItemId:     orderItem.ID,
 		ProductId:      orderItem.ProductId,
@@ -381,10 +873,12 @@ func (u *orderItemUsecaseImpl) fillOrderItemDTO(orderItem entity.OrderItem) dto.
 		DiscountPrice:  discountPrice,
 		Quantity:       int(orderItem.Quantity),
 		Stock:          int(orderItem.VariantItem.Stock),
+		ReservedStock:  int(orderReservedStock),
+		AvailableStock: int(availableStock),
 		Notes:          &orderItem.Notes,
 		IsValid: orderItem.Product.DeletedAt == gorm.DeletedAt{} &&
 			orderItem.VariantItem.DeletedAt == gorm.DeletedAt{} &&
-			orderItem.Quantity <= orderItem.VariantItem.Stock,
+			orderItem.Quantity <= availableStock,
 	}
 
 	if len(orderItem.Product.ProductImages) != 0 {
@@ -403,3 +897,15 @@ func (u *orderItemUsecaseImpl) fillOrderItemDTO(orderItem entity.OrderItem) dto.
 
 	return newOrderPH
 }
+
+func (u *orderItemUsecaseImpl) CronReleaseExpiredStockReservations() {
+	released, err := u.stockReservationRepository.ReleaseExpiredReservations()
+	if err != nil {
+		log.Error().Msgf("CronReleaseExpiredStockReservations Error: %v", err)
+		return
+	}
+
+	if released > 0 {
+		log.Info().Msgf("%d expired stock reservations released", released)
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"time"
 
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/config"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cronjob"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"github.com/rs/zerolog/log"
 )
 
 type PaymentRecordUsecase interface {
@@ -15,6 +18,8 @@ type PaymentRecordUsecase interface {
 
 	GetWaitingForPayment(username string) ([]dto.WaitingForPaymentDTO, error)
 	GetWaitingForPaymentDetail(username string, paymentId string) (*dto.WaitingForPaymentDetailDTO, error)
+
+	CronReconcilePendingPayments()
 }
 
 type PaymentRecordUsecaseConfig struct {
@@ -25,6 +30,9 @@ type PaymentRecordUsecaseConfig struct {
 
 	WalletUsecase      WalletUsecase
 	TransactionUsecase TransactionUsecase
+
+	PaymentProviderRegistry repository.PaymentProviderRegistry
+	Cron                    *cronjob.CronJob
 }
 
 type paymentRecordUsecaseImpl struct {
@@ -35,10 +43,12 @@ type paymentRecordUsecaseImpl struct {
 
 	walletUsecase      WalletUsecase
 	transactionUsecase TransactionUsecase
+
+	paymentProviderRegistry repository.PaymentProviderRegistry
 }
 
 func NewPaymentRecordUsecase(c PaymentRecordUsecaseConfig) PaymentRecordUsecase {
-	return &paymentRecordUsecaseImpl{
+	paymentRecordUsecase := &paymentRecordUsecaseImpl{
 		paymentRecordRepository:            c.PaymentRecordRepository,
 		transactionPaymentRecordRepository: c.TransactionPaymentRecordRepository,
 		walletRepository:                   c.WalletRepository,
@@ -46,7 +56,20 @@ func NewPaymentRecordUsecase(c PaymentRecordUsecaseConfig) PaymentRecordUsecase
 
 		walletUsecase:      c.WalletUsecase,
 		transactionUsecase: c.TransactionUsecase,
+
+		paymentProviderRegistry: c.PaymentProviderRegistry,
+	}
+
+	if c.Cron != nil {
+		_, err := c.Cron.AddJob("* * * * *", paymentRecordUsecase.CronReconcilePendingPayments)
+		if err != nil {
+			log.Error().Msg("error scheduling payment status reconciliation")
+		} else {
+			log.Info().Msg("payment status reconciliation scheduled")
+		}
 	}
+
+	return paymentRecordUsecase
 }
 
 func (u *paymentRecordUsecaseImpl) GetWaitingForPaymentDetail(username string, paymentId string) (*dto.WaitingForPaymentDetailDTO, error) {
@@ -105,7 +128,7 @@ func (u *paymentRecordUsecaseImpl) GetWaitingForPaymentDetail(username string, p
 			return nil, domain.ErrUnmarshalJSONPaymentDetails
 		}
 
-		trxPaymentDetails.Total = trxPaymentDetails.Subtotal + trxPaymentDetails.DeliveryFee - trxPaymentDetails.MarketplaceVoucherNominal - trxPaymentDetails.MarketplaceVoucherNominal
+		trxPaymentDetails.Total = trxPaymentDetails.PaidAmount()
 
 		waitingForPaymentDTO.Transactions = append(waitingForPaymentDTO.Transactions, dto.WaitingForPaymentTransactions{
 			TransactionDetailProductResDTO: dto.TransactionDetailProductResDTO{
@@ -173,7 +196,7 @@ func (u *paymentRecordUsecaseImpl) UpdatePaymentRecordStatus(paymentId string, p
 		return errPaymentRecord
 	}
 	if paymentRecord.PaidAt != nil || paymentRecord.CanceledAt != nil {
-		return domain.ErrPaymentIdExpired
+		return u.checkSettledPaymentRecord(*paymentRecord, paymentAmount, isSuccess)
 	}
 	if float64(paymentAmount) != paymentRecord.Amount {
 		return domain.ErrPaymentAmountNotMatch
@@ -195,6 +218,14 @@ func (u *paymentRecordUsecaseImpl) UpdatePaymentRecordStatus(paymentId string, p
 
 	if len(trxPayRecords) > 0 && errTrxProduct == nil {
 		err := u.transactionUsecase.HandleTransactionSlpRes(trxPayRecords, isSuccess, *paymentRecord)
+		if err == domain.ErrPaymentAlreadySettled {
+			// a concurrent callback or reconcile run won the race
+			settledRecord, errSettled := u.paymentRecordRepository.GetByPaymentId(paymentId)
+			if errSettled != nil {
+				return errSettled
+			}
+			return u.checkSettledPaymentRecord(*settledRecord, paymentAmount, isSuccess)
+		}
 		if err != nil {
 			return err
 		}
@@ -202,3 +233,64 @@ func (u *paymentRecordUsecaseImpl) UpdatePaymentRecordStatus(paymentId string, p
 
 	return nil
 }
+
+// checkSettledPaymentRecord accepts a replay of the outcome the payment was
+// already settled with by the reconcile cron or an earlier callback.
+func (u *paymentRecordUsecaseImpl) checkSettledPaymentRecord(paymentRecord entity.PaymentRecord, paymentAmount uint, isSuccess bool) error {
+	if (paymentRecord.PaidAt != nil) == isSuccess && float64(paymentAmount) == paymentRecord.Amount {
+		return nil
+	}
+	return domain.ErrPaymentIdExpired
+}
+
+// CronReconcilePendingPayments asks the payment provider for the status of
+// SeaLabsPay payments whose callback has not arrived yet.
+func (u *paymentRecordUsecaseImpl) CronReconcilePendingPayments() {
+	cronConfig := config.Config.CronConfig
+	createdAfter := time.Now().Add(-time.Duration(cronConfig.PaymentReconcileMaxAgeHours) * time.Hour)
+	createdBefore := time.Now().Add(-time.Duration(cronConfig.PaymentReconcileOlderThanMinutes) * time.Minute)
+	paymentRecords, err := u.paymentRecordRepository.GetPendingByPaymentMethod(dto.PAYMENT_METHOD_ID_SLP, createdAfter, createdBefore, cronConfig.PaymentReconcileBatchSize)
+	if err != nil {
+		log.Error().Msgf("CronReconcilePendingPayments Error: %v", err)
+		return
+	}
+
+	// payments the provider can't settle go to the back of the queue so they
+	// don't starve newer ones
+	var paymentIds []string
+	for _, paymentRecord := range paymentRecords {
+		paymentIds = append(paymentIds, paymentRecord.PaymentId)
+	}
+	err = u.paymentRecordRepository.UpdateLastCheckedAt(paymentIds)
+	if err != nil {
+		log.Error().Msgf("CronReconcilePendingPayments Error: %v", err)
+	}
+
+	for _, paymentRecord := range paymentRecords {
+		err = u.reconcilePaymentRecord(paymentRecord)
+		if err != nil {
+			log.Error().Msgf("CronReconcilePendingPayments payment %s: %v", paymentRecord.PaymentId, err)
+		}
+	}
+}
+
+func (u *paymentRecordUsecaseImpl) reconcilePaymentRecord(paymentRecord entity.PaymentRecord) error {
+	provider, err := u.paymentProviderRegistry.Get(paymentRecord.PaymentMethod.Code)
+	if err != nil {
+		return err
+	}
+
+	paymentStatus, err := provider.QueryStatus(paymentRecord.PaymentId)
+	if err != nil {
+		return err
+	}
+
+	switch paymentStatus.Status {
+	case dto.PAYMENT_STATUS_PAID:
+		return u.UpdatePaymentRecordStatus(paymentRecord.PaymentId, paymentStatus.Amount, true)
+	case dto.PAYMENT_STATUS_FAILED, dto.PAYMENT_STATUS_CANCELED:
+		return u.UpdatePaymentRecordStatus(paymentRecord.PaymentId, paymentStatus.Amount, false)
+	}
+
+	return nil
+}
@@ -0,0 +1,407 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/config"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/usecase"
+)
+
+// paymentRecordRepositoryStub keeps payment records in memory and follows the
+// ordering contract of GetPendingByPaymentMethod.
+type paymentRecordRepositoryStub struct {
+	repository.PaymentRecordRepository
+
+	mu      sync.Mutex
+	records []*entity.PaymentRecord
+}
+
+func (r *paymentRecordRepositoryStub) GetByPaymentId(paymentId string) (*entity.PaymentRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, record := range r.records {
+		if record.PaymentId == paymentId {
+			res := *record
+			return &res, nil
+		}
+	}
+	return nil, domain.ErrPaymentIdNotFound
+}
+
+func (r *paymentRecordRepositoryStub) GetPendingByPaymentMethod(paymentMethodId uint, createdAfter time.Time, createdBefore time.Time, limit int) ([]entity.PaymentRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var pending []entity.PaymentRecord
+	for _, record := range r.records {
+		if record.PaymentMethodId != paymentMethodId || record.PaidAt != nil || record.CanceledAt != nil {
+			continue
+		}
+		if record.CreatedAt.Before(createdAfter) || record.CreatedAt.After(createdBefore) {
+			continue
+		}
+		pending = append(pending, *record)
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		a, b := pending[i], pending[j]
+		if (a.LastCheckedAt == nil) != (b.LastCheckedAt == nil) {
+			return a.LastCheckedAt == nil
+		}
+		if a.LastCheckedAt != nil && !a.LastCheckedAt.Equal(*b.LastCheckedAt) {
+			return a.LastCheckedAt.Before(*b.LastCheckedAt)
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+
+	return pending, nil
+}
+
+func (r *paymentRecordRepositoryStub) UpdateLastCheckedAt(paymentIds []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, record := range r.records {
+		for _, paymentId := range paymentIds {
+			if record.PaymentId == paymentId {
+				checkedAt := now
+				record.LastCheckedAt = &checkedAt
+			}
+		}
+	}
+	return nil
+}
+
+// settle mirrors the conditional update of the real repository, only an
+// unsettled payment record can be settled.
+func (r *paymentRecordRepositoryStub) settle(paymentId string, isSuccess bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, record := range r.records {
+		if record.PaymentId != paymentId || record.PaidAt != nil || record.CanceledAt != nil {
+			continue
+		}
+		if isSuccess {
+			record.PaidAt = &now
+		} else {
+			record.CanceledAt = &now
+		}
+		return true
+	}
+	return false
+}
+
+type transactionPaymentRecordRepositoryStub struct {
+	repository.TransactionPaymentRecordRepository
+}
+
+func (r *transactionPaymentRecordRepositoryStub) GetByPaymentId(paymentId string) ([]entity.TransactionPaymentRecord, error) {
+	return []entity.TransactionPaymentRecord{{PaymentId: paymentId}}, nil
+}
+
+type walletRepositoryStub struct {
+	repository.WalletRepository
+}
+
+func (r *walletRepositoryStub) GetTransactionByPaymentId(paymentId string) (*entity.WalletTransactionRecord, error) {
+	return nil, domain.ErrPaymentIdNotFound
+}
+
+// transactionUsecaseStub settles the payment record the way the real handler
+// does and remembers the outcome of every payment it settled.
+type transactionUsecaseStub struct {
+	usecase.TransactionUsecase
+
+	paymentRecordRepository *paymentRecordRepositoryStub
+	mu                      sync.Mutex
+	handled                 map[string][]bool
+
+	// arrived, when set, holds every handler call until the expected number
+	// of calls arrived so concurrent settles race on the same payment.
+	arrived chan struct{}
+	expect  int
+}
+
+func (u *transactionUsecaseStub) HandleTransactionSlpRes(trxRecords []entity.TransactionPaymentRecord, isSuccess bool, paymentRec entity.PaymentRecord) error {
+	if u.arrived != nil {
+		u.arrived <- struct{}{}
+		deadline := time.Now().Add(5 * time.Second)
+		for len(u.arrived) < u.expect && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	if !u.paymentRecordRepository.settle(paymentRec.PaymentId, isSuccess) {
+		return domain.ErrPaymentAlreadySettled
+	}
+
+	u.mu.Lock()
+	u.handled[paymentRec.PaymentId] = append(u.handled[paymentRec.PaymentId], isSuccess)
+	u.mu.Unlock()
+	return nil
+}
+
+// sealabspayServerStub answers SeaLabsPay transaction status requests.
+type sealabspayServerStub struct {
+	mu       sync.Mutex
+	sequence int
+	amounts  map[string]uint
+	statuses map[string]string
+}
+
+func (s *sealabspayServerStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	txnId := strings.TrimPrefix(r.URL.Path, "/transaction/")
+	if r.Method != http.MethodGet || r.URL.Query().Get("signature") == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	amount, ok := s.amounts[txnId]
+	status := s.statuses[txnId]
+	s.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(dto.SealabspayStatusResDTO{
+		Code: "OK",
+		Data: dto.SealabspayStatusDataDTO{
+			TxnId:  txnId,
+			Amount: fmt.Sprint(amount),
+			Status: status,
+		},
+	})
+}
+
+func (s *sealabspayServerStub) charge(amount uint) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sequence++
+	txnId := fmt.Sprint(s.sequence)
+	s.amounts[txnId] = amount
+	s.statuses[txnId] = "TXN_PENDING"
+	return dto.PAYMENT_METHOD_CODE_SLP + txnId
+}
+
+func (s *sealabspayServerStub) setStatus(paymentId string, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.statuses[strings.TrimPrefix(paymentId, dto.PAYMENT_METHOD_CODE_SLP)] = status
+}
+
+type reconcileFixture struct {
+	usecase                 usecase.PaymentRecordUsecase
+	sealabspay              *sealabspayServerStub
+	paymentRecordRepository *paymentRecordRepositoryStub
+	transactionUsecase      *transactionUsecaseStub
+}
+
+func newReconcileFixture(t *testing.T, batchSize int) *reconcileFixture {
+	cronConfig := config.Config.CronConfig
+	config.Config.CronConfig.PaymentReconcileBatchSize = batchSize
+	config.Config.CronConfig.PaymentReconcileOlderThanMinutes = 2
+	config.Config.CronConfig.PaymentReconcileMaxAgeHours = 24
+	t.Cleanup(func() {
+		config.Config.CronConfig = cronConfig
+	})
+
+	sealabspay := &sealabspayServerStub{
+		amounts:  make(map[string]uint),
+		statuses: make(map[string]string),
+	}
+	server := httptest.NewServer(sealabspay)
+	t.Cleanup(server.Close)
+
+	provider := repository.NewSealabspayPaymentProvider(repository.SealabspayPaymentProviderConfig{
+		SealabspayRepository: repository.NewSealabspayRepository(repository.SealabspayRepositoryConfig{
+			HttpClient: server.Client(),
+			Url:        server.URL,
+		}),
+	})
+	paymentRecordRepository := &paymentRecordRepositoryStub{}
+	transactionUsecase := &transactionUsecaseStub{
+		paymentRecordRepository: paymentRecordRepository,
+		handled:                 make(map[string][]bool),
+	}
+
+	return &reconcileFixture{
+		usecase: usecase.NewPaymentRecordUsecase(usecase.PaymentRecordUsecaseConfig{
+			PaymentRecordRepository:            paymentRecordRepository,
+			TransactionPaymentRecordRepository: &transactionPaymentRecordRepositoryStub{},
+			WalletRepository:                   &walletRepositoryStub{},
+			TransactionUsecase:                 transactionUsecase,
+			PaymentProviderRegistry: repository.NewPaymentProviderRegistry(repository.PaymentProviderRegistryConfig{
+				Providers: []repository.PaymentProvider{provider},
+			}),
+		}),
+		sealabspay:              sealabspay,
+		paymentRecordRepository: paymentRecordRepository,
+		transactionUsecase:      transactionUsecase,
+	}
+}
+
+// charge creates a SeaLabsPay charge and its pending payment record created age ago.
+func (f *reconcileFixture) charge(t *testing.T, amount uint, age time.Duration) string {
+	paymentId := f.sealabspay.charge(amount)
+
+	f.paymentRecordRepository.records = append(f.paymentRecordRepository.records, &entity.PaymentRecord{
+		PaymentId:       paymentId,
+		PaymentMethodId: dto.PAYMENT_METHOD_ID_SLP,
+		PaymentMethod:   entity.PaymentMethod{Code: dto.PAYMENT_METHOD_CODE_SLP},
+		Amount:          float64(amount),
+		CreatedAt:       time.Now().Add(-age),
+	})
+	return paymentId
+}
+
+// raceSettles makes the next n handler calls wait for each other.
+func (f *reconcileFixture) raceSettles(n int) {
+	f.transactionUsecase.arrived = make(chan struct{}, n)
+	f.transactionUsecase.expect = n
+}
+
+func (f *reconcileFixture) handled(paymentId string) []bool {
+	f.transactionUsecase.mu.Lock()
+	defer f.transactionUsecase.mu.Unlock()
+
+	return f.transactionUsecase.handled[paymentId]
+}
+
+func TestCronReconcilePendingPaymentsSettlesProviderStatus(t *testing.T) {
+	f := newReconcileFixture(t, 50)
+	paid := f.charge(t, 10000, 10*time.Minute)
+	failed := f.charge(t, 20000, 10*time.Minute)
+	pending := f.charge(t, 30000, 10*time.Minute)
+	fresh := f.charge(t, 40000, time.Minute)
+	tooOld := f.charge(t, 50000, 48*time.Hour)
+	f.sealabspay.setStatus(paid, dto.SLP_SUCCESS_CODE)
+	f.sealabspay.setStatus(failed, dto.SLP_FAILED_CODE)
+	f.sealabspay.setStatus(fresh, dto.SLP_SUCCESS_CODE)
+	f.sealabspay.setStatus(tooOld, dto.SLP_SUCCESS_CODE)
+
+	f.usecase.CronReconcilePendingPayments()
+
+	if got := f.handled(paid); len(got) != 1 || !got[0] {
+		t.Errorf("paid payment handled as %v, want [true]", got)
+	}
+	if got := f.handled(failed); len(got) != 1 || got[0] {
+		t.Errorf("failed payment handled as %v, want [false]", got)
+	}
+	for _, paymentId := range []string{pending, fresh, tooOld} {
+		if got := f.handled(paymentId); len(got) != 0 {
+			t.Errorf("payment %s handled as %v, want untouched", paymentId, got)
+		}
+	}
+}
+
+func TestCronReconcilePendingPaymentsRotatesStuckPayments(t *testing.T) {
+	f := newReconcileFixture(t, 2)
+	stuck := []string{
+		f.charge(t, 10000, 30*time.Minute),
+		f.charge(t, 10000, 20*time.Minute),
+	}
+	newer := f.charge(t, 10000, 10*time.Minute)
+	f.sealabspay.setStatus(newer, dto.SLP_SUCCESS_CODE)
+
+	// the first batch only holds the two oldest payments, which stay pending
+	f.usecase.CronReconcilePendingPayments()
+	if got := f.handled(newer); len(got) != 0 {
+		t.Fatalf("newer payment handled in the first batch: %v", got)
+	}
+
+	f.usecase.CronReconcilePendingPayments()
+	if got := f.handled(newer); len(got) != 1 || !got[0] {
+		t.Errorf("newer payment handled as %v after the batch rotated, want [true]", got)
+	}
+	for _, paymentId := range stuck {
+		if got := f.handled(paymentId); len(got) != 0 {
+			t.Errorf("stuck payment %s handled as %v, want untouched", paymentId, got)
+		}
+	}
+}
+
+func TestUpdatePaymentRecordStatusAfterReconcile(t *testing.T) {
+	f := newReconcileFixture(t, 50)
+	paid := f.charge(t, 10000, 10*time.Minute)
+	f.sealabspay.setStatus(paid, dto.SLP_SUCCESS_CODE)
+
+	f.usecase.CronReconcilePendingPayments()
+
+	// the provider callback arriving after the cron settled the payment
+	if err := f.usecase.UpdatePaymentRecordStatus(paid, 10000, true); err != nil {
+		t.Errorf("replayed paid callback: got %v, want nil", err)
+	}
+	if err := f.usecase.UpdatePaymentRecordStatus(paid, 10000, false); err != domain.ErrPaymentIdExpired {
+		t.Errorf("conflicting failed callback: got %v, want %v", err, domain.ErrPaymentIdExpired)
+	}
+	if got := f.handled(paid); len(got) != 1 {
+		t.Errorf("payment handled %d times, want once", len(got))
+	}
+}
+
+func TestCronReconcilePendingPaymentsRunTwiceSettlesOnce(t *testing.T) {
+	f := newReconcileFixture(t, 50)
+	paid := f.charge(t, 10000, 10*time.Minute)
+	f.sealabspay.setStatus(paid, dto.SLP_SUCCESS_CODE)
+	f.raceSettles(2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.usecase.CronReconcilePendingPayments()
+		}()
+	}
+	wg.Wait()
+
+	if got := f.handled(paid); len(got) != 1 || !got[0] {
+		t.Errorf("payment settled as %v by two reconcile runs, want [true]", got)
+	}
+}
+
+func TestUpdatePaymentRecordStatusDuringReconcileSettlesOnce(t *testing.T) {
+	f := newReconcileFixture(t, 50)
+	paid := f.charge(t, 10000, 10*time.Minute)
+	f.sealabspay.setStatus(paid, dto.SLP_SUCCESS_CODE)
+	f.raceSettles(2)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f.usecase.CronReconcilePendingPayments()
+	}()
+
+	// the provider callback racing the cron on the same payment
+	if err := f.usecase.UpdatePaymentRecordStatus(paid, 10000, true); err != nil {
+		t.Errorf("callback racing the reconcile: got %v, want nil", err)
+	}
+	<-done
+
+	if got := f.handled(paid); len(got) != 1 || !got[0] {
+		t.Errorf("payment settled as %v by the callback and the reconcile, want [true]", got)
+	}
+}
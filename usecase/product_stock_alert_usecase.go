@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"strings"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/config"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cronjob"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"github.com/rs/zerolog/log"
+)
+
+type ProductStockAlertUsecase interface {
+	UpdateLowStockThreshold(username string, productId uint, req dto.UpdateLowStockThresholdReqDTO) (*dto.ProductStockAlertResDTO, error)
+	GetLowStockProductList(username string, req dto.PaginationRequest) (*dto.LowStockProductListResDTO, error)
+	CronNotifyLowStockProducts()
+}
+
+type ProductStockAlertUsecaseConfig struct {
+	ProductStockAlertRepository repository.ProductStockAlertRepository
+	ProductRepository           repository.ProductRepository
+	MerchantRepository          repository.MerchantRepository
+	Cron                        *cronjob.CronJob
+}
+
+type productStockAlertUsecaseImpl struct {
+	productStockAlertRepository repository.ProductStockAlertRepository
+	productRepository           repository.ProductRepository
+	merchantRepository          repository.MerchantRepository
+}
+
+func NewProductStockAlertUsecase(c ProductStockAlertUsecaseConfig) ProductStockAlertUsecase {
+	productStockAlertUsecase := &productStockAlertUsecaseImpl{
+		productStockAlertRepository: c.ProductStockAlertRepository,
+		productRepository:           c.ProductRepository,
+		merchantRepository:          c.MerchantRepository,
+	}
+
+	if c.Cron != nil {
+		_, err := c.Cron.AddJob("*/10 * * * *", productStockAlertUsecase.CronNotifyLowStockProducts)
+		if err != nil {
+			log.Error().Msg("error scheduling low stock alert")
+		} else {
+			log.Info().Msg("low stock alert scheduled")
+		}
+	}
+
+	return productStockAlertUsecase
+}
+
+func (u *productStockAlertUsecaseImpl) UpdateLowStockThreshold(username string, productId uint, req dto.UpdateLowStockThresholdReqDTO) (*dto.ProductStockAlertResDTO, error) {
+	merchant, err := u.merchantRepository.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := u.productRepository.GetProductByProductId(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.MerchantDomain != merchant.Domain {
+		return nil, domain.ErrUpdateProductUnauthorized
+	}
+
+	alert, err := u.productStockAlertRepository.UpsertThreshold(product.ID, *req.Threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ProductStockAlertResDTO{
+		ProductId:  alert.ProductId,
+		Threshold:  alert.Threshold,
+		IsLowStock: alert.IsLowStock,
+	}, nil
+}
+
+func (u *productStockAlertUsecaseImpl) GetLowStockProductList(username string, req dto.PaginationRequest) (*dto.LowStockProductListResDTO, error) {
+	merchant, err := u.merchantRepository.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	products, totalData, err := u.productStockAlertRepository.GetLowStockProducts(merchant.Domain, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if products == nil {
+		products = []dto.LowStockProductDTO{}
+	}
+
+	return &dto.LowStockProductListResDTO{
+		PaginationResponse: dto.PaginationResponse{
+			TotalData:   totalData,
+			TotalPage:   (totalData + int64(req.Limit) - 1) / int64(req.Limit),
+			CurrentPage: req.Page,
+		},
+		Products: products,
+	}, nil
+}
+
+// CronNotifyLowStockProducts alerts merchants once for every product that
+// dropped to its low-stock threshold since the last run.
+func (u *productStockAlertUsecaseImpl) CronNotifyLowStockProducts() {
+	err := u.productStockAlertRepository.ResetRecoveredProducts()
+	if err != nil {
+		log.Error().Msgf("CronNotifyLowStockProducts reset Error: %v", err)
+		return
+	}
+
+	products, err := u.productStockAlertRepository.GetUnalertedLowStockProducts(dto.PRODUCT_LOW_STOCK_ALERT_BATCH_SIZE)
+	if err != nil {
+		log.Error().Msgf("CronNotifyLowStockProducts Error: %v", err)
+		return
+	}
+
+	var merchantUserIds []uint
+	var merchantProducts = make(map[uint][]dto.LowStockProductAlertDTO)
+	for _, product := range products {
+		if merchantProducts[product.MerchantUserId] == nil {
+			merchantUserIds = append(merchantUserIds, product.MerchantUserId)
+		}
+		merchantProducts[product.MerchantUserId] = append(merchantProducts[product.MerchantUserId], product)
+	}
+
+	for _, merchantUserId := range merchantUserIds {
+		notifications := make(map[uint]entity.Notification)
+		for _, product := range merchantProducts[merchantUserId] {
+			notifications[product.ID] = entity.Notification{
+				UserId:  merchantUserId,
+				Type:    dto.NOTIFICATION_TYPE_LOW_STOCK,
+				Title:   "Low stock",
+				Message: fmt.Sprintf("%s has only %d left in stock", product.Title, product.MinStock),
+			}
+		}
+
+		alertedIds, err := u.productStockAlertRepository.MarkLowStockAlerted(notifications)
+		if err != nil {
+			log.Error().Msgf("CronNotifyLowStockProducts merchant user %d: %v", merchantUserId, err)
+			continue
+		}
+
+		// another run may have alerted some of the products in the meantime
+		alerted := make(map[uint]bool)
+		for _, productId := range alertedIds {
+			alerted[productId] = true
+		}
+		var lowStockProducts []dto.LowStockProductAlertDTO
+		for _, product := range merchantProducts[merchantUserId] {
+			if alerted[product.ID] {
+				lowStockProducts = append(lowStockProducts, product)
+			}
+		}
+		if len(lowStockProducts) == 0 {
+			continue
+		}
+
+		err = u.sendLowStockEmail(lowStockProducts)
+		if err != nil {
+			log.Error().Msgf("CronNotifyLowStockProducts merchant user %d email: %v", merchantUserId, err)
+		}
+	}
+}
+
+func (u *productStockAlertUsecaseImpl) sendLowStockEmail(products []dto.LowStockProductAlertDTO) error {
+	b, err := ioutil.ReadFile(dto.SMTP_LOW_STOCK_HTML_PATH)
+	if err != nil {
+		return err
+	}
+
+	var rows strings.Builder
+	for _, product := range products {
+		rows.WriteString(fmt.Sprintf(
+			`<tr><td style="padding:4px 8px">%s</td><td style="padding:4px 8px;text-align:right">%d</td><td style="padding:4px 8px;text-align:right">%d</td></tr>`,
+			html.EscapeString(product.Title), product.MinStock, product.Threshold,
+		))
+	}
+
+	body := strings.Replace(string(b), "{{MERCHANT_NAME}}", html.EscapeString(products[0].MerchantName), 1)
+	body = strings.Replace(body, "{{PRODUCTS}}", rows.String(), 1)
+
+	return util.SMTPSendMail(util.Mail{
+		SenderAddress: config.Config.SmtpConfig.EmailAddress,
+		SenderName:    dto.SMTP_LOW_STOCK_SENDER_NAME,
+		ToAddress:     products[0].MerchantEmail,
+		ToName:        products[0].MerchantName,
+		Subject:       dto.SMTP_LOW_STOCK_SUBJECT,
+		Body:          body,
+	})
+}
@@ -4,15 +4,23 @@ import (
 	"fmt"
 	"strings"
 
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cronjob"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"github.com/rs/zerolog/log"
 )
 
 type ProductUsecase interface {
 	GetProductList(req dto.ProductListReqParamDTO) (*dto.ProductListResDTO, error)
-	GetRecommendationProductList(req dto.PaginationRequest) (*dto.ProductListResDTO, error)
+	GetProductSuggestions(req dto.ProductSuggestionReqDTO) (*dto.ProductSuggestionResDTO, error)
+	GetRecommendationProductList(userJwt *dto.AccessTokenPayload, req dto.PaginationRequest) (*dto.ProductListResDTO, error)
+
+	GetRelatedProducts(slug string, relationType string, req dto.RelatedProductReqDTO) (*dto.RelatedProductListResDTO, error)
+
+	CronRefreshProductSimilarities()
+	CronRefreshRelatedProducts()
 	GetMerchantProductList(username string, req dto.ProductListReqParamDTO) (*dto.ProductSellerListResDTO, error)
 	GetProductDetailsBySlug(userJwt *dto.AccessTokenPayload, slug string) (*dto.ProductDetailResDTO, error)
 	GetAdminProductDetailByProductID(userJwt *dto.AccessTokenPayload, productId uint) (*dto.ProductAdminDetailResDTO, error)
@@ -31,6 +39,10 @@ type ProductUsecaseConfig struct {
 	UserRepository     repository.UserRepository
 	MerchantRepository repository.MerchantRepository
 	MediaUsecase       MediaUsecase
+
+	ProductSuggestionRepository     repository.ProductSuggestionRepository
+	ProductRecommendationRepository repository.ProductRecommendationRepository
+	Cron                            *cronjob.CronJob
 }
 
 type productUsecaseImpl struct {
@@ -39,16 +51,40 @@ type productUsecaseImpl struct {
 	userRepository     repository.UserRepository
 	merchantRepository repository.MerchantRepository
 	mediaUsecase       MediaUsecase
+
+	productSuggestionRepository     repository.ProductSuggestionRepository
+	productRecommendationRepository repository.ProductRecommendationRepository
 }
 
 func NewProductUsecase(c ProductUsecaseConfig) ProductUsecase {
-	return &productUsecaseImpl{
+	productUsecase := &productUsecaseImpl{
 		productRepository:  c.ProductRepository,
 		categoryRepository: c.CategoryRepository,
 		userRepository:     c.UserRepository,
 		merchantRepository: c.MerchantRepository,
 		mediaUsecase:       c.MediaUsecase,
+
+		productSuggestionRepository:     c.ProductSuggestionRepository,
+		productRecommendationRepository: c.ProductRecommendationRepository,
+	}
+
+	if c.Cron != nil {
+		_, err := c.Cron.AddJob("0 2 * * *", productUsecase.CronRefreshProductSimilarities)
+		if err != nil {
+			log.Error().Msg("error scheduling product similarity refresh")
+		} else {
+			log.Info().Msg("product similarity refresh scheduled")
+		}
+
+		_, err = c.Cron.AddJob("30 2 * * *", productUsecase.CronRefreshRelatedProducts)
+		if err != nil {
+			log.Error().Msg("error scheduling related products refresh")
+		} else {
+			log.Info().Msg("related products refresh scheduled")
+		}
 	}
+
+	return productUsecase
 }
 
 func (u *productUsecaseImpl) GetProductList(req dto.ProductListReqParamDTO) (*dto.ProductListResDTO, error) {
@@ -88,16 +124,61 @@ func (u *productUsecaseImpl) GetProductList(req dto.ProductListReqParamDTO) (*dt
 		}
 	}
 
+	if req.WithHighlight && req.Search != "" {
+		productIdList := make([]uint, len(products))
+		for i, product := range products {
+			productIdList[i] = product.ID
+		}
+
+		highlights, err := u.productRepository.GetProductSearchHighlights(req.Search, productIdList)
+		if err != nil {
+			return nil, err
+		}
+		for i := range productsDTO {
+			productsDTO[i].Highlight = highlights[productsDTO[i].ID]
+		}
+	}
+
+	var facets *dto.ProductSearchFacetsDTO
+	if req.WithFacets {
+		facets, err = u.productRepository.GetProductListFacets(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	didYouMean := ""
+	if totalProducts == 0 && req.Search != "" {
+		didYouMean, err = u.productSuggestionRepository.GetDidYouMean(req.Search)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &dto.ProductListResDTO{
 		PaginationResponse: dto.PaginationResponse{
 			TotalData:   totalProducts,
 			TotalPage:   (totalProducts + int64(req.Pagination.Limit) - 1) / int64(req.Pagination.Limit),
 			CurrentPage: req.Pagination.Page,
 		},
-		Products: productsDTO,
+		Products:   productsDTO,
+		Facets:     facets,
+		DidYouMean: didYouMean,
 	}, nil
 }
 
+func (u *productUsecaseImpl) GetProductSuggestions(req dto.ProductSuggestionReqDTO) (*dto.ProductSuggestionResDTO, error) {
+	if strings.TrimSpace(req.Query) == "" {
+		return &dto.ProductSuggestionResDTO{
+			Products:   make([]dto.ProductTitleSuggestionDTO, 0),
+			Categories: make([]dto.CategorySuggestionDTO, 0),
+			Merchants:  make([]dto.MerchantSuggestionDTO, 0),
+		}, nil
+	}
+
+	return u.productSuggestionRepository.GetSuggestions(req.Query, req.Limit)
+}
+
 func (u *productUsecaseImpl) GetMerchantProductList(username string, req dto.ProductListReqParamDTO) (*dto.ProductSellerListResDTO, error) {
 	merchant, err := u.merchantRepository.GetByUsername(username)
 	if err != nil {
@@ -142,49 +223,203 @@ func (u *productUsecaseImpl) GetMerchantProductList(username string, req dto.Pro
 	}, nil
 }
 
-func (u *productUsecaseImpl) GetRecommendationProductList(req dto.PaginationRequest) (*dto.ProductListResDTO, error) {
+func (u *productUsecaseImpl) GetRecommendationProductList(userJwt *dto.AccessTokenPayload, req dto.PaginationRequest) (*dto.ProductListResDTO, error) {
+	if userJwt != nil {
+		// a broken personalized list shouldn't hide the recommendations
+		res, err := u.getPersonalizedRecommendationProductList(userJwt.Username, req)
+		if err != nil {
+			log.Error().Msgf("GetRecommendationProductList personalized Error: %v", err)
+		} else if res != nil {
+			return res, nil
+		}
+	}
+
 	products, totalProducts, err := u.productRepository.GetRecommendationProductList(req)
 	if err != nil {
 		return nil, err
 	}
 	totalPages := (totalProducts + int64(req.Limit) - 1) / int64(req.Limit)
-	if int64(req.Page) > totalPages {
-		return &dto.ProductListResDTO{
-			Products: []dto.ProductResDTO{},
-		}, nil
-	}
 
-	productsDTO := make([]dto.ProductResDTO, len(products))
-	for i, product := range products {
-		productsDTO[i] = dto.ProductResDTO{
-			ID:               product.ID,
-			Title:            product.Title,
-			Slug:             product.Slug,
-			MinRealPrice:     product.MinRealPrice,
-			MaxRealPrice:     product.MaxRealPrice,
-			MinDiscountPrice: product.MinDiscountedPrice,
-			MaxDiscountPrice: product.MaxDiscountedPrice,
-			NumOfSale:        product.ProductAnalytic.NumOfSale,
-			AvgRating:        product.ProductAnalytic.AvgRating,
-			ThumbnailImg:     "http://dummyimage.com/173x122.png/ff4444/ffffff",
-			SellerCity:       product.Merchant.City.Name,
+	productsDTO := make([]dto.ProductResDTO, 0, len(products))
+	if int64(req.Page) <= totalPages {
+		for _, product := range products {
+			productsDTO = append(productsDTO, buildProductResDTO(product))
 		}
+	}
 
-		if len(product.ProductImages) > 0 {
-			productsDTO[i].ThumbnailImg = product.ProductImages[0].ImageUrl
+	return &dto.ProductListResDTO{
+		PaginationResponse: dto.PaginationResponse{
+			TotalData:   totalProducts,
+			TotalPage:   totalPages,
+			CurrentPage: req.Page,
+		},
+		Products: productsDTO,
+	}, nil
+}
+
+// getPersonalizedRecommendationProductList ranks the user's recommendations
+// first and pads them with the global list. It returns nil when the user has no
+// recommendations yet so the caller can fall back to the global list.
+func (u *productUsecaseImpl) getPersonalizedRecommendationProductList(username string, req dto.PaginationRequest) (*dto.ProductListResDTO, error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err := u.productRecommendationRepository.GetUserRecommendationScores(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(scores) == 0 {
+		return nil, nil
+	}
+
+	globalProductIdList, err := u.productRepository.GetRecommendationProductIds(dto.PRODUCT_RECOMMENDATION_LIMIT)
+	if err != nil {
+		return nil, err
+	}
+
+	rankedProductIdList := make([]uint, 0, len(scores)+len(globalProductIdList))
+	isRanked := make(map[uint]bool, len(scores))
+	for _, score := range scores {
+		rankedProductIdList = append(rankedProductIdList, score.ProductId)
+		isRanked[score.ProductId] = true
+	}
+	for _, productId := range globalProductIdList {
+		if !isRanked[productId] {
+			rankedProductIdList = append(rankedProductIdList, productId)
 		}
 	}
+	if len(rankedProductIdList) > dto.PRODUCT_RECOMMENDATION_LIMIT {
+		rankedProductIdList = rankedProductIdList[:dto.PRODUCT_RECOMMENDATION_LIMIT]
+	}
 
-	return &dto.ProductListResDTO{
+	totalProducts := int64(len(rankedProductIdList))
+	totalPages := (totalProducts + int64(req.Limit) - 1) / int64(req.Limit)
+	res := &dto.ProductListResDTO{
 		PaginationResponse: dto.PaginationResponse{
 			TotalData:   totalProducts,
 			TotalPage:   totalPages,
 			CurrentPage: req.Page,
 		},
+		Products: []dto.ProductResDTO{},
+	}
+
+	pageOffset := req.Limit * (req.Page - 1)
+	if int64(req.Page) > totalPages || pageOffset < 0 {
+		return res, nil
+	}
+
+	pageEnd := pageOffset + req.Limit
+	if pageEnd > len(rankedProductIdList) {
+		pageEnd = len(rankedProductIdList)
+	}
+	productIdList := rankedProductIdList[pageOffset:pageEnd]
+
+	products, err := u.productRepository.GetProductListByIds(productIdList)
+	if err != nil {
+		return nil, err
+	}
+	productById := make(map[uint]entity.Product, len(products))
+	for _, product := range products {
+		productById[product.ID] = product
+	}
+
+	productsDTO := make([]dto.ProductResDTO, 0, len(productIdList))
+	for _, productId := range productIdList {
+		product, ok := productById[productId]
+		if !ok {
+			continue
+		}
+		productsDTO = append(productsDTO, buildProductResDTO(product))
+	}
+	res.Products = productsDTO
+
+	return res, nil
+}
+
+func (u *productUsecaseImpl) CronRefreshProductSimilarities() {
+	err := u.productRecommendationRepository.RefreshProductSimilarities()
+	if err != nil {
+		log.Error().Msgf("CronRefreshProductSimilarities Error: %v", err)
+		return
+	}
+
+	log.Info().Msg("product similarities refreshed")
+}
+
+func (u *productUsecaseImpl) GetRelatedProducts(slug string, relationType string, req dto.RelatedProductReqDTO) (*dto.RelatedProductListResDTO, error) {
+	product, err := u.productRepository.GetProductBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	productIdList, err := u.productRecommendationRepository.GetRelatedProductIds(product.ID, relationType)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := u.productRepository.GetProductListByIds(productIdList)
+	if err != nil {
+		return nil, err
+	}
+	productById := make(map[uint]entity.Product, len(products))
+	for _, relatedProduct := range products {
+		productById[relatedProduct.ID] = relatedProduct
+	}
+
+	// related products that were archived or deleted since the last refresh are skipped
+	productsDTO := make([]dto.ProductResDTO, 0, req.Limit)
+	for _, productId := range productIdList {
+		relatedProduct, ok := productById[productId]
+		if !ok {
+			continue
+		}
+		productsDTO = append(productsDTO, buildProductResDTO(relatedProduct))
+		if len(productsDTO) == req.Limit {
+			break
+		}
+	}
+
+	return &dto.RelatedProductListResDTO{
 		Products: productsDTO,
 	}, nil
 }
 
+func (u *productUsecaseImpl) CronRefreshRelatedProducts() {
+	err := u.productRecommendationRepository.RefreshBoughtTogetherProducts()
+	if err != nil {
+		log.Error().Msgf("CronRefreshRelatedProducts bought together Error: %v", err)
+	}
+
+	err = u.productRecommendationRepository.RefreshSimilarProducts()
+	if err != nil {
+		log.Error().Msgf("CronRefreshRelatedProducts similar Error: %v", err)
+	}
+}
+
+func buildProductResDTO(product entity.Product) dto.ProductResDTO {
+	productDTO := dto.ProductResDTO{
+		ID:               product.ID,
+		Title:            product.Title,
+		Slug:             product.Slug,
+		MinRealPrice:     product.MinRealPrice,
+		MaxRealPrice:     product.MaxRealPrice,
+		MinDiscountPrice: product.MinDiscountedPrice,
+		MaxDiscountPrice: product.MaxDiscountedPrice,
+		NumOfSale:        product.ProductAnalytic.NumOfSale,
+		AvgRating:        product.ProductAnalytic.AvgRating,
+		ThumbnailImg:     "http://dummyimage.com/173x122.png/ff4444/ffffff",
+		SellerCity:       product.Merchant.City.Name,
+	}
+
+	if len(product.ProductImages) > 0 {
+		productDTO.ThumbnailImg = product.ProductImages[0].ImageUrl
+	}
+
+	return productDTO
+}
+
 func (u *productUsecaseImpl) GetAdminProductDetailByProductID(userJwt *dto.AccessTokenPayload, productId uint) (*dto.ProductAdminDetailResDTO, error) {
 	product, err := u.productRepository.GetProductByProductId(productId)
 	if err != nil {
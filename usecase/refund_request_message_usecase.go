@@ -25,6 +25,7 @@ type RefundRequestMessageUsecaseConfig struct {
 	RefundRequestRepository        repository.RefundRequestRepository
 	UserRepository                 repository.UserRepository
 	GcsUploader                    util.GCSUploader
+	EventStreamUsecase             EventStreamUsecase
 }
 
 type refundRequestMessageUsecaseImpl struct {
@@ -32,6 +33,7 @@ type refundRequestMessageUsecaseImpl struct {
 	refundRequestRepository        repository.RefundRequestRepository
 	userRepository                 repository.UserRepository
 	gcsUploader                    util.GCSUploader
+	eventStreamUsecase             EventStreamUsecase
 }
 
 func NewRefundRequestMessageUsecase(c RefundRequestMessageUsecaseConfig) RefundRequestMessageUsecase {
@@ -40,6 +42,7 @@ func NewRefundRequestMessageUsecase(c RefundRequestMessageUsecaseConfig) RefundR
 		refundRequestRepository:        c.RefundRequestRepository,
 		userRepository:                 c.UserRepository,
 		gcsUploader:                    c.GcsUploader,
+		eventStreamUsecase:             c.EventStreamUsecase,
 	}
 }
 
@@ -62,7 +65,7 @@ func (u *refundRequestMessageUsecaseImpl) MerchantAddMessage(username string, re
 		return nil, domain.ErrGetRefundRequestNotFound
 	}
 
-	return u.addRefundReqMessage(refundId, user.ID, dto.REFUND_REQ_MSG_ROLE_MERCHANT_ID, message)
+	return u.addRefundReqMessage(*refundReq, user.ID, dto.REFUND_REQ_MSG_ROLE_MERCHANT_ID, message)
 }
 
 func (u *refundRequestMessageUsecaseImpl) BuyerAddMessage(username string, refundId uint, message dto.RefundRequestMsgFormReqDTO) (*dto.RefundRequestMsgResDTO, error) {
@@ -84,7 +87,7 @@ func (u *refundRequestMessageUsecaseImpl) BuyerAddMessage(username string, refun
 		return nil, domain.ErrGetRefundRequestNotFound
 	}
 
-	return u.addRefundReqMessage(refundId, user.ID, dto.REFUND_REQ_MSG_ROLE_BUYER_ID, message)
+	return u.addRefundReqMessage(*refundReq, user.ID, dto.REFUND_REQ_MSG_ROLE_BUYER_ID, message)
 }
 
 func (u *refundRequestMessageUsecaseImpl) AdminAddMessage(refundId uint, message dto.RefundRequestMsgFormReqDTO) (*dto.RefundRequestMsgResDTO, error) {
@@ -97,10 +100,11 @@ func (u *refundRequestMessageUsecaseImpl) AdminAddMessage(refundId uint, message
 		return nil, domain.ErrRefundRequestClosed
 	}
 
-	return u.addRefundReqMessage(refundId, 0, dto.REFUND_REQ_MSG_ROLE_ADMIN_ID, message)
+	return u.addRefundReqMessage(*refundReq, 0, dto.REFUND_REQ_MSG_ROLE_ADMIN_ID, message)
 }
 
-func (u *refundRequestMessageUsecaseImpl) addRefundReqMessage(refundId uint, userId uint, roleId int, message dto.RefundRequestMsgFormReqDTO) (*dto.RefundRequestMsgResDTO, error) {
+func (u *refundRequestMessageUsecaseImpl) addRefundReqMessage(refundReq entity.RefundRequest, userId uint, roleId int, message dto.RefundRequestMsgFormReqDTO) (*dto.RefundRequestMsgResDTO, error) {
+	refundId := refundReq.ID
 	newMsg := entity.RefundReqMessage{
 		RefundRequestId:        refundId,
 		RefundReqMessageRoleId: uint(roleId),
@@ -130,6 +134,11 @@ func (u *refundRequestMessageUsecaseImpl) addRefundReqMessage(refundId uint, use
 		CreatedAt: createdMsg.CreatedAt,
 	}
 
+	eventMsg := res
+	eventMsg.SenderName = u.getRefundReqMessageSenderName(refundReq, uint(roleId))
+	eventMsg.Role.ID = uint(roleId)
+	u.eventStreamUsecase.PublishRefundMessage(refundReq, eventMsg)
+
 	return &res, nil
 }
 
@@ -173,14 +182,7 @@ func (u *refundRequestMessageUsecaseImpl) getRefundReqMessageList(refundRequest
 	}
 
 	for _, msg := range msgList {
-		name := ""
-		if msg.RefundReqMessageRoleId == dto.REFUND_REQ_MSG_ROLE_ADMIN_ID {
-			name = "blanche_admin"
-		} else if msg.RefundReqMessageRoleId == dto.REFUND_REQ_MSG_ROLE_MERCHANT_ID {
-			name = refundRequest.Transaction.MerchantDomain
-		} else if msg.RefundReqMessageRoleId == dto.REFUND_REQ_MSG_ROLE_BUYER_ID {
-			name = refundRequest.Transaction.User.Username
-		}
+		name := u.getRefundReqMessageSenderName(refundRequest, msg.RefundReqMessageRoleId)
 
 		res.Messages = append(res.Messages, dto.RefundRequestMsgResDTO{
 			ID:         msg.ID,
@@ -224,3 +226,16 @@ func (u *refundRequestMessageUsecaseImpl) GetAdminListMessageByRefundRequestId(r
 
 	return u.getRefundReqMessageList(*refundRequest)
 }
+
+func (u *refundRequestMessageUsecaseImpl) getRefundReqMessageSenderName(refundRequest entity.RefundRequest, roleId uint) string {
+	switch roleId {
+	case dto.REFUND_REQ_MSG_ROLE_ADMIN_ID:
+		return "blanche_admin"
+	case dto.REFUND_REQ_MSG_ROLE_MERCHANT_ID:
+		return refundRequest.Transaction.MerchantDomain
+	case dto.REFUND_REQ_MSG_ROLE_BUYER_ID:
+		return refundRequest.Transaction.User.Username
+	}
+
+	return ""
+}
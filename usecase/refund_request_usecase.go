@@ -40,6 +40,8 @@ type RefundRequestUsecaseConfig struct {
 	GCSUploader             util.GCSUploader
 	MerchantRepository      repository.MerchantRepository
 	WalletRepository        repository.WalletRepository
+	NotificationUsecase     NotificationUsecase
+	EventStreamUsecase      EventStreamUsecase
 	Cron                    *cronjob.CronJob
 }
 
@@ -50,6 +52,8 @@ type refundRequestUsecaseImpl struct {
 	gCSUploader             util.GCSUploader
 	merchantRepository      repository.MerchantRepository
 	walletRepository        repository.WalletRepository
+	notificationUsecase     NotificationUsecase
+	eventStreamUsecase      EventStreamUsecase
 	cron                    *cronjob.CronJob
 }
 
@@ -61,6 +65,8 @@ func NewRefundRequestUsecase(c RefundRequestUsecaseConfig) RefundRequestUsecase
 		gCSUploader:             c.GCSUploader,
 		merchantRepository:      c.MerchantRepository,
 		walletRepository:        c.WalletRepository,
+		notificationUsecase:     c.NotificationUsecase,
+		eventStreamUsecase:      c.EventStreamUsecase,
 		cron:                    c.Cron,
 	}
 
@@ -118,6 +124,9 @@ func (u *refundRequestUsecaseImpl) RequestRefundProcess(username string, req dto
 		return nil, err
 	}
 
+	u.notificationUsecase.NotifyTransactions([]entity.Transaction{*transaction}, dto.NOTIFICATION_TYPE_MERCHANT_REFUND_REQUESTED)
+	u.eventStreamUsecase.PublishTransactionStatus([]entity.Transaction{*transaction}, dto.TransactionStatusRequestRefund)
+
 	return &dto.RefundRequestFormResDTO{
 		ID:            createdRefundRequest.ID,
 		TransactionId: createdRefundRequest.TransactionID,
@@ -296,13 +305,16 @@ func (u *refundRequestUsecaseImpl) UserCancelRefundProcess(username string, refu
 	if err != nil {
 		return nil, domain.ErrUnmarshalJSONPaymentDetails
 	}
-	amount = trxPaymentDetails.Subtotal + trxPaymentDetails.DeliveryFee - trxPaymentDetails.MerchantVoucherNominal
+	amount = trxPaymentDetails.PaidAmount()
 
-	refReqStatusRes, err := u.refundRequestRepository.UserCancelRefundRequest(refundId, refundRequest.Transaction, amount, trxPaymentDetails.MarketplaceVoucherNominal)
+	refReqStatusRes, err := u.refundRequestRepository.UserCancelRefundRequest(refundId, refundRequest.Transaction, amount, trxPaymentDetails.MarketplaceSubsidy())
 	if err != nil {
 		return nil, err
 	}
 
+	u.notificationUsecase.NotifyTransactions([]entity.Transaction{refundRequest.Transaction}, dto.NOTIFICATION_TYPE_MERCHANT_REFUND_CANCELED)
+	u.eventStreamUsecase.PublishTransactionStatus([]entity.Transaction{refundRequest.Transaction}, dto.TransactionStatusCompleted)
+
 	return u.buildRefundRequestResDTO(*refundRequest, *refReqStatusRes), nil
 }
 
@@ -326,13 +338,16 @@ func (u *refundRequestUsecaseImpl) UserAcceptRefundProcess(username string, refu
 	if err != nil {
 		return nil, domain.ErrUnmarshalJSONPaymentDetails
 	}
-	amount = trxPaymentDetails.Subtotal + trxPaymentDetails.DeliveryFee - trxPaymentDetails.MerchantVoucherNominal
+	amount = trxPaymentDetails.PaidAmount()
 
-	refReqStatusRes, err := u.refundRequestRepository.UserAcceptRefundRequest(refundId, refundRequest.Transaction, amount, trxPaymentDetails.MarketplaceVoucherNominal)
+	refReqStatusRes, err := u.refundRequestRepository.UserAcceptRefundRequest(refundId, refundRequest.Transaction, amount, trxPaymentDetails.MarketplaceSubsidy())
 	if err != nil {
 		return nil, err
 	}
 
+	u.notificationUsecase.NotifyTransactions([]entity.Transaction{refundRequest.Transaction}, dto.NOTIFICATION_TYPE_MERCHANT_REFUND_BUYER_CLOSE)
+	u.eventStreamUsecase.PublishTransactionStatus([]entity.Transaction{refundRequest.Transaction}, dto.TransactionStatusCompleted)
+
 	return u.buildRefundRequestResDTO(*refundRequest, *refReqStatusRes), nil
 }
 
@@ -399,6 +414,8 @@ func (u *refundRequestUsecaseImpl) MerchantRejectRefundProsess(username string,
 		return nil, err
 	}
 
+	u.notificationUsecase.NotifyTransactions([]entity.Transaction{refundReq.Transaction}, dto.NOTIFICATION_TYPE_REFUND_REJECTED_BY_SELLER)
+
 	return u.buildRefundRequestResDTO(*refundReq, *refReqStatusRes), nil
 }
 
@@ -413,6 +430,8 @@ func (u *refundRequestUsecaseImpl) MerchantAcceptRefundProsess(username string,
 		return nil, err
 	}
 
+	u.notificationUsecase.NotifyTransactions([]entity.Transaction{refundReq.Transaction}, dto.NOTIFICATION_TYPE_REFUND_ACCEPTED_BY_SELLER)
+
 	return u.buildRefundRequestResDTO(*refundReq, *refReqStatusRes), nil
 }
 
@@ -454,13 +473,17 @@ func (u *refundRequestUsecaseImpl) AdminAcceptRefundProcess(refundId uint) (*dto
 	if err != nil {
 		return nil, domain.ErrUnmarshalJSONPaymentDetails
 	}
-	amount = trxPaymentDetails.Subtotal + trxPaymentDetails.DeliveryFee - trxPaymentDetails.MerchantVoucherNominal
+	amount = trxPaymentDetails.PaidAmount()
 
 	refReqStatusRes, err := u.refundRequestRepository.AdminAcceptRefundRequest(refundId, refundReq.Transaction, amount, trxCartItems)
 	if err != nil {
 		return nil, err
 	}
 
+	u.notificationUsecase.NotifyTransactions([]entity.Transaction{refundReq.Transaction}, dto.NOTIFICATION_TYPE_REFUND_ACCEPTED_BY_ADMIN)
+	u.notificationUsecase.NotifyTransactions([]entity.Transaction{refundReq.Transaction}, dto.NOTIFICATION_TYPE_MERCHANT_REFUND_ACCEPTED)
+	u.eventStreamUsecase.PublishTransactionStatus([]entity.Transaction{refundReq.Transaction}, dto.TransactionStatusRefunded)
+
 	return u.buildRefundRequestResDTO(*refundReq, *refReqStatusRes), nil
 }
 
@@ -481,22 +504,25 @@ func (u *refundRequestUsecaseImpl) AdminRejectRefundProcess(refundId uint) (*dto
 	if err != nil {
 		return nil, domain.ErrUnmarshalJSONPaymentDetails
 	}
-	amount = trxPaymentDetails.Subtotal + trxPaymentDetails.DeliveryFee - trxPaymentDetails.MerchantVoucherNominal
+	amount = trxPaymentDetails.PaidAmount()
 
-	if len(refundReq.RefundRequestStatuses) >= 3 {
-		refReqStatusRes, err := u.refundRequestRepository.AdminRejectRefundRequestClosed(refundId, refundReq.Transaction, amount, trxPaymentDetails.MarketplaceVoucherNominal)
-		if err != nil {
-			return nil, err
-		}
-
-		return u.buildRefundRequestResDTO(*refundReq, *refReqStatusRes), nil
+	var refReqStatusRes *entity.RefundRequestStatus
+	isClosed := len(refundReq.RefundRequestStatuses) >= 3
+	if isClosed {
+		refReqStatusRes, err = u.refundRequestRepository.AdminRejectRefundRequestClosed(refundId, refundReq.Transaction, amount, trxPaymentDetails.MarketplaceSubsidy())
+	} else {
+		refReqStatusRes, err = u.refundRequestRepository.AdminRejectRefundRequest(refundId)
 	}
-
-	refReqStatusRes, err := u.refundRequestRepository.AdminRejectRefundRequest(refundId)
 	if err != nil {
 		return nil, err
 	}
 
+	u.notificationUsecase.NotifyTransactions([]entity.Transaction{refundReq.Transaction}, dto.NOTIFICATION_TYPE_REFUND_REJECTED_BY_ADMIN)
+	u.notificationUsecase.NotifyTransactions([]entity.Transaction{refundReq.Transaction}, dto.NOTIFICATION_TYPE_MERCHANT_REFUND_REJECTED)
+	if isClosed {
+		u.eventStreamUsecase.PublishTransactionStatus([]entity.Transaction{refundReq.Transaction}, dto.TransactionStatusCompleted)
+	}
+
 	return u.buildRefundRequestResDTO(*refundReq, *refReqStatusRes), nil
 }
 
@@ -1,61 +1,251 @@
 package usecase
 
 import (
-	"fmt"
-	"strconv"
+	"time"
 
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cronjob"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
-	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/httperror"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"github.com/rs/zerolog/log"
 )
 
 type SealabspayUsecase interface {
 	HandlePaymentResponse(input dto.SealabspayReqDTO) (*dto.SealabspayReqDTO, error)
+
+	GetCallbackList(req dto.SealabspayCallbackListReqParamDTO) (*dto.SealabspayCallbackListResDTO, error)
+	ReplayCallback(callbackId uint) (*dto.SealabspayCallbackDTO, error)
+
+	CronRetryCallbacks()
 }
 
 type SealabspayUsecaseConfig struct {
-	PaymentRecordUsecase PaymentRecordUsecase
+	PaymentRecordUsecase         PaymentRecordUsecase
+	PaymentProviderRegistry      repository.PaymentProviderRegistry
+	SealabspayCallbackRepository repository.SealabspayCallbackRepository
+	Cron                         *cronjob.CronJob
 }
 
 type sealabspayUsecaseImpl struct {
-	paymentRecordUsecase PaymentRecordUsecase
+	paymentRecordUsecase         PaymentRecordUsecase
+	paymentProviderRegistry      repository.PaymentProviderRegistry
+	sealabspayCallbackRepository repository.SealabspayCallbackRepository
 }
 
 func NewSealabspayUsecase(c SealabspayUsecaseConfig) SealabspayUsecase {
-	return &sealabspayUsecaseImpl{
-		paymentRecordUsecase: c.PaymentRecordUsecase,
+	sealabspayUsecase := &sealabspayUsecaseImpl{
+		paymentRecordUsecase:         c.PaymentRecordUsecase,
+		paymentProviderRegistry:      c.PaymentProviderRegistry,
+		sealabspayCallbackRepository: c.SealabspayCallbackRepository,
 	}
+
+	if c.Cron != nil {
+		_, err := c.Cron.AddJob("* * * * *", sealabspayUsecase.CronRetryCallbacks)
+		if err != nil {
+			log.Error().Msg("error scheduling sealabspay callback retry")
+		} else {
+			log.Info().Msg("sealabspay callback retry scheduled")
+		}
+	}
+
+	return sealabspayUsecase
 }
 
 func (u *sealabspayUsecaseImpl) HandlePaymentResponse(input dto.SealabspayReqDTO) (*dto.SealabspayReqDTO, error) {
-	err := util.ValidateResSlpSignature(input)
+	// reject forged callbacks before they reach the inbox
+	_, err := u.verifyCallback(input)
+	if err != nil {
+		return nil, err
+	}
+
+	callback, err := u.sealabspayCallbackRepository.Create(entity.SealabspayCallback{
+		TxnId:         input.TxnId,
+		Amount:        input.Amount,
+		MerchantCode:  input.MerchantCode,
+		Message:       input.Message,
+		Signature:     input.Signature,
+		Status:        input.Status,
+		ProcessStatus: dto.SLP_CALLBACK_STATUS_PENDING,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if callback.ProcessStatus != dto.SLP_CALLBACK_STATUS_PENDING || callback.Attempts > 0 {
+		return &input, nil
+	}
+
+	err = u.processCallback(callback)
+	if err != nil && !isRetryableCallbackErr(err) {
+		return nil, err
+	}
+
+	return &input, nil
+}
+
+func (u *sealabspayUsecaseImpl) GetCallbackList(req dto.SealabspayCallbackListReqParamDTO) (*dto.SealabspayCallbackListResDTO, error) {
+	callbacks, totalData, err := u.sealabspayCallbackRepository.GetList(req)
+	if err != nil {
+		return nil, err
+	}
+
+	callbacksDTO := make([]dto.SealabspayCallbackDTO, 0)
+	for _, callback := range callbacks {
+		callbacksDTO = append(callbacksDTO, buildSealabspayCallbackDTO(callback))
+	}
+
+	return &dto.SealabspayCallbackListResDTO{
+		Callbacks: callbacksDTO,
+		PaginationResponse: dto.PaginationResponse{
+			TotalData:   totalData,
+			TotalPage:   (totalData + int64(req.Limit) - 1) / int64(req.Limit),
+			CurrentPage: req.Page,
+		},
+	}, nil
+}
+
+func (u *sealabspayUsecaseImpl) ReplayCallback(callbackId uint) (*dto.SealabspayCallbackDTO, error) {
+	callback, err := u.sealabspayCallbackRepository.GetById(callbackId)
+	if err != nil {
+		return nil, err
+	}
+	if callback.ProcessStatus == dto.SLP_CALLBACK_STATUS_PROCESSED {
+		return nil, domain.ErrSealabspayCallbackAlreadyProcessed
+	}
+
+	isReset, err := u.sealabspayCallbackRepository.ResetForReplay(callback)
 	if err != nil {
 		return nil, err
 	}
+	if !isReset {
+		return nil, domain.ErrSealabspayCallbackInProgress
+	}
 
-	paymentId, err := strconv.ParseUint(input.TxnId, 10, 64)
+	err = u.processCallback(callback)
 	if err != nil {
-		return nil, domain.ErrSealabspayTxnIdNotValid
+		log.Error().Msgf("replay sealabspay callback %d failed: %v", callback.ID, err)
 	}
 
-	paymentAmount, err := strconv.ParseUint(input.Amount, 10, 64)
+	res := buildSealabspayCallbackDTO(*callback)
+	return &res, nil
+}
+
+func (u *sealabspayUsecaseImpl) CronRetryCallbacks() {
+	callbacks, err := u.sealabspayCallbackRepository.GetDueForRetry(dto.SLP_CALLBACK_RETRY_BATCH_SIZE)
 	if err != nil {
-		return nil, domain.ErrSealabspayAmountNotValid
+		log.Error().Msgf("CronRetryCallbacks Error: %v", err)
+		return
 	}
 
-	if input.Status == dto.SLP_FAILED_CODE {
-		err = u.paymentRecordUsecase.UpdatePaymentRecordStatus(fmt.Sprintf("SLP%d", paymentId), uint(paymentAmount), false)
+	for i := range callbacks {
+		err = u.processCallback(&callbacks[i])
 		if err != nil {
-			return nil, err
+			log.Error().Msgf("CronRetryCallbacks sealabspay callback %s attempt %d: %v", callbacks[i].TxnId, callbacks[i].Attempts, err)
 		}
 	}
+}
 
-	if input.Status == dto.SLP_SUCCESS_CODE {
-		err = u.paymentRecordUsecase.UpdatePaymentRecordStatus(fmt.Sprintf("SLP%d", paymentId), uint(paymentAmount), true)
-		if err != nil {
-			return nil, err
+// processCallback applies the callback to its payment record and stores the
+// outcome, scheduling another attempt with exponential backoff on transient errors.
+func (u *sealabspayUsecaseImpl) processCallback(callback *entity.SealabspayCallback) error {
+	isClaimed, err := u.sealabspayCallbackRepository.Claim(callback, dto.SLP_CALLBACK_PROCESS_LEASE)
+	if err != nil {
+		return err
+	}
+	if !isClaimed {
+		return nil
+	}
+
+	verified, processErr := u.verifyCallback(dto.SealabspayReqDTO{
+		Amount:       callback.Amount,
+		MerchantCode: callback.MerchantCode,
+		Message:      callback.Message,
+		Signature:    callback.Signature,
+		Status:       callback.Status,
+		TxnId:        callback.TxnId,
+	})
+	if processErr == nil {
+		processErr = u.paymentRecordUsecase.UpdatePaymentRecordStatus(verified.PaymentId, verified.Amount, verified.IsSuccess)
+	}
+
+	now := time.Now()
+	switch {
+	case processErr == nil:
+		callback.ProcessStatus = dto.SLP_CALLBACK_STATUS_PROCESSED
+		callback.ProcessedAt = &now
+		callback.NextAttemptAt = nil
+		callback.LastError = ""
+	case isRetryableCallbackErr(processErr) && callback.Attempts < dto.SLP_CALLBACK_MAX_ATTEMPTS:
+		nextAttemptAt := now.Add(callbackRetryDelay(callback.Attempts))
+		callback.NextAttemptAt = &nextAttemptAt
+		callback.LastError = processErr.Error()
+	default:
+		callback.ProcessStatus = dto.SLP_CALLBACK_STATUS_FAILED
+		callback.NextAttemptAt = nil
+		callback.LastError = processErr.Error()
+	}
+
+	_, err = u.sealabspayCallbackRepository.Update(*callback)
+	if err != nil {
+		log.Error().Msgf("error: store sealabspay callback %s result: %v", callback.TxnId, err)
+	}
+
+	return processErr
+}
+
+func (u *sealabspayUsecaseImpl) verifyCallback(input dto.SealabspayReqDTO) (*dto.PaymentCallbackResDTO, error) {
+	provider, err := u.paymentProviderRegistry.Get(dto.PAYMENT_METHOD_CODE_SLP)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.VerifyCallback(dto.PaymentCallbackReqDTO{
+		PaymentId:    input.TxnId,
+		Amount:       input.Amount,
+		MerchantCode: input.MerchantCode,
+		Status:       input.Status,
+		Message:      input.Message,
+		Signature:    input.Signature,
+	})
+}
+
+func callbackRetryDelay(attempts int) time.Duration {
+	delay := dto.SLP_CALLBACK_RETRY_BASE_DELAY
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= dto.SLP_CALLBACK_RETRY_MAX_DELAY {
+			return dto.SLP_CALLBACK_RETRY_MAX_DELAY
 		}
 	}
 
-	return &input, nil
+	return delay
+}
+
+// isRetryableCallbackErr reports whether the error may go away on a later
+// attempt. Client errors such as an expired payment or amount mismatch will not.
+func isRetryableCallbackErr(err error) bool {
+	appErr, isAppError := err.(httperror.AppError)
+	if !isAppError {
+		return true
+	}
+
+	return appErr.StatusCode >= 500
+}
+
+func buildSealabspayCallbackDTO(callback entity.SealabspayCallback) dto.SealabspayCallbackDTO {
+	return dto.SealabspayCallbackDTO{
+		ID:            callback.ID,
+		TxnId:         callback.TxnId,
+		Amount:        callback.Amount,
+		Status:        callback.Status,
+		Message:       callback.Message,
+		ProcessStatus: callback.ProcessStatus,
+		Attempts:      callback.Attempts,
+		LastError:     callback.LastError,
+		NextAttemptAt: callback.NextAttemptAt,
+		ProcessedAt:   callback.ProcessedAt,
+		CreatedAt:     callback.CreatedAt,
+	}
 }
@@ -32,6 +32,16 @@ func (u *transactionUsecaseImpl) UpdateMerchantTransactionStatus(username string
 		return nil, err
 	}
 
+	if notificationType, ok := dto.TransactionStatusNotificationTypes[req.Status]; ok {
+		u.notificationUsecase.NotifyTransactions([]entity.Transaction{*validatedTransaction}, notificationType)
+	}
+	if req.Status == dto.TransactionStatusOnDelivery || req.Status == dto.TransactionStatusDelivered {
+		u.eventStreamUsecase.PublishDeliveryStatus(*validatedTransaction, req.Status, updatedTrxDeliveryStatus.ReceiptNumber)
+	}
+	if req.Status != dto.TransactionStatusOnDelivery {
+		u.eventStreamUsecase.PublishTransactionStatus([]entity.Transaction{*validatedTransaction}, req.Status)
+	}
+
 	return &dto.UpdateMerchantTransactionStatusResDTO{
 		InvoiceCode: req.InvoiceCode,
 		TransactionStatus: dto.TransactionStatusResDTO{
@@ -4,6 +4,7 @@ import (
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/config"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cronjob"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
 	"github.com/rs/zerolog/log"
 )
@@ -18,17 +19,23 @@ type TransactionStatusUsecase interface {
 
 type transactionStatusUsecaseImpl struct {
 	transactionStatusRepo repository.TransactionStatusRepository
+	notificationUsecase   NotificationUsecase
+	eventStreamUsecase    EventStreamUsecase
 	cron                  *cronjob.CronJob
 }
 
 type TransactionStatusUsecaseConfig struct {
 	TransactionStatusRepo repository.TransactionStatusRepository
+	NotificationUsecase   NotificationUsecase
+	EventStreamUsecase    EventStreamUsecase
 	Cron                  *cronjob.CronJob
 }
 
 func NewTransactionStatusUsecase(c TransactionStatusUsecaseConfig) TransactionStatusUsecase {
 	transactionStatusUsecaseImpl := &transactionStatusUsecaseImpl{
 		transactionStatusRepo: c.TransactionStatusRepo,
+		notificationUsecase:   c.NotificationUsecase,
+		eventStreamUsecase:    c.EventStreamUsecase,
 		cron:                  c.Cron,
 	}
 
@@ -62,10 +69,13 @@ func (u *transactionStatusUsecaseImpl) CronUpdateTransactionWaitingStatusToCance
 	concurrentSize := cronConfig.TrxQueueSizeWaitingToCanceled / cronConfig.TrxBatchSizeWaitingToCanceled
 	for i := 0; i < concurrentSize; i++ {
 		go func(batchNum int) {
-			err := u.transactionStatusRepo.CronUpdateTransactionWaitingStatusToCanceled(batchNum)
+			transactions, err := u.transactionStatusRepo.CronUpdateTransactionWaitingStatusToCanceled(batchNum)
 			if err != nil {
 				log.Error().Msgf("CronUpdateTransactionWaitingStatusToCanceled Error: %v", err)
+				return
 			}
+
+			u.publishTransactionsCanceled(transactions)
 		}(i)
 	}
 }
@@ -75,10 +85,13 @@ func (u *transactionStatusUsecaseImpl) CronUpdateTransactionProcessedStatusToCan
 	concurrentSize := cronConfig.TrxQueueSizeWaitingToCanceled / cronConfig.TrxBatchSizeWaitingToCanceled
 	for i := 0; i < concurrentSize; i++ {
 		go func(batchNum int) {
-			err := u.transactionStatusRepo.CronUpdateTransactionProcessedStatusToCanceled(batchNum)
+			transactions, err := u.transactionStatusRepo.CronUpdateTransactionProcessedStatusToCanceled(batchNum)
 			if err != nil {
 				log.Error().Msgf("CronUpdateTransactionProcessedStatusToCanceled Error: %v", err)
+				return
 			}
+
+			u.publishTransactionsCanceled(transactions)
 		}(i)
 	}
 }
@@ -88,10 +101,33 @@ func (u *transactionStatusUsecaseImpl) CronUpdateTransactionDeliveredStatusToCom
 	concurrentSize := cronConfig.TrxQueueSizeWaitingToCanceled / cronConfig.TrxBatchSizeWaitingToCanceled
 	for i := 0; i < concurrentSize; i++ {
 		go func(batchNum int) {
-			err := u.transactionStatusRepo.CronUpdateTransactionDeliveredStatusToCompleted(batchNum)
+			transactions, err := u.transactionStatusRepo.CronUpdateTransactionDeliveredStatusToCompleted(batchNum)
 			if err != nil {
 				log.Error().Msgf("CronUpdateTransactionDeliveredStatusToCompleted Error: %v", err)
+				return
 			}
+
+			u.publishTransactionsCompleted(transactions)
 		}(i)
 	}
 }
+
+func (u *transactionStatusUsecaseImpl) publishTransactionsCanceled(transactions []entity.Transaction) {
+	if len(transactions) == 0 {
+		return
+	}
+
+	u.notificationUsecase.NotifyTransactions(transactions, dto.NOTIFICATION_TYPE_TRANSACTION_CANCELED)
+	u.notificationUsecase.NotifyTransactions(transactions, dto.NOTIFICATION_TYPE_MERCHANT_TRANSACTION_CANCELED)
+	u.eventStreamUsecase.PublishTransactionStatus(transactions, dto.TransactionStatusCanceled)
+}
+
+func (u *transactionStatusUsecaseImpl) publishTransactionsCompleted(transactions []entity.Transaction) {
+	if len(transactions) == 0 {
+		return
+	}
+
+	u.notificationUsecase.NotifyTransactions(transactions, dto.NOTIFICATION_TYPE_TRANSACTION_COMPLETED)
+	u.notificationUsecase.NotifyTransactions(transactions, dto.NOTIFICATION_TYPE_MERCHANT_TRANSACTION_COMPLETED)
+	u.eventStreamUsecase.PublishTransactionStatus(transactions, dto.TransactionStatusCompleted)
+}
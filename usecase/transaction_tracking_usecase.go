@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/cronjob"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"github.com/rs/zerolog/log"
+)
+
+type TransactionTrackingUsecase interface {
+	GetTransactionTrackingEvents(transactionID uint) ([]dto.TransactionTrackingEventResDTO, error)
+
+	CronSyncTransactionTrackings()
+}
+
+type TransactionTrackingUsecaseConfig struct {
+	TransactionTrackingRepository repository.TransactionTrackingRepository
+	WaybillRepository             repository.WaybillRepository
+	DeliveryRepository            repository.DeliveryRepository
+	NotificationUsecase           NotificationUsecase
+	EventStreamUsecase            EventStreamUsecase
+	Cron                          *cronjob.CronJob
+}
+
+type transactionTrackingUsecaseImpl struct {
+	transactionTrackingRepository repository.TransactionTrackingRepository
+	waybillRepository             repository.WaybillRepository
+	deliveryRepository            repository.DeliveryRepository
+	notificationUsecase           NotificationUsecase
+	eventStreamUsecase            EventStreamUsecase
+}
+
+func NewTransactionTrackingUsecase(c TransactionTrackingUsecaseConfig) TransactionTrackingUsecase {
+	transactionTrackingUsecaseImpl := &transactionTrackingUsecaseImpl{
+		transactionTrackingRepository: c.TransactionTrackingRepository,
+		waybillRepository:             c.WaybillRepository,
+		deliveryRepository:            c.DeliveryRepository,
+		notificationUsecase:           c.NotificationUsecase,
+		eventStreamUsecase:            c.EventStreamUsecase,
+	}
+
+	if c.Cron != nil {
+		_, err := c.Cron.AddJob("*/30 * * * *", transactionTrackingUsecaseImpl.CronSyncTransactionTrackings)
+		if err != nil {
+			log.Error().Msg("error scheduling transaction tracking sync")
+		} else {
+			log.Info().Msg("transaction tracking sync scheduled")
+		}
+	}
+
+	return transactionTrackingUsecaseImpl
+}
+
+func (u *transactionTrackingUsecaseImpl) GetTransactionTrackingEvents(transactionID uint) ([]dto.TransactionTrackingEventResDTO, error) {
+	events, err := u.transactionTrackingRepository.GetTrackingEventsByTransactionID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.TransactionTrackingEventResDTO, 0, len(events))
+	for _, event := range events {
+		res = append(res, dto.TransactionTrackingEventResDTO{
+			Code:        event.Code,
+			Description: event.Description,
+			Location:    event.Location,
+			OccurredAt:  event.OccurredAt,
+		})
+	}
+
+	return res, nil
+}
+
+// CronSyncTransactionTrackings pulls the courier manifest of every shipped transaction
+// and marks it delivered once the courier reports so.
+func (u *transactionTrackingUsecaseImpl) CronSyncTransactionTrackings() {
+	courierCodes, err := u.getCourierCodesByName()
+	if err != nil {
+		log.Error().Msgf("CronSyncTransactionTrackings Error: %v", err)
+		return
+	}
+
+	for offset := 0; ; offset += dto.TRACKING_SYNC_BATCH_SIZE {
+		transactions, err := u.transactionTrackingRepository.GetTrackableTransactions(dto.TRACKING_SYNC_BATCH_SIZE, offset)
+		if err != nil {
+			log.Error().Msgf("CronSyncTransactionTrackings Error: %v", err)
+			return
+		}
+
+		var deliveredCount int
+		for _, transaction := range transactions {
+			isDelivered, err := u.syncTransactionTracking(transaction, courierCodes)
+			if err != nil {
+				log.Error().Msgf("CronSyncTransactionTrackings %s Error: %v", transaction.InvoiceCode, err)
+				continue
+			}
+			if isDelivered {
+				deliveredCount++
+			}
+		}
+
+		if len(transactions) < dto.TRACKING_SYNC_BATCH_SIZE {
+			return
+		}
+		// delivered transactions drop out of the trackable set
+		offset -= deliveredCount
+	}
+}
+
+// getCourierCodesByName maps courier names to codes for transactions made
+// before the courier code was stored on the delivery option.
+func (u *transactionTrackingUsecaseImpl) getCourierCodesByName() (map[string]string, error) {
+	deliveryOptions, err := u.deliveryRepository.GetAllDeliveryOption()
+	if err != nil {
+		return nil, err
+	}
+
+	courierCodes := make(map[string]string)
+	for _, deliveryOption := range deliveryOptions {
+		courierCodes[strings.ToLower(deliveryOption.CourierName)] = deliveryOption.CourierCode
+	}
+	return courierCodes, nil
+}
+
+func (u *transactionTrackingUsecaseImpl) syncTransactionTracking(transaction entity.Transaction, courierCodes map[string]string) (bool, error) {
+	var deliveryOption entity.TransactionDeliveryOption
+	err := json.Unmarshal(transaction.DeliveryOption.Bytes, &deliveryOption)
+	if err != nil {
+		return false, err
+	}
+
+	courierCode := deliveryOption.CourierCode
+	if courierCode == "" {
+		courierCode = courierCodes[strings.ToLower(deliveryOption.CourierName)]
+	}
+	if courierCode == "" {
+		log.Warn().Msgf("CronSyncTransactionTrackings %s skipped, unknown courier %q", transaction.InvoiceCode, deliveryOption.CourierName)
+		return false, nil
+	}
+
+	waybill, err := u.waybillRepository.GetWaybill(dto.RajaOngkirWaybillReqDTO{
+		Waybill: *transaction.TransactionDeliveryStatus.ReceiptNumber,
+		Courier: courierCode,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var events []entity.TransactionTrackingEvent
+	var lastEventAt time.Time
+	for _, manifest := range waybill.Rajaongkir.Result.Manifest {
+		occurredAt, err := time.ParseInLocation(dto.TRACKING_MANIFEST_TIME_FORMAT, manifest.ManifestDate+" "+manifest.ManifestTime, time.Local)
+		if err != nil {
+			log.Error().Msgf("CronSyncTransactionTrackings %s invalid manifest time: %v", transaction.InvoiceCode, err)
+			continue
+		}
+		if occurredAt.After(lastEventAt) {
+			lastEventAt = occurredAt
+		}
+
+		code := manifest.ManifestCode
+		if code == "" {
+			code = dto.TRACKING_MANIFEST_CODE_DEFAULT
+		}
+		events = append(events, entity.TransactionTrackingEvent{
+			TransactionId: transaction.ID,
+			Code:          code,
+			Description:   manifest.ManifestDescription,
+			Location:      manifest.CityName,
+			OccurredAt:    occurredAt,
+		})
+	}
+
+	err = u.transactionTrackingRepository.CreateTrackingEvents(events)
+	if err != nil {
+		return false, err
+	}
+
+	if !waybill.Rajaongkir.Result.Delivered {
+		return false, nil
+	}
+
+	deliveredAt := time.Now()
+	if !lastEventAt.IsZero() && lastEventAt.Before(deliveredAt) {
+		deliveredAt = lastEventAt
+	}
+	isUpdated, err := u.transactionTrackingRepository.UpdateTrackedTransactionDelivered(transaction, deliveredAt)
+	if err != nil || !isUpdated {
+		return false, err
+	}
+
+	u.notificationUsecase.NotifyTransactions([]entity.Transaction{transaction}, dto.NOTIFICATION_TYPE_TRANSACTION_DELIVERED)
+	u.eventStreamUsecase.PublishDeliveryStatus(transaction, dto.TransactionStatusDelivered, transaction.TransactionDeliveryStatus.ReceiptNumber)
+	u.eventStreamUsecase.PublishTransactionStatus([]entity.Transaction{transaction}, dto.TransactionStatusDelivered)
+
+	return true, nil
+}
@@ -36,10 +36,13 @@ type transactionUsecaseImpl struct {
 	merchantRepository                  repository.MerchantRepository
 	transactionStatusUsecase            TransactionStatusUsecase
 	transactionDeliveryStatusUsecase    TransactionDeliveryStatusUsecase
+	transactionTrackingUsecase          TransactionTrackingUsecase
 	orderItemUsecase                    OrderItemUsecase
-	sealabspayRepository                repository.SealabspayRepository
-	walletRepository                    repository.WalletRepository
+	paymentProviderRegistry             repository.PaymentProviderRegistry
 	paymentMethodRepository             repository.PaymentMethodRepository
+	voucherRedemptionRepository         repository.VoucherRedemptionRepository
+	notificationUsecase                 NotificationUsecase
+	eventStreamUsecase                  EventStreamUsecase
 }
 
 type TransactionUsecaseConfig struct {
@@ -51,10 +54,13 @@ type TransactionUsecaseConfig struct {
 	MerchantRepository                  repository.MerchantRepository
 	TransactionStatusUsecase            TransactionStatusUsecase
 	TransactionDeliveryStatusUsecase    TransactionDeliveryStatusUsecase
+	TransactionTrackingUsecase          TransactionTrackingUsecase
 	OrderItemUsecase                    OrderItemUsecase
-	SealabspayRepository                repository.SealabspayRepository
-	WalletRepository                    repository.WalletRepository
+	PaymentProviderRegistry             repository.PaymentProviderRegistry
 	PaymentMethodRepository             repository.PaymentMethodRepository
+	VoucherRedemptionRepository         repository.VoucherRedemptionRepository
+	NotificationUsecase                 NotificationUsecase
+	EventStreamUsecase                  EventStreamUsecase
 }
 
 func NewTransactionUsecase(c TransactionUsecaseConfig) TransactionUsecase {
@@ -67,10 +73,13 @@ func NewTransactionUsecase(c TransactionUsecaseConfig) TransactionUsecase {
 		transactionStatusRepository:         c.TransactionStatusRepository,
 		transactionStatusUsecase:            c.TransactionStatusUsecase,
 		transactionDeliveryStatusUsecase:    c.TransactionDeliveryStatusUsecase,
+		transactionTrackingUsecase:          c.TransactionTrackingUsecase,
 		orderItemUsecase:                    c.OrderItemUsecase,
-		sealabspayRepository:                c.SealabspayRepository,
-		walletRepository:                    c.WalletRepository,
+		paymentProviderRegistry:             c.PaymentProviderRegistry,
 		paymentMethodRepository:             c.PaymentMethodRepository,
+		voucherRedemptionRepository:         c.VoucherRedemptionRepository,
+		notificationUsecase:                 c.NotificationUsecase,
+		eventStreamUsecase:                  c.EventStreamUsecase,
 	}
 }
 
@@ -213,11 +222,7 @@ func (u *transactionUsecaseImpl) GetTransactionList(username string, req dto.Tra
 		if err != nil {
 			return nil, domain.ErrUnmarshalJSONPaymentDetails
 		}
-		subtotal := paymentDetails.Subtotal
-		delivery_fee := paymentDetails.DeliveryFee
-		merchant_voucher_nominal := paymentDetails.MerchantVoucherNominal
-		marketplace_voucher_nominal := paymentDetails.MarketplaceVoucherNominal
-		transactionResDTOs[len(transactionResDTOs)-1].Total = subtotal + delivery_fee - merchant_voucher_nominal - marketplace_voucher_nominal
+		transactionResDTOs[len(transactionResDTOs)-1].Total = paymentDetails.PaidAmount()
 
 		merchant, err := u.merchantRepository.GetByDomain(transaction.MerchantDomain)
 		if err != nil {
@@ -347,6 +352,12 @@ func (u *transactionUsecaseImpl) GetSellerTransactionDetail(username string, inv
 		transactionResDTO.ShippingDetails.DeliveryOption.ReceiptNumber = *deliveryReceiptNumber
 	}
 
+	trackingEvents, err := u.transactionTrackingUsecase.GetTransactionTrackingEvents(transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	transactionResDTO.ShippingDetails.TrackingEvents = trackingEvents
+
 	return &transactionResDTO, nil
 }
 
@@ -377,12 +388,8 @@ func (u *transactionUsecaseImpl) GetTransactionDetail(username string, invoiceCo
 	if err != nil {
 		return nil, domain.ErrUnmarshalJSONPaymentDetails
 	}
-	subtotal := paymentDetails.Subtotal
-	delivery_fee := paymentDetails.DeliveryFee
-	merchant_voucher_nominal := paymentDetails.MerchantVoucherNominal
-	marketplace_voucher_nominal := paymentDetails.MarketplaceVoucherNominal
 	transactionResDTO.PaymentDetails.PaymentDetails = paymentDetails
-	transactionResDTO.PaymentDetails.PaymentDetails.Total = subtotal + delivery_fee - merchant_voucher_nominal - marketplace_voucher_nominal
+	transactionResDTO.PaymentDetails.PaymentDetails.Total = paymentDetails.PaidAmount()
 
 	var addressDetails entity.TransactionAddress
 	err = json.Unmarshal([]byte(transaction.Address.Bytes), &addressDetails)
@@ -432,6 +439,12 @@ func (u *transactionUsecaseImpl) GetTransactionDetail(username string, invoiceCo
 		transactionResDTO.ShippingDetails.DeliveryOption.ReceiptNumber = *deliveryReceiptNumber
 	}
 
+	trackingEvents, err := u.transactionTrackingUsecase.GetTransactionTrackingEvents(transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	transactionResDTO.ShippingDetails.TrackingEvents = trackingEvents
+
 	return &transactionResDTO, nil
 }
 
@@ -441,12 +454,6 @@ func (u *transactionUsecaseImpl) MakeTransaction(username string, req dto.MakeTr
 		return nil, err
 	}
 
-	//get paymentId from paymentCode
-	redirectUrl, paymentId, payRecId, paymentMethod, err := u.getPaymentDetails(req.PaymentMethodCode, req.PaymentAccountNumber, req.PaymentTotal, req.OrderCode)
-	if err != nil {
-		return nil, err
-	}
-
 	//check order summary, is the promo is available and the total price is correct
 	orderValidated, err := u.validateOrderRequest(username, req)
 	if err != nil {
@@ -457,6 +464,19 @@ func (u *transactionUsecaseImpl) MakeTransaction(username string, req dto.MakeTr
 		return nil, domain.ErrPaymentTotalNotMatch
 	}
 
+	//hold the vouchers before charging so a sold out voucher never leaves a charge behind
+	cancelVoucherReservations, err := u.reserveVoucherQuotas(*orderValidated)
+	if err != nil {
+		return nil, err
+	}
+
+	//get paymentId from paymentCode
+	redirectUrl, paymentId, payRecId, paymentMethod, err := u.getPaymentDetails(req.PaymentMethodCode, req.PaymentAccountNumber, req.PaymentTotal, req.OrderCode)
+	if err != nil {
+		cancelVoucherReservations()
+		return nil, err
+	}
+
 	//make payment record and the transaction record
 	paymentRec := entity.PaymentRecord{
 		ID:              payRecId,
@@ -467,11 +487,13 @@ func (u *transactionUsecaseImpl) MakeTransaction(username string, req dto.MakeTr
 	}
 	transactionRecords, err := u.makeTransactionsEntity(user.ID, paymentRec, *orderValidated, *paymentMethod, req.PaymentAccountNumber)
 	if err != nil {
+		cancelVoucherReservations()
 		return nil, err
 	}
 
 	err = u.transactionRepository.MakeTransaction(transactionRecords, *orderValidated, paymentId)
 	if err != nil {
+		cancelVoucherReservations()
 		log.Error().Msgf("error: in usecase make transaction %v", err)
 		return nil, err
 	}
@@ -496,6 +518,10 @@ func (u *transactionUsecaseImpl) HandleTransactionSlpRes(trxRecords []entity.Tra
 		if err != nil {
 			return err
 		}
+
+		u.notificationUsecase.NotifyTransactions(transactions, dto.NOTIFICATION_TYPE_TRANSACTION_PAID)
+		u.notificationUsecase.NotifyTransactions(transactions, dto.NOTIFICATION_TYPE_MERCHANT_NEW_ORDER)
+		u.eventStreamUsecase.PublishTransactionStatus(transactions, dto.TransactionStatusWaited)
 	} else {
 		trxCartItems, err := u.getTransactionCartItemsFromTransactions(transactions)
 		if err != nil {
@@ -506,6 +532,9 @@ func (u *transactionUsecaseImpl) HandleTransactionSlpRes(trxRecords []entity.Tra
 		if err != nil {
 			return err
 		}
+
+		u.notificationUsecase.NotifyTransactions(transactions, dto.NOTIFICATION_TYPE_TRANSACTION_PAYMENT_FAILED)
+		u.eventStreamUsecase.PublishPaymentFailed(transactions, paymentRec.PaymentId)
 	}
 
 	return nil
@@ -529,6 +558,18 @@ func (u *transactionUsecaseImpl) UpdateUserTransactionStatus(username string, re
 		return nil, err
 	}
 
+	var notificationType string
+	switch req.Status {
+	case dto.TransactionStatusCompleted:
+		notificationType = dto.NOTIFICATION_TYPE_MERCHANT_TRANSACTION_COMPLETED
+	case dto.TransactionStatusCanceled:
+		notificationType = dto.NOTIFICATION_TYPE_MERCHANT_TRANSACTION_CANCELED
+	case dto.TransactionStatusRequestRefund:
+		notificationType = dto.NOTIFICATION_TYPE_MERCHANT_REFUND_REQUESTED
+	}
+	u.notificationUsecase.NotifyTransactions([]entity.Transaction{*validatedTransaction}, notificationType)
+	u.eventStreamUsecase.PublishTransactionStatus([]entity.Transaction{*validatedTransaction}, req.Status)
+
 	return &dto.UpdateUserTransactionStatusResDTO{
 		InvoiceCode: req.InvoiceCode,
 		TransactionStatus: dto.TransactionStatusResDTO{
@@ -584,7 +625,7 @@ func (u *transactionUsecaseImpl) updateTransactionCanceledProcess(transaction en
 		return nil, domain.ErrUnmarshalJSONCartItems
 	}
 
-	amountPayment := paymentDetails.Subtotal + paymentDetails.DeliveryFee - paymentDetails.MarketplaceVoucherNominal - paymentDetails.MerchantVoucherNominal
+	amountPayment := paymentDetails.PaidAmount()
 	updatedStatus, err := u.transactionRepository.UpdateTransactionStatusCanceled(transaction, amountPayment, trxCartItems)
 	if err != nil {
 		return nil, err
@@ -602,8 +643,8 @@ func (u *transactionUsecaseImpl) updateTransactionCompletedProcess(transaction e
 		return nil, domain.ErrUnmarshalJSONPaymentDetails
 	}
 
-	amountPayment := paymentDetails.Subtotal + paymentDetails.DeliveryFee - paymentDetails.MerchantVoucherNominal
-	updatedStatus, err := u.transactionRepository.UpdateTransactionStatusCompleted(transaction, amountPayment, paymentDetails.MarketplaceVoucherNominal)
+	amountPayment := paymentDetails.PaidAmount()
+	updatedStatus, err := u.transactionRepository.UpdateTransactionStatusCompleted(transaction, amountPayment, paymentDetails.MarketplaceSubsidy())
 	if err != nil {
 		return nil, err
 	}
@@ -643,26 +684,60 @@ func (u *transactionUsecaseImpl) getPaymentDetails(paymentMethodCode string, pay
 		return "", "", 0, nil, err
 	}
 
-	if paymentMethod.ID == dto.PAYMENT_METHOD_ID_SLP {
-		redirectTrxUrl := fmt.Sprintf("%s/%s/payment-status", config.Config.WebTransactionURL, orderCode)
-		redirectUrl, paymentId, err := u.sealabspayRepository.MakePaymentCustomRedirect(paymentAccNumber, uint(amount), redirectTrxUrl)
-		if err != nil {
-			return "", "", 0, nil, err
-		}
+	provider, err := u.paymentProviderRegistry.Get(paymentMethod.Code)
+	if err != nil {
+		return "", "", 0, nil, domain.ErrCreateTransactionPayment
+	}
 
-		return redirectUrl, paymentId, 0, paymentMethod, nil
+	charge, err := provider.CreateCharge(dto.PaymentChargeReqDTO{
+		AccountNumber: paymentAccNumber,
+		Amount:        uint(amount),
+		RedirectUrl:   fmt.Sprintf("%s/%s/payment-status", config.Config.WebTransactionURL, orderCode),
+	})
+	if err != nil {
+		return "", "", 0, nil, err
 	}
 
-	if paymentMethod.ID == dto.PAYMENT_METHOD_ID_WALLET {
-		redirectUrl, paymentId, paymentRecordId, err := u.walletRepository.MakePayment(paymentAccNumber, uint(amount))
+	return charge.RedirectUrl, charge.PaymentId, charge.PaymentRecordId, paymentMethod, nil
+}
+
+// reserveVoucherQuotas takes the order's vouchers off their cached quota, returning
+// a func that gives them back when the transaction can't be made.
+func (u *transactionUsecaseImpl) reserveVoucherQuotas(orderSummary dto.PostOrderSummaryResDTO) (func(), error) {
+	var cancels []func()
+	cancelAll := func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}
+
+	if orderSummary.MarketplaceVoucherId != nil {
+		voucherId := *orderSummary.MarketplaceVoucherId
+		err := u.voucherRedemptionRepository.ReserveMarketplaceVoucherQuota(voucherId)
 		if err != nil {
-			return "", "", 0, nil, err
+			return nil, err
 		}
+		cancels = append(cancels, func() {
+			u.voucherRedemptionRepository.CancelMarketplaceVoucherQuotaReservation(voucherId)
+		})
+	}
 
-		return redirectUrl, paymentId, paymentRecordId, paymentMethod, nil
+	for _, order := range orderSummary.Orders {
+		if order.MerchantVoucherId == nil {
+			continue
+		}
+		voucherId := *order.MerchantVoucherId
+		err := u.voucherRedemptionRepository.ReserveMerchantVoucherQuota(voucherId)
+		if err != nil {
+			cancelAll()
+			return nil, err
+		}
+		cancels = append(cancels, func() {
+			u.voucherRedemptionRepository.CancelMerchantVoucherQuotaReservation(voucherId)
+		})
 	}
 
-	return "", "", 0, nil, domain.ErrCreateTransactionPayment
+	return cancelAll, nil
 }
 
 func (u *transactionUsecaseImpl) validateOrderRequest(username string, req dto.MakeTransactionReqDTO) (*dto.PostOrderSummaryResDTO, error) {
@@ -671,6 +746,8 @@ func (u *transactionUsecaseImpl) validateOrderRequest(username string, req dto.M
 		AddressId:          req.AddressId,
 		Merchants:          req.Merchants,
 		VoucherMarketplace: req.VoucherMarketplace,
+		PaymentMethodCode:  req.PaymentMethodCode,
+		AutoApplyVoucher:   req.AutoApplyVoucher,
 	})
 	if err != nil {
 		return nil, err
@@ -734,10 +811,11 @@ func (u *transactionUsecaseImpl) makeTransactionsEntity(userId uint, paymentReco
 			AccountRelatedNumber: paymentAccRelated,
 		})
 		transaction.PaymentDetails.Set(entity.TransactionPaymentDetails{
-			Subtotal:                  orderItem.SubTotal,
-			DeliveryFee:               orderItem.DeliveryCost,
-			MarketplaceVoucherNominal: orderSummary.DiscountMarketplace / float64(numOfTrx),
-			MerchantVoucherNominal:    orderItem.Discount,
+			Subtotal:                          orderItem.SubTotal,
+			DeliveryFee:                       orderItem.DeliveryCost,
+			MarketplaceVoucherNominal:         orderSummary.DiscountMarketplace / float64(numOfTrx),
+			MarketplaceShippingVoucherNominal: orderItem.ShippingDiscount,
+			MerchantVoucherNominal:            orderItem.Discount,
 		})
 		transaction.Address.Set(entity.TransactionAddress{
 			Name:            orderSummary.Address.Name,
@@ -750,8 +828,17 @@ func (u *transactionUsecaseImpl) makeTransactionsEntity(userId uint, paymentReco
 			ProvinceName:    orderSummary.Address.Province.Name,
 			ZipCode:         orderSummary.Address.Subdistrict.ZipCode,
 		})
+		var appliedShippingRules []entity.TransactionShippingRule
+		for _, rule := range orderItem.DeliveryService.AppliedRules {
+			appliedShippingRules = append(appliedShippingRules, entity.TransactionShippingRule(rule))
+		}
 		transaction.DeliveryOption.Set(entity.TransactionDeliveryOption{
-			CourierName: orderItem.DeliveryService.Name,
+			CourierName:  orderItem.DeliveryService.Name,
+			CourierCode:  orderItem.DeliveryService.DeliveryOption,
+			Service:      orderItem.DeliveryService.Service,
+			BaseCost:     orderItem.DeliveryService.BaseCost,
+			Cost:         orderItem.DeliveryCost,
+			AppliedRules: appliedShippingRules,
 		})
 		trxCartItems := u.makeTransactionCartItemEntity(orderItem.Items)
 		transaction.CartItems.Set(trxCartItems)
@@ -0,0 +1,72 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/usecase"
+	"github.com/jackc/pgtype"
+)
+
+type sellerDetailStub struct {
+	repository.TransactionRepository
+	repository.MerchantRepository
+	usecase.TransactionStatusUsecase
+	usecase.TransactionDeliveryStatusUsecase
+	usecase.TransactionTrackingUsecase
+}
+
+func (s *sellerDetailStub) GetByUsername(username string) (*entity.Merchant, error) {
+	return &entity.Merchant{Domain: "merchant"}, nil
+}
+
+func (s *sellerDetailStub) GetMerchantTransactionDetailByInvoiceCode(merchantDomain string, invoiceCode string) (*entity.Transaction, error) {
+	jsonb := func(value string) pgtype.JSONB {
+		return pgtype.JSONB{Bytes: []byte(value), Status: pgtype.Present}
+	}
+	return &entity.Transaction{
+		ID:             7,
+		InvoiceCode:    invoiceCode,
+		CartItems:      jsonb(`[]`),
+		PaymentDetails: jsonb(`{}`),
+		Address:        jsonb(`{}`),
+		PaymentMethod:  jsonb(`{}`),
+		DeliveryOption: jsonb(`{}`),
+	}, nil
+}
+
+func (s *sellerDetailStub) GetTransactionStatusByTransactionID(transactionID uint) (*dto.TransactionStatusResDTO, error) {
+	return &dto.TransactionStatusResDTO{}, nil
+}
+
+func (s *sellerDetailStub) GetTransactionDeliveryStatusByTransactionID(transactionID uint) (*dto.TransactionDeliveryStatusResDTO, *string, error) {
+	return &dto.TransactionDeliveryStatusResDTO{}, nil, nil
+}
+
+func (s *sellerDetailStub) GetTransactionTrackingEvents(transactionID uint) ([]dto.TransactionTrackingEventResDTO, error) {
+	if transactionID != 7 {
+		return nil, nil
+	}
+	return []dto.TransactionTrackingEventResDTO{{Code: "PICKUP"}, {Code: "TRANSIT"}}, nil
+}
+
+func TestGetSellerTransactionDetailAttachesTrackingEvents(t *testing.T) {
+	stub := &sellerDetailStub{}
+	transactionUsecase := usecase.NewTransactionUsecase(usecase.TransactionUsecaseConfig{
+		TransactionRepository:            stub,
+		MerchantRepository:               stub,
+		TransactionStatusUsecase:         stub,
+		TransactionDeliveryStatusUsecase: stub,
+		TransactionTrackingUsecase:       stub,
+	})
+
+	res, err := transactionUsecase.GetSellerTransactionDetail("seller", "INV/7")
+	if err != nil {
+		t.Fatalf("GetSellerTransactionDetail: %v", err)
+	}
+	if got := len(res.ShippingDetails.TrackingEvents); got != 2 {
+		t.Errorf("seller detail has %d tracking events, want 2", got)
+	}
+}
@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"sort"
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+)
+
+type UserVoucherUsecase interface {
+	GetUserVouchers(username string) ([]dto.UserVoucherResDTO, error)
+	ClaimVoucher(username string, req dto.ClaimVoucherReqDTO) (*dto.UserVoucherResDTO, error)
+	DeleteUserVoucher(username string, userVoucherID uint) error
+}
+
+type UserVoucherUsecaseConfig struct {
+	UserVoucherRepository        repository.UserVoucherRepository
+	UserRepository               repository.UserRepository
+	MarketplaceVoucherRepository repository.MarketplaceVoucherRepository
+	MerchantRepository           repository.MerchantRepository
+	CartItemUsecase              CartItemUsecase
+	VoucherRuleUsecase           VoucherRuleUsecase
+}
+
+type userVoucherUsecaseImpl struct {
+	userVoucherRepository        repository.UserVoucherRepository
+	userRepository               repository.UserRepository
+	marketplaceVoucherRepository repository.MarketplaceVoucherRepository
+	merchantRepository           repository.MerchantRepository
+	cartItemUsecase              CartItemUsecase
+	voucherRuleUsecase           VoucherRuleUsecase
+}
+
+func NewUserVoucherUsecase(c UserVoucherUsecaseConfig) UserVoucherUsecase {
+	return &userVoucherUsecaseImpl{
+		userVoucherRepository:        c.UserVoucherRepository,
+		userRepository:               c.UserRepository,
+		marketplaceVoucherRepository: c.MarketplaceVoucherRepository,
+		merchantRepository:           c.MerchantRepository,
+		cartItemUsecase:              c.CartItemUsecase,
+		voucherRuleUsecase:           c.VoucherRuleUsecase,
+	}
+}
+
+func (u *userVoucherUsecaseImpl) GetUserVouchers(username string) ([]dto.UserVoucherResDTO, error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	userVouchers, err := u.userVoucherRepository.GetUserVouchers(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := u.makeUserVoucherResDTOs(*user, userVouchers)
+	if err != nil {
+		return nil, err
+	}
+
+	// applicable vouchers come first, the biggest saving on top
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].IsApplicable != res[j].IsApplicable {
+			return res[i].IsApplicable
+		}
+		return res[i].EstimatedDiscount > res[j].EstimatedDiscount
+	})
+
+	return res, nil
+}
+
+func (u *userVoucherUsecaseImpl) ClaimVoucher(username string, req dto.ClaimVoucherReqDTO) (*dto.UserVoucherResDTO, error) {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	userVoucher := entity.UserVoucher{UserId: user.ID}
+	if req.MerchantDomain == "" {
+		mpVoucher, err := u.marketplaceVoucherRepository.GetMarketplaceVoucherByCode(req.VoucherCode)
+		if err != nil {
+			return nil, err
+		}
+		if !isVoucherAvailable(mpVoucher.IsInvalid, mpVoucher.Quota, mpVoucher.StartDate, mpVoucher.ExpiredAt) {
+			return nil, domain.ErrVoucherNotClaimable
+		}
+		userVoucher.MarketplaceVoucherId = &mpVoucher.ID
+	} else {
+		sellerVoucher, err := u.merchantRepository.GetMerchantVoucherByCode(req.MerchantDomain, req.VoucherCode)
+		if err != nil {
+			return nil, err
+		}
+		if !isVoucherAvailable(sellerVoucher.IsInvalid, sellerVoucher.Quota, sellerVoucher.StartDate, sellerVoucher.ExpiredAt) {
+			return nil, domain.ErrVoucherNotClaimable
+		}
+		userVoucher.MerchantVoucherId = &sellerVoucher.ID
+	}
+
+	_, err = u.userVoucherRepository.CreateUserVoucher(&userVoucher)
+	if err != nil {
+		return nil, err
+	}
+
+	userVouchers, err := u.userVoucherRepository.GetUserVouchers(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, claimed := range userVouchers {
+		if claimed.ID != userVoucher.ID {
+			continue
+		}
+		res, err := u.makeUserVoucherResDTOs(*user, []entity.UserVoucher{claimed})
+		if err != nil {
+			return nil, err
+		}
+		return &res[0], nil
+	}
+
+	return nil, domain.ErrUserVoucherNotFound
+}
+
+func (u *userVoucherUsecaseImpl) DeleteUserVoucher(username string, userVoucherID uint) error {
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	return u.userVoucherRepository.DeleteUserVoucher(user.ID, userVoucherID)
+}
+
+// makeUserVoucherResDTOs hints whether each voucher applies to the checked items in
+// the user's cart. Vouchers that can no longer be used are left out.
+func (u *userVoucherUsecaseImpl) makeUserVoucherResDTOs(user entity.User, userVouchers []entity.UserVoucher) ([]dto.UserVoucherResDTO, error) {
+	cart, err := u.cartItemUsecase.GetCartItems(user.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	var allItems []dto.OrderItemDTO
+	var merchantItemsMap = make(map[string][]dto.OrderItemDTO)
+	var merchantTotalMap = make(map[string]float64)
+	var total float64
+	if cart != nil {
+		total = cart.Total
+		for _, store := range cart.Carts {
+			for _, cartItem := range store.Items {
+				if !cartItem.IsChecked {
+					continue
+				}
+				item := dto.OrderItemDTO{
+					ProductId:      cartItem.ProductId,
+					MerchantId:     cartItem.MerchantId,
+					MerchantDomain: cartItem.MerchantDomain,
+					DiscountPrice:  cartItem.DiscountPrice,
+					Quantity:       cartItem.Quantity,
+				}
+				allItems = append(allItems, item)
+				merchantItemsMap[store.MerchantDomain] = append(merchantItemsMap[store.MerchantDomain], item)
+				merchantTotalMap[store.MerchantDomain] += cartItem.DiscountPrice * float64(cartItem.Quantity)
+			}
+		}
+	}
+
+	var res = make([]dto.UserVoucherResDTO, 0, len(userVouchers))
+	for _, userVoucher := range userVouchers {
+		var voucherRes dto.UserVoucherResDTO
+		if userVoucher.MarketplaceVoucher != nil {
+			mpVoucher := *userVoucher.MarketplaceVoucher
+			voucherRes = dto.UserVoucherResDTO{
+				ID:                 userVoucher.ID,
+				Source:             dto.USER_VOUCHER_SOURCE_MARKETPLACE,
+				Code:               mpVoucher.Code,
+				Type:               mpVoucher.Type,
+				DiscountPercentage: mpVoucher.DiscountPercentage,
+				MaxDiscountNominal: mpVoucher.MaxDiscountNominal,
+				MinOrderNominal:    mpVoucher.MinOrderNominal,
+				ExpiredAt:          mpVoucher.ExpiredAt,
+			}
+			voucherRes.Reason, err = u.voucherRuleUsecase.CheckMarketplaceVoucher(mpVoucher, total, dto.VoucherRuleCheckDTO{
+				UserId:        user.ID,
+				UserCreatedAt: user.CreatedAt,
+				Items:         allItems,
+			})
+			if err != nil {
+				return nil, err
+			}
+			// the delivery cost is not known until checkout
+			voucherRes.EstimatedDiscount = getMarketplaceVoucherDiscount(mpVoucher, total, 0)
+		} else if userVoucher.MerchantVoucher != nil {
+			sellerVoucher := *userVoucher.MerchantVoucher
+			voucherRes = dto.UserVoucherResDTO{
+				ID:                 userVoucher.ID,
+				Source:             dto.USER_VOUCHER_SOURCE_MERCHANT,
+				MerchantDomain:     sellerVoucher.MerchantDomain,
+				Code:               sellerVoucher.Code,
+				DiscountNominal:    sellerVoucher.DiscountNominal,
+				MaxDiscountNominal: sellerVoucher.MaxDiscountNominal,
+				MinOrderNominal:    sellerVoucher.MinOrderNominal,
+				ExpiredAt:          sellerVoucher.ExpiredAt,
+			}
+			voucherRes.Reason = dto.VOUCHER_REJECT_REASON_MERCHANT_NOT_IN_CART
+			if len(merchantItemsMap[sellerVoucher.MerchantDomain]) > 0 {
+				voucherRes.Reason, err = u.voucherRuleUsecase.CheckMerchantVoucher(sellerVoucher, merchantTotalMap[sellerVoucher.MerchantDomain], dto.VoucherRuleCheckDTO{
+					UserId:        user.ID,
+					UserCreatedAt: user.CreatedAt,
+					Items:         merchantItemsMap[sellerVoucher.MerchantDomain],
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+			voucherRes.EstimatedDiscount = getMerchantVoucherDiscount(sellerVoucher, merchantTotalMap[sellerVoucher.MerchantDomain])
+		} else {
+			continue
+		}
+
+		voucherRes.IsApplicable = voucherRes.Reason == ""
+		if !voucherRes.IsApplicable {
+			voucherRes.EstimatedDiscount = 0
+		}
+		res = append(res, voucherRes)
+	}
+
+	return res, nil
+}
+
+func isVoucherAvailable(isInvalid bool, quota int, startDate time.Time, expiredAt time.Time) bool {
+	now := time.Now()
+	return !isInvalid && quota > 0 && !startDate.After(now) && expiredAt.After(now)
+}
@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"strconv"
+	"strings"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+)
+
+type VoucherCodeUsecase interface {
+	GenerateMarketplaceVoucherCodes(voucherCode string, req dto.GenerateVoucherCodesReqDTO) (*dto.GenerateVoucherCodesResDTO, error)
+	GetMarketplaceVoucherCodes(voucherCode string, req dto.VoucherCodeListParamReqDTO) (*dto.VoucherCodeListResDTO, error)
+	ExportMarketplaceVoucherCodes(voucherCode string) ([]byte, error)
+	GenerateMerchantVoucherCodes(username string, voucherCode string, req dto.GenerateVoucherCodesReqDTO) (*dto.GenerateVoucherCodesResDTO, error)
+	GetMerchantVoucherCodes(username string, voucherCode string, req dto.VoucherCodeListParamReqDTO) (*dto.VoucherCodeListResDTO, error)
+	ExportMerchantVoucherCodes(username string, voucherCode string) ([]byte, error)
+}
+
+type VoucherCodeUsecaseConfig struct {
+	VoucherCodeRepository        repository.VoucherCodeRepository
+	MarketplaceVoucherRepository repository.MarketplaceVoucherRepository
+	MerchantRepository           repository.MerchantRepository
+}
+
+type voucherCodeUsecaseImpl struct {
+	voucherCodeRepository        repository.VoucherCodeRepository
+	marketplaceVoucherRepository repository.MarketplaceVoucherRepository
+	merchantRepository           repository.MerchantRepository
+}
+
+func NewVoucherCodeUsecase(c VoucherCodeUsecaseConfig) VoucherCodeUsecase {
+	return &voucherCodeUsecaseImpl{
+		voucherCodeRepository:        c.VoucherCodeRepository,
+		marketplaceVoucherRepository: c.MarketplaceVoucherRepository,
+		merchantRepository:           c.MerchantRepository,
+	}
+}
+
+func (u *voucherCodeUsecaseImpl) GenerateMarketplaceVoucherCodes(voucherCode string, req dto.GenerateVoucherCodesReqDTO) (*dto.GenerateVoucherCodesResDTO, error) {
+	voucher, err := u.marketplaceVoucherRepository.GetMarketplaceVoucherByCode(voucherCode)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := u.generateVoucherCodes(MARKETPLACE_PREFIX, req, func(code string) entity.VoucherCode {
+		return entity.VoucherCode{MarketplaceVoucherId: &voucher.ID, Code: code}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.GenerateVoucherCodesResDTO{
+		Generated: len(codes),
+		Codes:     makeVoucherCodeResDTOs(codes),
+	}, nil
+}
+
+func (u *voucherCodeUsecaseImpl) GetMarketplaceVoucherCodes(voucherCode string, req dto.VoucherCodeListParamReqDTO) (*dto.VoucherCodeListResDTO, error) {
+	voucher, err := u.marketplaceVoucherRepository.GetMarketplaceVoucherByCode(voucherCode)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, total, err := u.voucherCodeRepository.GetMarketplaceVoucherCodes(voucher.ID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return makeVoucherCodeListResDTO(codes, total, req), nil
+}
+
+func (u *voucherCodeUsecaseImpl) ExportMarketplaceVoucherCodes(voucherCode string) ([]byte, error) {
+	voucher, err := u.marketplaceVoucherRepository.GetMarketplaceVoucherByCode(voucherCode)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := u.voucherCodeRepository.GetAllMarketplaceVoucherCodes(voucher.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return makeVoucherCodesCSV(codes)
+}
+
+func (u *voucherCodeUsecaseImpl) GenerateMerchantVoucherCodes(username string, voucherCode string, req dto.GenerateVoucherCodesReqDTO) (*dto.GenerateVoucherCodesResDTO, error) {
+	merchant, err := u.merchantRepository.GetByUsername(username)
+	if err != nil {
+		return nil, domain.ErrMerchantUsernameNotFound
+	}
+
+	voucher, err := u.merchantRepository.GetMerchantVoucherByCode(merchant.Domain, voucherCode)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := u.generateVoucherCodes(merchant.Domain, req, func(code string) entity.VoucherCode {
+		return entity.VoucherCode{MerchantVoucherId: &voucher.ID, Code: code}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.GenerateVoucherCodesResDTO{
+		Generated: len(codes),
+		Codes:     makeVoucherCodeResDTOs(codes),
+	}, nil
+}
+
+func (u *voucherCodeUsecaseImpl) GetMerchantVoucherCodes(username string, voucherCode string, req dto.VoucherCodeListParamReqDTO) (*dto.VoucherCodeListResDTO, error) {
+	merchant, err := u.merchantRepository.GetByUsername(username)
+	if err != nil {
+		return nil, domain.ErrMerchantUsernameNotFound
+	}
+
+	voucher, err := u.merchantRepository.GetMerchantVoucherByCode(merchant.Domain, voucherCode)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, total, err := u.voucherCodeRepository.GetMerchantVoucherCodes(voucher.ID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return makeVoucherCodeListResDTO(codes, total, req), nil
+}
+
+func (u *voucherCodeUsecaseImpl) ExportMerchantVoucherCodes(username string, voucherCode string) ([]byte, error) {
+	merchant, err := u.merchantRepository.GetByUsername(username)
+	if err != nil {
+		return nil, domain.ErrMerchantUsernameNotFound
+	}
+
+	voucher, err := u.merchantRepository.GetMerchantVoucherByCode(merchant.Domain, voucherCode)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := u.voucherCodeRepository.GetAllMerchantVoucherCodes(voucher.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return makeVoucherCodesCSV(codes)
+}
+
+// generateVoucherCodes keeps drawing random codes from the template until the batch is
+// full, returning fewer codes than requested when too many draws collide.
+func (u *voucherCodeUsecaseImpl) generateVoucherCodes(ownerPrefix string, req dto.GenerateVoucherCodesReqDTO, makeCode func(code string) entity.VoucherCode) ([]entity.VoucherCode, error) {
+	randomLength := req.Length - len(req.Prefix)
+	if randomLength < 1 || math.Pow(float64(util.VoucherCodeCharsetSize), float64(randomLength)) < float64(2*req.Count) {
+		return nil, domain.ErrVoucherCodeTemplateTooSmall
+	}
+
+	codePrefix := strings.ToUpper(ownerPrefix) + req.Prefix
+	codeLength := len(ownerPrefix) + req.Length
+	err := util.ValidateVoucherCode(util.GenerateVoucherCode(codePrefix, codeLength), ownerPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var createdCodes []entity.VoucherCode
+	for attempt := 0; attempt < dto.VOUCHER_CODE_GENERATE_MAX_ATTEMPTS && len(createdCodes) < req.Count; attempt++ {
+		var isDrawn = make(map[string]bool)
+		var candidates []entity.VoucherCode
+		for len(candidates) < req.Count-len(createdCodes) {
+			code := util.GenerateVoucherCode(codePrefix, codeLength)
+			if isDrawn[code] {
+				continue
+			}
+			isDrawn[code] = true
+			candidates = append(candidates, makeCode(code))
+		}
+
+		codes, err := u.voucherCodeRepository.CreateVoucherCodes(candidates)
+		if err != nil {
+			return nil, err
+		}
+		createdCodes = append(createdCodes, codes...)
+	}
+
+	return createdCodes, nil
+}
+
+func makeVoucherCodeResDTOs(codes []entity.VoucherCode) []dto.VoucherCodeResDTO {
+	var res = make([]dto.VoucherCodeResDTO, 0, len(codes))
+	for _, code := range codes {
+		status := dto.VOUCHER_CODE_STATUS_AVAILABLE
+		if code.RedeemedAt != nil {
+			status = dto.VOUCHER_CODE_STATUS_REDEEMED
+		}
+		res = append(res, dto.VoucherCodeResDTO{
+			ID:         code.ID,
+			Code:       code.Code,
+			Status:     status,
+			RedeemedAt: code.RedeemedAt,
+			RedeemedBy: code.RedeemedBy,
+			PaymentId:  code.PaymentId,
+		})
+	}
+	return res
+}
+
+func makeVoucherCodeListResDTO(codes []entity.VoucherCode, total int64, req dto.VoucherCodeListParamReqDTO) *dto.VoucherCodeListResDTO {
+	return &dto.VoucherCodeListResDTO{
+		PaginationResponse: dto.PaginationResponse{
+			TotalData:   total,
+			TotalPage:   (total + int64(req.Limit) - 1) / int64(req.Limit),
+			CurrentPage: req.Page,
+		},
+		Codes: makeVoucherCodeResDTOs(codes),
+	}
+}
+
+func makeVoucherCodesCSV(codes []entity.VoucherCode) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	err := w.Write([]string{"code", "status", "redeemed_at", "redeemed_by", "payment_id"})
+	if err != nil {
+		return nil, domain.ErrExportVoucherCodes
+	}
+
+	for _, code := range makeVoucherCodeResDTOs(codes) {
+		var redeemedAt, redeemedBy, paymentId string
+		if code.RedeemedAt != nil {
+			redeemedAt = code.RedeemedAt.Format(dto.VOUCHER_CODE_CSV_TIME_FORMAT)
+		}
+		if code.RedeemedBy != nil {
+			redeemedBy = strconv.FormatUint(uint64(*code.RedeemedBy), 10)
+		}
+		if code.PaymentId != nil {
+			paymentId = *code.PaymentId
+		}
+		err = w.Write([]string{code.Code, code.Status, redeemedAt, redeemedBy, paymentId})
+		if err != nil {
+			return nil, domain.ErrExportVoucherCodes
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, domain.ErrExportVoucherCodes
+	}
+
+	return buf.Bytes(), nil
+}
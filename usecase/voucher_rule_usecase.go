@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/util"
+)
+
+type VoucherRuleUsecase interface {
+	GetMarketplaceVoucherRules(voucherCode string) ([]dto.VoucherRuleResDTO, error)
+	UpdateMarketplaceVoucherRules(voucherCode string, req dto.UpdateVoucherRulesReqDTO) ([]dto.VoucherRuleResDTO, error)
+	GetMerchantVoucherRules(username string, voucherCode string) ([]dto.VoucherRuleResDTO, error)
+	UpdateMerchantVoucherRules(username string, voucherCode string, req dto.UpdateVoucherRulesReqDTO) ([]dto.VoucherRuleResDTO, error)
+
+	CheckMarketplaceVoucherRules(voucherID uint, input dto.VoucherRuleCheckDTO) (string, error)
+	CheckMerchantVoucherRules(voucherID uint, input dto.VoucherRuleCheckDTO) (string, error)
+	CheckMarketplaceVoucher(voucher entity.MarketplaceVoucher, subTotal float64, input dto.VoucherRuleCheckDTO) (string, error)
+	CheckMerchantVoucher(voucher entity.MerchantVoucher, subTotal float64, input dto.VoucherRuleCheckDTO) (string, error)
+}
+
+type VoucherRuleUsecaseConfig struct {
+	VoucherRuleRepository        repository.VoucherRuleRepository
+	MarketplaceVoucherRepository repository.MarketplaceVoucherRepository
+	MerchantRepository           repository.MerchantRepository
+}
+
+type voucherRuleUsecaseImpl struct {
+	voucherRuleRepository        repository.VoucherRuleRepository
+	marketplaceVoucherRepository repository.MarketplaceVoucherRepository
+	merchantRepository           repository.MerchantRepository
+}
+
+func NewVoucherRuleUsecase(c VoucherRuleUsecaseConfig) VoucherRuleUsecase {
+	return &voucherRuleUsecaseImpl{
+		voucherRuleRepository:        c.VoucherRuleRepository,
+		marketplaceVoucherRepository: c.MarketplaceVoucherRepository,
+		merchantRepository:           c.MerchantRepository,
+	}
+}
+
+func (u *voucherRuleUsecaseImpl) GetMarketplaceVoucherRules(voucherCode string) ([]dto.VoucherRuleResDTO, error) {
+	voucher, err := u.marketplaceVoucherRepository.GetMarketplaceVoucherByCode(voucherCode)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := u.voucherRuleRepository.GetMarketplaceVoucherRules(voucher.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return makeVoucherRuleResDTOs(rules), nil
+}
+
+func (u *voucherRuleUsecaseImpl) UpdateMarketplaceVoucherRules(voucherCode string, req dto.UpdateVoucherRulesReqDTO) ([]dto.VoucherRuleResDTO, error) {
+	voucher, err := u.marketplaceVoucherRepository.GetMarketplaceVoucherByCode(voucherCode)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := makeVoucherRules(req.Rules, true)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err = u.voucherRuleRepository.UpdateMarketplaceVoucherRules(voucher.ID, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return makeVoucherRuleResDTOs(rules), nil
+}
+
+func (u *voucherRuleUsecaseImpl) GetMerchantVoucherRules(username string, voucherCode string) ([]dto.VoucherRuleResDTO, error) {
+	voucher, err := u.getMerchantVoucher(username, voucherCode)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := u.voucherRuleRepository.GetMerchantVoucherRules(voucher.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return makeVoucherRuleResDTOs(rules), nil
+}
+
+func (u *voucherRuleUsecaseImpl) UpdateMerchantVoucherRules(username string, voucherCode string, req dto.UpdateVoucherRulesReqDTO) ([]dto.VoucherRuleResDTO, error) {
+	voucher, err := u.getMerchantVoucher(username, voucherCode)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := makeVoucherRules(req.Rules, false)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err = u.voucherRuleRepository.UpdateMerchantVoucherRules(voucher.ID, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return makeVoucherRuleResDTOs(rules), nil
+}
+
+func (u *voucherRuleUsecaseImpl) getMerchantVoucher(username string, voucherCode string) (*entity.MerchantVoucher, error) {
+	merchant, err := u.merchantRepository.GetByUsername(username)
+	if err != nil {
+		return nil, domain.ErrMerchantUsernameNotFound
+	}
+
+	return u.merchantRepository.GetMerchantVoucherByCode(merchant.Domain, voucherCode)
+}
+
+// CheckMarketplaceVoucherRules returns the reason the voucher is rejected, or an
+// empty string when every rule passes.
+func (u *voucherRuleUsecaseImpl) CheckMarketplaceVoucherRules(voucherID uint, input dto.VoucherRuleCheckDTO) (string, error) {
+	rules, err := u.voucherRuleRepository.GetMarketplaceVoucherRules(voucherID)
+	if err != nil {
+		return "", err
+	}
+
+	return u.checkVoucherRules(rules, input, func() (int64, error) {
+		return u.voucherRuleRepository.CountUserMarketplaceVoucherUsage(input.UserId, voucherID)
+	})
+}
+
+func (u *voucherRuleUsecaseImpl) CheckMerchantVoucherRules(voucherID uint, input dto.VoucherRuleCheckDTO) (string, error) {
+	rules, err := u.voucherRuleRepository.GetMerchantVoucherRules(voucherID)
+	if err != nil {
+		return "", err
+	}
+
+	return u.checkVoucherRules(rules, input, func() (int64, error) {
+		return u.voucherRuleRepository.CountUserMerchantVoucherUsage(input.UserId, voucherID)
+	})
+}
+
+// CheckMarketplaceVoucher checks the minimum order before the voucher's rules.
+func (u *voucherRuleUsecaseImpl) CheckMarketplaceVoucher(voucher entity.MarketplaceVoucher, subTotal float64, input dto.VoucherRuleCheckDTO) (string, error) {
+	if voucher.MinOrderNominal > subTotal {
+		return dto.VOUCHER_REJECT_REASON_MIN_ORDER_NOT_MET, nil
+	}
+
+	return u.CheckMarketplaceVoucherRules(voucher.ID, input)
+}
+
+func (u *voucherRuleUsecaseImpl) CheckMerchantVoucher(voucher entity.MerchantVoucher, subTotal float64, input dto.VoucherRuleCheckDTO) (string, error) {
+	if voucher.MinOrderNominal > subTotal {
+		return dto.VOUCHER_REJECT_REASON_MIN_ORDER_NOT_MET, nil
+	}
+
+	return u.CheckMerchantVoucherRules(voucher.ID, input)
+}
+
+// checkVoucherRules requires every item to match the category, product and
+// merchant rules, the voucher discount is taken from the whole subtotal.
+func (u *voucherRuleUsecaseImpl) checkVoucherRules(rules []entity.VoucherRule, input dto.VoucherRuleCheckDTO, countUsage func() (int64, error)) (string, error) {
+	var productIds []uint
+	var productIdStrs, merchantIdStrs []string
+	for _, item := range input.Items {
+		productIdStr := strconv.FormatUint(uint64(item.ProductId), 10)
+		if !util.IsSliceContainString(productIdStrs, productIdStr) {
+			productIds = append(productIds, item.ProductId)
+			productIdStrs = append(productIdStrs, productIdStr)
+		}
+		merchantIdStrs = append(merchantIdStrs, strconv.FormatUint(uint64(item.MerchantId), 10))
+	}
+
+	for _, rule := range rules {
+		targets := strings.Split(rule.Targets, ",")
+
+		switch rule.Type {
+		case dto.VOUCHER_RULE_TYPE_FIRST_PURCHASE:
+			count, err := u.voucherRuleRepository.CountUserTransactions(input.UserId)
+			if err != nil {
+				return "", err
+			}
+			if count > 0 {
+				return dto.VOUCHER_REJECT_REASON_NOT_FIRST_PURCHASE, nil
+			}
+		case dto.VOUCHER_RULE_TYPE_USAGE_LIMIT_PER_USER:
+			count, err := countUsage()
+			if err != nil {
+				return "", err
+			}
+			if count >= int64(rule.Value) {
+				return dto.VOUCHER_REJECT_REASON_USAGE_LIMIT_REACHED, nil
+			}
+		case dto.VOUCHER_RULE_TYPE_CATEGORY:
+			var categoryIds []uint
+			for _, target := range targets {
+				categoryId, _ := strconv.ParseUint(target, 10, 64)
+				categoryIds = append(categoryIds, uint(categoryId))
+			}
+			count, err := u.voucherRuleRepository.CountProductsInCategories(productIds, categoryIds)
+			if err != nil {
+				return "", err
+			}
+			if count < int64(len(productIds)) {
+				return dto.VOUCHER_REJECT_REASON_CATEGORY_NOT_ALLOWED, nil
+			}
+		case dto.VOUCHER_RULE_TYPE_PRODUCT:
+			if !isAllSliceContainString(targets, productIdStrs) {
+				return dto.VOUCHER_REJECT_REASON_PRODUCT_NOT_ALLOWED, nil
+			}
+		case dto.VOUCHER_RULE_TYPE_MERCHANT:
+			if !isAllSliceContainString(targets, merchantIdStrs) {
+				return dto.VOUCHER_REJECT_REASON_MERCHANT_NOT_ALLOWED, nil
+			}
+		case dto.VOUCHER_RULE_TYPE_PAYMENT_METHOD:
+			// the summary is requested before a payment method is picked, a voucher
+			// the buyer did not choose is only applied once the method is known
+			if input.PaymentMethodCode == "" {
+				if input.IsAutoApply {
+					return dto.VOUCHER_REJECT_REASON_PAYMENT_REQUIRED, nil
+				}
+			} else if !util.IsSliceContainString(targets, input.PaymentMethodCode) {
+				return dto.VOUCHER_REJECT_REASON_PAYMENT_NOT_ALLOWED, nil
+			}
+		case dto.VOUCHER_RULE_TYPE_NEW_USER_DAYS:
+			if time.Since(input.UserCreatedAt) > time.Duration(rule.Value)*24*time.Hour {
+				return dto.VOUCHER_REJECT_REASON_NOT_NEW_USER, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func isAllSliceContainString(strSlice []string, strs []string) bool {
+	for _, str := range strs {
+		if !util.IsSliceContainString(strSlice, str) {
+			return false
+		}
+	}
+	return len(strs) > 0
+}
+
+// makeVoucherRules validates the requested rules, only marketplace vouchers may be
+// limited to merchants.
+func makeVoucherRules(req []dto.VoucherRuleReqDTO, isMarketplace bool) ([]entity.VoucherRule, error) {
+	var rules = make([]entity.VoucherRule, 0, len(req))
+	var ruleTypes []string
+	for _, ruleReq := range req {
+		if util.IsSliceContainString(ruleTypes, ruleReq.Type) {
+			return nil, domain.ErrDuplicateVoucherRule
+		}
+		ruleTypes = append(ruleTypes, ruleReq.Type)
+
+		var targets []string
+		for _, target := range ruleReq.Targets {
+			target = strings.TrimSpace(target)
+			if target != "" {
+				targets = append(targets, target)
+			}
+		}
+
+		rule := entity.VoucherRule{Type: ruleReq.Type}
+		switch ruleReq.Type {
+		case dto.VOUCHER_RULE_TYPE_USAGE_LIMIT_PER_USER, dto.VOUCHER_RULE_TYPE_NEW_USER_DAYS:
+			if ruleReq.Value < 1 {
+				return nil, domain.ErrInvalidVoucherRule
+			}
+			rule.Value = ruleReq.Value
+		case dto.VOUCHER_RULE_TYPE_CATEGORY, dto.VOUCHER_RULE_TYPE_PRODUCT, dto.VOUCHER_RULE_TYPE_MERCHANT:
+			if ruleReq.Type == dto.VOUCHER_RULE_TYPE_MERCHANT && !isMarketplace {
+				return nil, domain.ErrInvalidVoucherRule
+			}
+			if len(targets) == 0 {
+				return nil, domain.ErrInvalidVoucherRule
+			}
+			for _, target := range targets {
+				if _, err := strconv.ParseUint(target, 10, 64); err != nil {
+					return nil, domain.ErrInvalidVoucherRule
+				}
+			}
+			rule.Targets = strings.Join(targets, ",")
+		case dto.VOUCHER_RULE_TYPE_PAYMENT_METHOD:
+			if len(targets) == 0 {
+				return nil, domain.ErrInvalidVoucherRule
+			}
+			rule.Targets = strings.ToUpper(strings.Join(targets, ","))
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func makeVoucherRuleResDTOs(rules []entity.VoucherRule) []dto.VoucherRuleResDTO {
+	var res = make([]dto.VoucherRuleResDTO, 0, len(rules))
+	for _, rule := range rules {
+		var targets = []string{}
+		if rule.Targets != "" {
+			targets = strings.Split(rule.Targets, ",")
+		}
+		res = append(res, dto.VoucherRuleResDTO{
+			ID:      rule.ID,
+			Type:    rule.Type,
+			Value:   rule.Value,
+			Targets: targets,
+		})
+	}
+	return res
+}
@@ -25,12 +25,14 @@ type WalletUsecaseConfig struct {
 	UserRepository       repository.UserRepository
 	WalletRepository     repository.WalletRepository
 	SealabspayRepository repository.SealabspayRepository
+	NotificationUsecase  NotificationUsecase
 }
 
 type walletUsecaseImpl struct {
 	userRepository       repository.UserRepository
 	walletRepository     repository.WalletRepository
 	sealabspayRepository repository.SealabspayRepository
+	notificationUsecase  NotificationUsecase
 }
 
 func NewWalletUsecase(c WalletUsecaseConfig) WalletUsecase {
@@ -38,6 +40,7 @@ func NewWalletUsecase(c WalletUsecaseConfig) WalletUsecase {
 		userRepository:       c.UserRepository,
 		walletRepository:     c.WalletRepository,
 		sealabspayRepository: c.SealabspayRepository,
+		notificationUsecase:  c.NotificationUsecase,
 	}
 }
 
@@ -256,11 +259,15 @@ func (u *walletUsecaseImpl) HandleTopUpWalletUsingSlpRes(walletTrx entity.Wallet
 		if err != nil {
 			return err
 		}
+
+		u.notificationUsecase.NotifyAmount(foundWallet.UserId, dto.NOTIFICATION_TYPE_WALLET_TOP_UP_SUCCESS, walletTrx.PaymentRecord.Amount)
 	} else {
 		err = u.walletRepository.BalanceTopUpFailed(*foundWallet, walletTrx)
 		if err != nil {
 			return err
 		}
+
+		u.notificationUsecase.NotifyAmount(foundWallet.UserId, dto.NOTIFICATION_TYPE_WALLET_TOP_UP_FAILED, walletTrx.PaymentRecord.Amount)
 	}
 
 	return nil
@@ -3,6 +3,7 @@ package usecase
 import (
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/domain"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/dto"
+	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/entity"
 	"git-garena.com/sea-labs-id/batch-04/stage-02/blanche/blanche-be/repository"
 )
 
@@ -12,27 +13,43 @@ type WalletpayUsecase interface {
 }
 
 type WalletpayUsecaseConfig struct {
-	WalletRepository     repository.WalletRepository
-	UserRepository       repository.UserRepository
-	PaymentRecordUsecase PaymentRecordUsecase
+	WalletRepository                   repository.WalletRepository
+	UserRepository                     repository.UserRepository
+	PaymentRecordRepository            repository.PaymentRecordRepository
+	TransactionPaymentRecordRepository repository.TransactionPaymentRecordRepository
+	PaymentRecordUsecase               PaymentRecordUsecase
 }
 
 type walletpayUsecaseImpl struct {
-	walletRepository     repository.WalletRepository
-	userRepository       repository.UserRepository
-	paymentRecordUsecase PaymentRecordUsecase
+	walletRepository                   repository.WalletRepository
+	userRepository                     repository.UserRepository
+	paymentRecordRepository            repository.PaymentRecordRepository
+	transactionPaymentRecordRepository repository.TransactionPaymentRecordRepository
+	paymentRecordUsecase               PaymentRecordUsecase
 }
 
 func NewWalletpayUsecase(c WalletpayUsecaseConfig) WalletpayUsecase {
 	return &walletpayUsecaseImpl{
-		walletRepository:     c.WalletRepository,
-		userRepository:       c.UserRepository,
-		paymentRecordUsecase: c.PaymentRecordUsecase,
+		walletRepository:                   c.WalletRepository,
+		userRepository:                     c.UserRepository,
+		paymentRecordRepository:            c.PaymentRecordRepository,
+		transactionPaymentRecordRepository: c.TransactionPaymentRecordRepository,
+		paymentRecordUsecase:               c.PaymentRecordUsecase,
 	}
 }
 
 func (u *walletpayUsecaseImpl) HandleWalletpaySuccessRequest(username string, req dto.WalletpayReqDTO) (*dto.WalletpayResDTO, error) {
-	err := u.checkWalletBalance(username, req.Amount)
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.checkPaymentOwner(*user, req.PaymentId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.checkWalletBalance(*user, req.Amount)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +67,17 @@ func (u *walletpayUsecaseImpl) HandleWalletpaySuccessRequest(username string, re
 }
 
 func (u *walletpayUsecaseImpl) HandleWalletpayCancelRequest(username string, req dto.WalletpayReqDTO) (*dto.WalletpayResDTO, error) {
-	err := u.paymentRecordUsecase.UpdatePaymentRecordStatus(req.PaymentId, req.Amount, false)
+	user, err := u.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.checkPaymentOwner(*user, req.PaymentId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.paymentRecordUsecase.UpdatePaymentRecordStatus(req.PaymentId, req.Amount, false)
 	if err != nil {
 		return nil, err
 	}
@@ -62,12 +89,25 @@ func (u *walletpayUsecaseImpl) HandleWalletpayCancelRequest(username string, req
 	}, nil
 }
 
-func (u *walletpayUsecaseImpl) checkWalletBalance(username string, amount uint) error {
-	user, err := u.userRepository.GetUserByUsername(username)
+// checkPaymentOwner only lets the buyer settle their own wallet payment.
+func (u *walletpayUsecaseImpl) checkPaymentOwner(user entity.User, paymentId string) error {
+	paymentRecord, err := u.paymentRecordRepository.GetByPaymentId(paymentId)
 	if err != nil {
 		return err
 	}
+	if paymentRecord.PaymentMethodId != dto.PAYMENT_METHOD_ID_WALLET {
+		return domain.ErrPaymentIdNotFound
+	}
+
+	trxPayRecords, err := u.transactionPaymentRecordRepository.GetByPaymentId(paymentId)
+	if err != nil || len(trxPayRecords) == 0 || trxPayRecords[0].Transaction.UserId != user.ID {
+		return domain.ErrPaymentIdNotFound
+	}
+
+	return nil
+}
 
+func (u *walletpayUsecaseImpl) checkWalletBalance(user entity.User, amount uint) error {
 	wallet, err := u.walletRepository.GetByUserId(user.ID)
 	if err != nil {
 		return err
@@ -0,0 +1,24 @@
+package util
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+const voucherCodeCharset = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const VoucherCodeCharsetSize = len(voucherCodeCharset)
+
+// GenerateVoucherCode appends random characters to prefix until it is length long,
+// leaving out characters that are easy to misread.
+func GenerateVoucherCode(prefix string, length int) string {
+	code := []byte(prefix)
+	for len(code) < length {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(voucherCodeCharset))))
+		if err != nil {
+			continue
+		}
+		code = append(code, voucherCodeCharset[n.Int64()])
+	}
+	return string(code)
+}
@@ -31,3 +31,13 @@ func ValidateResSlpSignature(input dto.SealabspayReqDTO) error {
 
 	return nil
 }
+
+func GenerateStatusSlpSignature(txnId string, merchantCode string) (string, error) {
+	apiKey := config.Config.SeaLabsPayConfig.ApiKey
+	signature, err := HashSHA256(fmt.Sprintf("%s:%s", txnId, merchantCode), apiKey)
+	if err != nil {
+		return "", err
+	}
+
+	return signature, nil
+}